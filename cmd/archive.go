@@ -3,16 +3,21 @@ package cmd
 import (
 	"archive/tar"
 	"compress/gzip"
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/paulderscheid/git-context/internal/archivestore"
 	"github.com/paulderscheid/git-context/internal/git"
 	"github.com/spf13/cobra"
 )
 
+const archiveManifestName = "archive-manifest.json"
+
 var (
 	archiveOutput string
 	archiveTopic  string
@@ -23,12 +28,21 @@ var archiveCmd = &cobra.Command{
 	Short: "Bundle snapshots for external storage",
 	Long: `Create a tar.gz archive of snapshot branches for backup or transfer.
 
+--output accepts a local path (the default) or a remote URL: file://,
+s3://bucket/prefix, gcs://bucket/prefix, or ssh://host/path. Archives are
+streamed straight to the destination, never staged as a complete local
+copy first. Remote credentials are read from the environment, following
+srpmproc's blob package convention: AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY/
+AWS_SESSION_TOKEN/AWS_REGION for s3, GOOGLE_APPLICATION_CREDENTIALS for
+gcs, and CONTEXT_SSH_KEY/CONTEXT_SSH_USER/CONTEXT_SSH_KNOWN_HOSTS for ssh.
+
 Examples:
   context archive 2024          # Archive all snapshots from 2024
   context archive 2024-11       # Archive snapshots from November 2024
   context archive all           # Archive all snapshots
   context archive 2024 --topic security  # Archive only security snapshots from 2024
-  context archive all --output my-snapshots.tar.gz`,
+  context archive all --output my-snapshots.tar.gz
+  context archive all --output s3://my-bucket/backups/snapshots.tar.gz`,
 	Args: cobra.ExactArgs(1),
 	RunE: runArchive,
 }
@@ -36,19 +50,20 @@ Examples:
 func init() {
 	rootCmd.AddCommand(archiveCmd)
 
-	archiveCmd.Flags().StringVar(&archiveOutput, "output", "", "Output file path (default: context-snapshots-<period>.tar.gz)")
+	archiveCmd.Flags().StringVar(&archiveOutput, "output", "", "Output path or URL (default: context-snapshots-<period>.tar.gz)")
 	archiveCmd.Flags().StringVar(&archiveTopic, "topic", "", "Filter by topic")
 }
 
 func runArchive(cmd *cobra.Command, args []string) error {
-	if !git.IsGitRepo() {
+	ctx := commandContext(cmd)
+	if !git.IsGitRepo(ctx) {
 		return fmt.Errorf("not a git repository")
 	}
 
 	period := args[0]
 
 	// Get all snapshot branches
-	branches, err := git.ListBranches("snapshot/*")
+	branches, err := git.ListBranches(ctx, "snapshot/*")
 	if err != nil {
 		return fmt.Errorf("failed to list branches: %w", err)
 	}
@@ -92,7 +107,7 @@ func runArchive(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	// Determine output file
+	// Determine output destination
 	outputFile := archiveOutput
 	if outputFile == "" {
 		if period == "all" {
@@ -105,16 +120,23 @@ func runArchive(cmd *cobra.Command, args []string) error {
 	fmt.Printf("Archiving %d snapshot(s) to: %s\n", len(selectedBranches), outputFile)
 	fmt.Println()
 
-	// Create archive
-	if err := createArchive(outputFile, selectedBranches); err != nil {
+	backend, name, err := archivestore.Open(outputFile)
+	if err != nil {
+		return fmt.Errorf("failed to open archive destination: %w", err)
+	}
+	defer backend.Close()
+
+	if err := streamArchive(ctx, backend, name, selectedBranches); err != nil {
 		return fmt.Errorf("failed to create archive: %w", err)
 	}
 
-	// Get file size
-	fileInfo, err := os.Stat(outputFile)
-	if err == nil {
-		sizeKB := fileInfo.Size() / 1024
-		fmt.Printf("\n✓ Archive created: %s (%.2f KB)\n", outputFile, float64(sizeKB))
+	if !strings.Contains(outputFile, "://") {
+		if fileInfo, err := os.Stat(outputFile); err == nil {
+			sizeKB := fileInfo.Size() / 1024
+			fmt.Printf("\n✓ Archive created: %s (%.2f KB)\n", outputFile, float64(sizeKB))
+		} else {
+			fmt.Printf("\n✓ Archive created: %s\n", outputFile)
+		}
 	} else {
 		fmt.Printf("\n✓ Archive created: %s\n", outputFile)
 	}
@@ -127,22 +149,47 @@ func runArchive(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func createArchive(filename string, branches []string) error {
-	// Create output file
-	outFile, err := os.Create(filename)
-	if err != nil {
-		return err
+// streamArchive builds the tar.gz for branches and writes it straight to
+// backend under name, using a pipe so the archive never has to be staged
+// as a complete file before it reaches the destination.
+func streamArchive(ctx context.Context, backend archivestore.Backend, name string, branches []string) error {
+	pr, pw := io.Pipe()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- backend.Write(ctx, name, pr)
+	}()
+
+	buildErr := createArchive(ctx, pw, branches)
+	pw.CloseWithError(buildErr)
+
+	writeErr := <-done
+	if buildErr != nil {
+		return buildErr
 	}
-	defer outFile.Close()
+	return writeErr
+}
 
+func createArchive(ctx context.Context, w io.Writer, branches []string) error {
 	// Create gzip writer
-	gzWriter := gzip.NewWriter(outFile)
+	gzWriter := gzip.NewWriter(w)
 	defer gzWriter.Close()
 
 	// Create tar writer
 	tarWriter := tar.NewWriter(gzWriter)
 	defer tarWriter.Close()
 
+	// branchPrefixes maps each flattened tar-entry prefix back to its
+	// original branch name, since "/" -> "-" flattening is lossy once
+	// timestamps and topics contain hyphens of their own.
+	branchPrefixes := make(map[string]string, len(branches))
+	for _, branch := range branches {
+		branchPrefixes[strings.ReplaceAll(branch, "/", "-")] = branch
+	}
+	if err := writeArchiveManifest(tarWriter, branchPrefixes); err != nil {
+		return fmt.Errorf("failed to write archive manifest: %w", err)
+	}
+
 	// Create temporary directory for exporting branches
 	tmpDir, err := os.MkdirTemp("", "context-archive-*")
 	if err != nil {
@@ -156,7 +203,7 @@ func createArchive(filename string, branches []string) error {
 
 		// Create worktree for the branch
 		worktreePath := filepath.Join(tmpDir, strings.ReplaceAll(branch, "/", "-"))
-		if err := git.CreateWorktree(worktreePath, branch); err != nil {
+		if err := git.CreateWorktree(ctx, worktreePath, branch); err != nil {
 			return fmt.Errorf("failed to create worktree for %s: %w", branch, err)
 		}
 
@@ -218,10 +265,32 @@ func createArchive(filename string, branches []string) error {
 		}
 
 		// Remove worktree
-		if err := git.RemoveWorktree(worktreePath); err != nil {
+		if err := git.RemoveWorktree(ctx, worktreePath); err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: failed to remove worktree: %v\n", err)
 		}
 	}
 
 	return nil
 }
+
+// writeArchiveManifest writes archive-manifest.json as the first tar
+// entry, mapping each flattened branch prefix back to the original
+// branch name so restoreArchive can recreate branches exactly instead of
+// guessing at hyphen positions.
+func writeArchiveManifest(tarWriter *tar.Writer, branchPrefixes map[string]string) error {
+	data, err := json.MarshalIndent(branchPrefixes, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	header := &tar.Header{
+		Name: archiveManifestName,
+		Mode: 0644,
+		Size: int64(len(data)),
+	}
+	if err := tarWriter.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err = tarWriter.Write(data)
+	return err
+}
@@ -0,0 +1,479 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alpkeskin/gotoon"
+	"github.com/pders01/git-context/internal/config"
+	"github.com/pders01/git-context/internal/embeddings"
+	"github.com/pders01/git-context/internal/git"
+	"github.com/pders01/git-context/internal/models"
+	"github.com/pders01/git-context/internal/ollama"
+	"github.com/spf13/cobra"
+)
+
+var (
+	checkReadData bool
+	checkRepair   bool
+	checkJSON     bool
+	checkToon     bool
+)
+
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Verify the integrity of the snapshot store",
+	Long: `Verify that every snapshot branch and its metadata and embedding are
+intact, the way restic check verifies a backup repository.
+
+For every snapshot/* branch, check:
+  - the branch commit and its tree are reachable and parse cleanly
+  - metadata.json exists, parses, and has a recognized mode and required fields
+  - related_branch, if set, points to a branch that still exists
+  - the embedding file, if referenced, is present and its header is well-formed
+
+It also reports local metadata/embedding cache entries left behind by
+snapshot branches that have since been deleted.
+
+By default only headers are validated (metadata-only). Pass --read-data to
+additionally decode every embedding's payload, verify its CRC-32, and check
+that it's a unit vector (magnitude ~= 1.0), which is slower but catches
+silent bit-rot and corrupted vectors.
+
+It also checks that notes.md exists under each snapshot's research/
+directory, and that full/poc snapshots still have code files in their tree.
+
+Pass --repair to regenerate a missing or broken embedding via Ollama, or
+renormalize one whose magnitude has drifted from 1.0.
+
+Exits non-zero if any issue is found, so it can be used in CI.
+
+Examples:
+  context check
+  context check --read-data
+  context check --repair
+  context check --json`,
+	RunE: runCheck,
+}
+
+func init() {
+	rootCmd.AddCommand(checkCmd)
+
+	checkCmd.Flags().BoolVar(&checkReadData, "read-data", false, "Decode every embedding and verify its CRC-32 (slower, full scan)")
+	checkCmd.Flags().BoolVar(&checkRepair, "repair", false, "Regenerate missing/broken embeddings and renormalize drifted vectors")
+	checkCmd.Flags().BoolVar(&checkJSON, "json", false, "Output as JSON")
+	checkCmd.Flags().BoolVar(&checkToon, "toon", false, "Output in LLM-friendly toon format")
+}
+
+// checkIssue is a single integrity problem found while checking the
+// snapshot store.
+type checkIssue struct {
+	Branch   string `json:"branch,omitempty"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+type checkReport struct {
+	BranchesChecked int          `json:"branches_checked"`
+	Issues          []checkIssue `json:"issues"`
+}
+
+func runCheck(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if !git.IsGitRepo(ctx) {
+		return fmt.Errorf("not a git repository")
+	}
+
+	branches, err := git.ListBranches(ctx, "snapshot/*")
+	if err != nil {
+		return fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	existing := make(map[string]bool, len(branches))
+	for _, b := range branches {
+		existing[b] = true
+	}
+
+	report := checkReport{BranchesChecked: len(branches)}
+
+	for _, branch := range branches {
+		report.Issues = append(report.Issues, checkSnapshot(ctx, branch, existing)...)
+	}
+
+	report.Issues = append(report.Issues, checkOrphanedCacheEntries(ctx, existing)...)
+
+	if checkJSON {
+		output, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(output))
+	} else if checkToon {
+		output, err := gotoon.Encode(report)
+		if err != nil {
+			return fmt.Errorf("failed to encode Toon: %w", err)
+		}
+		fmt.Println(output)
+	} else {
+		printCheckReport(report)
+	}
+
+	if len(report.Issues) > 0 {
+		return fmt.Errorf("snapshot store check found %d issue(s)", len(report.Issues))
+	}
+
+	return nil
+}
+
+// checkSnapshot validates a single snapshot branch and returns every issue
+// found. existing is the full set of snapshot/* branches, used to resolve
+// related_branch references.
+func checkSnapshot(ctx context.Context, branch string, existing map[string]bool) []checkIssue {
+	var issues []checkIssue
+
+	fail := func(format string, args ...interface{}) {
+		issues = append(issues, checkIssue{Branch: branch, Severity: "error", Message: fmt.Sprintf(format, args...)})
+	}
+	warn := func(format string, args ...interface{}) {
+		issues = append(issues, checkIssue{Branch: branch, Severity: "warning", Message: fmt.Sprintf(format, args...)})
+	}
+
+	if !git.BranchExists(ctx, branch) {
+		fail("branch commit is not reachable")
+		return issues
+	}
+	if !git.TreeExists(ctx, branch) {
+		fail("branch tree does not parse")
+		return issues
+	}
+
+	info, err := parseSnapshotBranch(branch)
+	if err != nil {
+		fail("invalid snapshot branch name: %v", err)
+		return issues
+	}
+
+	metaPath := models.MetadataPath(info.Timestamp, info.Topic)
+	metaContent, err := gitShow(ctx, branch, metaPath)
+	if err != nil {
+		fail("missing %s", metaPath)
+		return issues
+	}
+
+	var metadata models.Metadata
+	if err := json.Unmarshal([]byte(metaContent), &metadata); err != nil {
+		fail("%s does not parse as JSON: %v", metaPath, err)
+		return issues
+	}
+
+	if metadata.Topic == "" {
+		fail("metadata.topic is empty")
+	}
+	if metadata.MainCommit == "" {
+		fail("metadata.main_commit is empty")
+	}
+	switch metadata.Mode {
+	case models.ModeFull, models.ModeResearchOnly, models.ModeDiff, models.ModePOC:
+	default:
+		fail("metadata.mode %q is not a recognized snapshot mode", metadata.Mode)
+	}
+	if metadata.CreatedAt.IsZero() {
+		warn("metadata.created_at is unset")
+	}
+
+	if metadata.RelatedBranch != "" && !existing[metadata.RelatedBranch] {
+		fail("related_branch %q does not exist", metadata.RelatedBranch)
+	}
+
+	notesPath := filepath.Join(models.ResearchPath(info.Timestamp, info.Topic), "notes.md")
+	if _, err := gitShow(ctx, branch, notesPath); err != nil {
+		fail("missing %s", notesPath)
+	}
+
+	if metadata.Mode == models.ModeFull || metadata.Mode == models.ModePOC {
+		names, err := git.ListTreeRecursive(ctx, branch)
+		if err != nil {
+			fail("failed to list tree: %v", err)
+		} else if !hasCodeFiles(names) {
+			fail("mode %q expects code files in the tree but none were found outside research/", metadata.Mode)
+		}
+	}
+
+	if metadata.Embedding != "" || metadata.EmbeddingDelta != "" {
+		embIssues := checkEmbedding(ctx, branch, info, metadata)
+		if len(embIssues) > 0 && checkRepair {
+			if err := repairEmbedding(ctx, branch, info, metadata); err != nil {
+				issues = append(issues, embIssues...)
+				fail("repair failed: %v", err)
+			} else {
+				issues = append(issues, checkIssue{Branch: branch, Severity: "info", Message: fmt.Sprintf("repaired embedding %s", embeddingRef(metadata))})
+			}
+		} else {
+			issues = append(issues, embIssues...)
+		}
+	}
+
+	return issues
+}
+
+// hasCodeFiles reports whether names contains any path outside research/.
+func hasCodeFiles(names []string) bool {
+	for _, n := range names {
+		if !strings.HasPrefix(n, "research/") {
+			return true
+		}
+	}
+	return false
+}
+
+// embeddingRef returns the path a snapshot's metadata references for its
+// embedding, whichever storage form it's currently in.
+func embeddingRef(metadata models.Metadata) string {
+	if metadata.EmbeddingDelta != "" {
+		return metadata.EmbeddingDelta
+	}
+	return metadata.Embedding
+}
+
+// checkEmbedding validates the embedding a snapshot's metadata references.
+// A plain embedding uses the header-only path by default and a full CRC-32
+// decode when --read-data is set, same as before. A delta-compressed
+// embedding is checked for presence in the tree by default, and with
+// --read-data is fully resolved (following EmbeddingBase) and checked for
+// unit magnitude, the same way a plain embedding is.
+func checkEmbedding(ctx context.Context, branch string, info snapshotInfo, metadata models.Metadata) []checkIssue {
+	var issues []checkIssue
+	fail := func(format string, args ...interface{}) {
+		issues = append(issues, checkIssue{Branch: branch, Severity: "error", Message: fmt.Sprintf(format, args...)})
+	}
+
+	embeddingPath := filepath.Join(models.ResearchPath(info.Timestamp, info.Topic), embeddingRef(metadata))
+	content, err := gitShow(ctx, branch, embeddingPath)
+	if err != nil {
+		fail("embedding %s referenced in metadata but missing from tree", embeddingPath)
+		return issues
+	}
+
+	if metadata.EmbeddingDelta != "" {
+		if !checkReadData {
+			return issues
+		}
+		vec, err := resolveSnapshotEmbedding(ctx, branch, info, &metadata, 0)
+		if err != nil {
+			fail("delta-compressed embedding %s failed to resolve: %v", embeddingPath, err)
+			return issues
+		}
+		if mag := embeddings.Magnitude(vec); math.Abs(mag-1.0) > 0.01 {
+			fail("embedding %s is not a unit vector (magnitude %.4f)", embeddingPath, mag)
+		}
+		return issues
+	}
+
+	tmpFile := filepath.Join(os.TempDir(), fmt.Sprintf("check-embedding-%s-%s.bin", info.Timestamp.Format("20060102T1504"), info.Topic))
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		fail("failed to stage embedding %s for validation: %v", embeddingPath, err)
+		return issues
+	}
+	defer os.Remove(tmpFile)
+
+	var dim int
+	if checkReadData {
+		vec, err := embeddings.Resolve(casObjectsDir(), []byte(content))
+		if err != nil {
+			fail("embedding %s failed validation: %v", embeddingPath, err)
+			return issues
+		}
+		dim = len(vec)
+
+		if mag := embeddings.Magnitude(vec); math.Abs(mag-1.0) > 0.01 {
+			fail("embedding %s is not a unit vector (magnitude %.4f)", embeddingPath, mag)
+		}
+	} else if embeddings.IsPointer([]byte(content)) {
+		ptr, err := embeddings.ParsePointer([]byte(content))
+		if err != nil {
+			fail("embedding %s failed header validation: %v", embeddingPath, err)
+			return issues
+		}
+		dim = ptr.Dim
+	} else {
+		dim, err = embeddings.ReadEmbeddingHeader(tmpFile)
+		if err != nil {
+			fail("embedding %s failed header validation: %v", embeddingPath, err)
+			return issues
+		}
+	}
+
+	if dim <= 0 {
+		fail("embedding %s has non-positive dimension %d", embeddingPath, dim)
+	}
+
+	return issues
+}
+
+// repairEmbedding regenerates a snapshot's embedding via Ollama if it's
+// missing or fails to parse, or renormalizes it in place if it parses but
+// has drifted from a unit vector. A broken delta-compressed embedding is
+// always repaired back to plain storage (EmbeddingDelta/EmbeddingBase/
+// EmbeddingScale cleared, meta.json rewritten) rather than re-deltified;
+// `context gc --repack-embeddings` is what re-selects a base afterward.
+// It commits the fix on the snapshot branch itself, the same way
+// retagSnapshot commits a tag mutation.
+func repairEmbedding(ctx context.Context, branch string, info snapshotInfo, metadata models.Metadata) error {
+	oldSHA, _ := git.RevParseBranch(ctx, branch)
+
+	worktreePath := filepath.Join(os.TempDir(), fmt.Sprintf("context-repair-%d", time.Now().UnixNano()))
+	if err := git.CreateWorktree(ctx, worktreePath, branch); err != nil {
+		return err
+	}
+	defer func() {
+		if err := git.RemoveWorktree(ctx, worktreePath); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to remove worktree: %v\n", err)
+		}
+	}()
+
+	researchPath := models.ResearchPath(info.Timestamp, info.Topic)
+	worktreeResearchPath := filepath.Join(worktreePath, researchPath)
+
+	wasDelta := metadata.EmbeddingDelta != ""
+
+	var vec []float64
+	var readErr error
+	if wasDelta {
+		vec, readErr = resolveSnapshotEmbedding(ctx, branch, info, &metadata, 0)
+	} else {
+		var raw []byte
+		raw, readErr = os.ReadFile(filepath.Join(worktreeResearchPath, metadata.Embedding))
+		if readErr == nil {
+			vec, readErr = embeddings.Resolve(casObjectsDir(), raw)
+		}
+	}
+
+	rewroteMeta := false
+
+	switch {
+	case readErr != nil:
+		if !config.GetEmbeddingsEnabled() || !ollama.IsAvailable(ctx, config.GetOllamaURL()) {
+			return fmt.Errorf("embedding is broken and Ollama is unavailable to regenerate it: %w", readErr)
+		}
+		if wasDelta {
+			if err := os.Remove(filepath.Join(worktreeResearchPath, metadata.EmbeddingDelta)); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove broken delta %s: %w", metadata.EmbeddingDelta, err)
+			}
+			metadata.EmbeddingDelta = ""
+			metadata.EmbeddingBase = ""
+			metadata.EmbeddingScale = 0
+		}
+		if err := generateEmbedding(ctx, &metadata, worktreeResearchPath); err != nil {
+			return fmt.Errorf("failed to regenerate embedding: %w", err)
+		}
+		rewroteMeta = wasDelta
+
+	case math.Abs(embeddings.Magnitude(vec)-1.0) > 0.01:
+		normalized, err := embeddings.Normalize(vec)
+		if err != nil {
+			return fmt.Errorf("failed to normalize embedding: %w", err)
+		}
+		if wasDelta {
+			if err := os.Remove(filepath.Join(worktreeResearchPath, metadata.EmbeddingDelta)); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove delta %s: %w", metadata.EmbeddingDelta, err)
+			}
+			metadata.EmbeddingDelta = ""
+			metadata.EmbeddingBase = ""
+			metadata.EmbeddingScale = 0
+			metadata.Embedding = "embedding.bin"
+			rewroteMeta = true
+		}
+		ptr, err := embeddings.Put(casObjectsDir(), buildEmbeddingText(&metadata), normalized)
+		if err != nil {
+			return fmt.Errorf("failed to store normalized embedding: %w", err)
+		}
+		ptrBytes, err := json.Marshal(ptr)
+		if err != nil {
+			return fmt.Errorf("failed to marshal embedding pointer: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(worktreeResearchPath, metadata.Embedding), ptrBytes, 0644); err != nil {
+			return fmt.Errorf("failed to write normalized embedding pointer: %w", err)
+		}
+
+	default:
+		return nil
+	}
+
+	if rewroteMeta {
+		metaBytes, err := json.MarshalIndent(metadata, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal repaired metadata: %w", err)
+		}
+		metaPath := models.MetadataPath(info.Timestamp, info.Topic)
+		if err := os.WriteFile(filepath.Join(worktreePath, metaPath), metaBytes, 0644); err != nil {
+			return fmt.Errorf("failed to write repaired metadata: %w", err)
+		}
+	}
+
+	if err := git.AddFilesInDir(ctx, worktreePath, researchPath); err != nil {
+		return err
+	}
+	if err := git.CommitInDir(ctx, worktreePath, fmt.Sprintf("check --repair: %s\n\nRegenerated or renormalized embedding", branch)); err != nil {
+		return fmt.Errorf("failed to commit repair: %w", err)
+	}
+
+	if oldSHA != "" {
+		getMetadataCache().Invalidate(oldSHA)
+		getMetadataCache().Save()
+		getEmbeddingCache().Invalidate(oldSHA)
+		getEmbeddingCache().Save()
+	}
+
+	return nil
+}
+
+// checkOrphanedCacheEntries reports locally cached metadata/embedding
+// entries whose snapshot branch no longer exists. These are harmless
+// (the cache self-heals on the next miss) but worth surfacing.
+func checkOrphanedCacheEntries(ctx context.Context, existing map[string]bool) []checkIssue {
+	liveSHAs := make(map[string]bool, len(existing))
+	for branch := range existing {
+		if sha, err := git.RevParseBranch(ctx, branch); err == nil {
+			liveSHAs[sha] = true
+		}
+	}
+
+	var issues []checkIssue
+	for _, sha := range getMetadataCache().Keys() {
+		if !liveSHAs[sha] {
+			issues = append(issues, checkIssue{Severity: "warning", Message: fmt.Sprintf("orphaned metadata cache entry for commit %s (no matching snapshot branch)", sha)})
+		}
+	}
+	for _, sha := range getEmbeddingCache().Keys() {
+		if !liveSHAs[sha] {
+			issues = append(issues, checkIssue{Severity: "warning", Message: fmt.Sprintf("orphaned embedding cache entry for commit %s (no matching snapshot branch)", sha)})
+		}
+	}
+
+	return issues
+}
+
+func printCheckReport(report checkReport) {
+	fmt.Printf("Checked %d snapshot(s)\n\n", report.BranchesChecked)
+
+	if len(report.Issues) == 0 {
+		fmt.Println("No issues found.")
+		return
+	}
+
+	for _, issue := range report.Issues {
+		if issue.Branch != "" {
+			fmt.Printf("[%s] %s: %s\n", issue.Severity, issue.Branch, issue.Message)
+		} else {
+			fmt.Printf("[%s] %s\n", issue.Severity, issue.Message)
+		}
+	}
+
+	fmt.Printf("\n%d issue(s) found\n", len(report.Issues))
+}
@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pders01/git-context/internal/git"
+	"github.com/spf13/cobra"
+)
+
+var (
+	copyTo     string
+	copyFrom   string
+	copyTopic  string
+	copySince  string
+	copyDryRun bool
+)
+
+var copyCmd = &cobra.Command{
+	Use:   "copy [snapshotRef...]",
+	Short: "Synchronize snapshots between repositories",
+	Long: `Push or pull snapshot branches (and the embeddings committed inside
+them) between this repository and another git remote or bare clone.
+
+Use --to to push snapshots out, or --from to pull them in. Snapshots are
+selected by --topic/--since, or by naming specific branches as arguments
+(either full "snapshot/<timestamp>/<topic>" refs or "<timestamp> <topic>"
+pairs). A snapshot is skipped as a no-op if the destination already has it
+at the same commit, so repeated copies are cheap.
+
+Examples:
+  context copy --to teammate-clone
+  context copy --to git@example.com:team/context.git --topic security
+  context copy --from origin --since 2025-11-01
+  context copy --to backup snapshot/2025-11-14T2252/security-audit`,
+	RunE: runCopy,
+}
+
+func init() {
+	rootCmd.AddCommand(copyCmd)
+
+	copyCmd.Flags().StringVar(&copyTo, "to", "", "Remote or path to push snapshots to")
+	copyCmd.Flags().StringVar(&copyFrom, "from", "", "Remote or path to pull snapshots from")
+	copyCmd.Flags().StringVar(&copyTopic, "topic", "", "Filter by topic")
+	copyCmd.Flags().StringVar(&copySince, "since", "", "Filter by snapshots since date (YYYY-MM-DD)")
+	copyCmd.Flags().BoolVar(&copyDryRun, "dry-run", false, "Show what would be copied without transferring")
+}
+
+func runCopy(cmd *cobra.Command, args []string) error {
+	ctx := commandContext(cmd)
+	if !git.IsGitRepo(ctx) {
+		return fmt.Errorf("not a git repository")
+	}
+
+	if copyTo == "" && copyFrom == "" {
+		return fmt.Errorf("one of --to or --from is required")
+	}
+	if copyTo != "" && copyFrom != "" {
+		return fmt.Errorf("--to and --from are mutually exclusive")
+	}
+
+	if copyTo != "" {
+		return runCopyTo(ctx, args)
+	}
+	return runCopyFrom(ctx, args)
+}
+
+func runCopyTo(ctx context.Context, args []string) error {
+	branches, err := selectCopyBranches(ctx, args)
+	if err != nil {
+		return err
+	}
+	if len(branches) == 0 {
+		fmt.Println("No snapshots match the selection criteria")
+		return nil
+	}
+
+	fmt.Printf("Copying %d snapshot(s) to %s\n\n", len(branches), copyTo)
+
+	for _, branch := range branches {
+		localCommit, err := git.RevParseBranch(ctx, branch)
+		if err != nil {
+			fmt.Printf("  ✗ %s (failed to resolve: %v)\n", branch, err)
+			continue
+		}
+
+		remoteCommit, err := git.RemoteBranchCommit(ctx, copyTo, branch)
+		if err == nil && remoteCommit == localCommit {
+			fmt.Printf("  = %s (up to date)\n", branch)
+			continue
+		}
+
+		if copyDryRun {
+			fmt.Printf("  + %s (would push)\n", branch)
+			continue
+		}
+
+		if err := git.PushBranch(ctx, copyTo, branch); err != nil {
+			fmt.Printf("  ✗ %s (push failed: %v)\n", branch, err)
+			continue
+		}
+		fmt.Printf("  ✓ %s\n", branch)
+	}
+
+	return nil
+}
+
+func runCopyFrom(ctx context.Context, args []string) error {
+	refs, err := git.ListRemoteBranches(ctx, copyFrom, "snapshot/*")
+	if err != nil {
+		return fmt.Errorf("failed to list remote snapshots: %w", err)
+	}
+
+	branches, err := filterCopyRefs(refs, args)
+	if err != nil {
+		return err
+	}
+	if len(branches) == 0 {
+		fmt.Println("No snapshots match the selection criteria")
+		return nil
+	}
+
+	fmt.Printf("Copying %d snapshot(s) from %s\n\n", len(branches), copyFrom)
+
+	for _, branch := range branches {
+		remoteCommit, err := git.RemoteBranchCommit(ctx, copyFrom, branch)
+		if err != nil {
+			fmt.Printf("  ✗ %s (failed to resolve: %v)\n", branch, err)
+			continue
+		}
+
+		if git.BranchExists(ctx, branch) {
+			localCommit, err := git.RevParseBranch(ctx, branch)
+			if err == nil && localCommit == remoteCommit {
+				fmt.Printf("  = %s (up to date)\n", branch)
+				continue
+			}
+		}
+
+		if copyDryRun {
+			fmt.Printf("  + %s (would fetch)\n", branch)
+			continue
+		}
+
+		if err := git.FetchBranch(ctx, copyFrom, branch); err != nil {
+			fmt.Printf("  ✗ %s (fetch failed: %v)\n", branch, err)
+			continue
+		}
+		fmt.Printf("  ✓ %s\n", branch)
+	}
+
+	return nil
+}
+
+// selectCopyBranches resolves the local snapshot branches to copy, either
+// from explicit refs or from the --topic/--since filters.
+func selectCopyBranches(ctx context.Context, args []string) ([]string, error) {
+	if len(args) > 0 {
+		return normalizeCopyRefs(args), nil
+	}
+
+	branches, err := git.ListBranches(ctx, "snapshot/*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	return filterCopyRefs(branches, nil)
+}
+
+// filterCopyRefs applies --topic/--since to a candidate branch list, or
+// narrows it to explicit refs when args are given.
+func filterCopyRefs(branches []string, args []string) ([]string, error) {
+	if len(args) > 0 {
+		wanted := make(map[string]bool)
+		for _, ref := range normalizeCopyRefs(args) {
+			wanted[ref] = true
+		}
+		var selected []string
+		for _, b := range branches {
+			if wanted[b] {
+				selected = append(selected, b)
+			}
+		}
+		return selected, nil
+	}
+
+	var since time.Time
+	var err error
+	if copySince != "" {
+		since, err = time.Parse("2006-01-02", copySince)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --since date format (use YYYY-MM-DD): %w", err)
+		}
+	}
+
+	var selected []string
+	for _, branch := range branches {
+		info, err := parseSnapshotBranch(branch)
+		if err != nil {
+			continue
+		}
+		if copyTopic != "" && info.Topic != copyTopic {
+			continue
+		}
+		if !since.IsZero() && info.Timestamp.Before(since) {
+			continue
+		}
+		selected = append(selected, branch)
+	}
+	return selected, nil
+}
+
+// normalizeCopyRefs accepts either full "snapshot/<timestamp>/<topic>" refs
+// or "<timestamp> <topic>" argument pairs and returns full branch names.
+func normalizeCopyRefs(args []string) []string {
+	var refs []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if strings.HasPrefix(arg, "snapshot/") {
+			refs = append(refs, arg)
+			continue
+		}
+		if i+1 < len(args) {
+			refs = append(refs, fmt.Sprintf("snapshot/%s/%s", arg, args[i+1]))
+			i++
+		}
+	}
+	return refs
+}
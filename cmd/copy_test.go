@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"github.com/pders01/git-context/internal/testutil"
+)
+
+func resetCopyFlags() {
+	copyTo = ""
+	copyFrom = ""
+	copyTopic = ""
+	copySince = ""
+	copyDryRun = false
+}
+
+func TestCopyNoSnapshots(t *testing.T) {
+	repo := testutil.NewTempGitRepo(t)
+	defer repo.Cleanup()
+	remote := testutil.NewTempGitRepo(t)
+	defer remote.Cleanup()
+
+	oldWd, _ := os.Getwd()
+	os.Chdir(repo.Path)
+	defer os.Chdir(oldWd)
+
+	resetCopyFlags()
+	copyTo = remote.Path
+
+	err := runCopy(nil, []string{})
+	if err != nil {
+		t.Fatalf("copy command failed: %v", err)
+	}
+}
+
+func TestCopyToRemote(t *testing.T) {
+	repo := testutil.NewTempGitRepo(t)
+	defer repo.Cleanup()
+	remote := testutil.NewTempGitRepo(t)
+	defer remote.Cleanup()
+
+	oldWd, _ := os.Getwd()
+	os.Chdir(repo.Path)
+	defer os.Chdir(oldWd)
+
+	createTestSnapshot(t, "shared-work", "full", []string{})
+
+	var branch string
+	for _, b := range repo.GetBranches() {
+		if b != "main" && b != "master" {
+			branch = b
+		}
+	}
+	if branch == "" {
+		t.Fatal("test snapshot branch not found")
+	}
+
+	resetCopyFlags()
+	copyTo = remote.Path
+
+	err := runCopy(nil, []string{})
+	if err != nil {
+		t.Fatalf("copy command failed: %v", err)
+	}
+
+	if !remote.BranchExists(branch) {
+		t.Errorf("expected %s to exist on remote after copy --to", branch)
+	}
+
+	// Re-running should be a no-op, not an error.
+	if err := runCopy(nil, []string{}); err != nil {
+		t.Fatalf("second copy command failed: %v", err)
+	}
+}
+
+func TestCopyRequiresToOrFrom(t *testing.T) {
+	repo := testutil.NewTempGitRepo(t)
+	defer repo.Cleanup()
+
+	oldWd, _ := os.Getwd()
+	os.Chdir(repo.Path)
+	defer os.Chdir(oldWd)
+
+	resetCopyFlags()
+
+	err := runCopy(nil, []string{})
+	if err == nil {
+		t.Error("expected error when neither --to nor --from is set")
+	}
+}
+
+func TestCopyRejectsBothToAndFrom(t *testing.T) {
+	repo := testutil.NewTempGitRepo(t)
+	defer repo.Cleanup()
+
+	oldWd, _ := os.Getwd()
+	os.Chdir(repo.Path)
+	defer os.Chdir(oldWd)
+
+	resetCopyFlags()
+	copyTo = "somewhere"
+	copyFrom = "elsewhere"
+
+	err := runCopy(nil, []string{})
+	if err == nil {
+		t.Error("expected error when both --to and --from are set")
+	}
+}
@@ -1,18 +1,32 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/alpkeskin/gotoon"
+	unifieddiff "github.com/pders01/git-context/internal/diff"
+	"github.com/pders01/git-context/internal/embeddings"
 	"github.com/pders01/git-context/internal/git"
 	"github.com/spf13/cobra"
 )
 
 var (
-	diffJSON bool
-	diffToon bool
+	diffJSON         bool
+	diffToon         bool
+	diffNameOnly     bool
+	diffStat         bool
+	diffFilesOnly    bool
+	diffMetaOnly     bool
+	diffSemanticOnly bool
+	diffSemantic     bool
+	diffContent      bool
+	diffContext      int
+	diffAt1          string
+	diffAt2          string
 )
 
 var diffCmd = &cobra.Command{
@@ -22,10 +36,29 @@ var diffCmd = &cobra.Command{
   - Metadata (tags, notes, mode)
   - Timestamps
   - Related branches
-  - Commits
+  - Commits, including how many commits main advanced between the two
+    snapshots when one's main_commit is an ancestor of the other's (see
+    context merge-base/ancestry)
+  - Tracked code files and research artifacts
+  - Semantic drift, via cosine similarity of stored embeddings
+
+--content additionally renders a unified diff of every changed file under
+research/ (notes.md, embedding.bin, attached artifacts), the way a normal
+git commit range diff would; binary blobs fall back to a one-line summary.
+--context controls how many unchanged lines surround each change.
+
+Metadata is the resolved view (base meta.json with its operation log from
+context log replayed over it, see context tag). --at1/--at2 pin one side
+to an earlier commit on its snapshot branch, replaying only the ops
+reachable from there.
 
 Example:
-  context diff 2025-11-14T2252 initial-reconnaissance 2025-11-14T2252 vulnerability-analysis`,
+  context diff 2025-11-14T2252 initial-reconnaissance 2025-11-14T2252 vulnerability-analysis
+  context diff 2025-11-14T2252 initial-reconnaissance 2025-11-14T2252 vulnerability-analysis --stat
+  context diff 2025-11-14T2252 initial-reconnaissance 2025-11-14T2252 vulnerability-analysis --name-only
+  context diff 2025-11-14T2252 initial-reconnaissance 2025-11-14T2252 vulnerability-analysis --semantic-only
+  context diff 2025-11-14T2252 initial-reconnaissance 2025-11-14T2252 vulnerability-analysis --name-only --semantic
+  context diff 2025-11-14T2252 initial-reconnaissance 2025-11-14T2252 vulnerability-analysis --content --context 5`,
 	Args: cobra.ExactArgs(4),
 	RunE: runDiff,
 }
@@ -35,21 +68,93 @@ func init() {
 
 	diffCmd.Flags().BoolVar(&diffJSON, "json", false, "Output as JSON")
 	diffCmd.Flags().BoolVar(&diffToon, "toon", false, "Output in LLM-friendly toon format")
+	diffCmd.Flags().BoolVar(&diffNameOnly, "name-only", false, "Only list changed file names, skip diff content")
+	diffCmd.Flags().BoolVar(&diffStat, "stat", false, "Show a diffstat summary of changed files instead of full content")
+	diffCmd.Flags().BoolVar(&diffFilesOnly, "files-only", false, "Only show the file-level diff")
+	diffCmd.Flags().BoolVar(&diffMetaOnly, "meta-only", false, "Only show the metadata delta")
+	diffCmd.Flags().BoolVar(&diffSemanticOnly, "semantic-only", false, "Only show the embedding similarity score")
+	diffCmd.Flags().BoolVar(&diffSemantic, "semantic", false, "Also show the embedding similarity score alongside whatever else is selected")
+	diffCmd.Flags().BoolVar(&diffContent, "content", false, "Show a unified diff of each changed file under research/")
+	diffCmd.Flags().IntVar(&diffContext, "context", unifieddiff.DefaultContext, "Lines of context around each change in --content mode")
+	diffCmd.Flags().StringVar(&diffAt1, "at1", "", "Resolve snapshot 1's metadata as of this commit instead of its branch tip")
+	diffCmd.Flags().StringVar(&diffAt2, "at2", "", "Resolve snapshot 2's metadata as of this commit instead of its branch tip")
+}
+
+// diffSections reports which of the three comparison categories (files,
+// metadata, semantic) should be computed and displayed. With none of
+// --files-only/--meta-only/--semantic-only set, all three run; each *-only
+// flag narrows the output to the categories it names. --semantic is
+// additive rather than narrowing: it forces the semantic section on
+// alongside whatever --files-only/--meta-only already selected, for a
+// quick conceptual-similarity check without giving up the other output.
+func diffSections() (files, meta, semantic bool) {
+	none := !diffFilesOnly && !diffMetaOnly && !diffSemanticOnly
+	files = diffFilesOnly || none
+	meta = diffMetaOnly || none
+	semantic = diffSemanticOnly || none || diffSemantic
+	return
 }
 
 type snapshotDiff struct {
-	Snapshot1       snapshotSummary `json:"snapshot1"`
-	Snapshot2       snapshotSummary `json:"snapshot2"`
-	TimeDifference  string          `json:"time_difference"`
-	TagsAdded       []string        `json:"tags_added"`
-	TagsRemoved     []string        `json:"tags_removed"`
-	TagsShared      []string        `json:"tags_shared"`
-	ModeChanged     bool            `json:"mode_changed"`
-	ModeFrom        string          `json:"mode_from,omitempty"`
-	ModeTo          string          `json:"mode_to,omitempty"`
-	NotesChanged    bool            `json:"notes_changed"`
-	BranchChanged   bool            `json:"branch_changed"`
-	CommitChanged   bool            `json:"commit_changed"`
+	Snapshot1      snapshotSummary `json:"snapshot1"`
+	Snapshot2      snapshotSummary `json:"snapshot2"`
+	TimeDifference string          `json:"time_difference"`
+	TagsAdded      []string        `json:"tags_added"`
+	TagsRemoved    []string        `json:"tags_removed"`
+	TagsShared     []string        `json:"tags_shared"`
+	ModeChanged    bool            `json:"mode_changed"`
+	ModeFrom       string          `json:"mode_from,omitempty"`
+	ModeTo         string          `json:"mode_to,omitempty"`
+	NotesChanged   bool            `json:"notes_changed"`
+	BranchChanged  bool            `json:"branch_changed"`
+	CommitChanged  bool            `json:"commit_changed"`
+	Lineage        *mainLineage    `json:"lineage,omitempty"`
+	CodeFiles      []fileChange    `json:"code_files,omitempty"`
+	ResearchFiles  []fileChange    `json:"research_files,omitempty"`
+	Added          []string        `json:"added,omitempty"`
+	Removed        []string        `json:"removed,omitempty"`
+	Modified       []string        `json:"modified,omitempty"`
+	Stat           string          `json:"stat,omitempty"`
+	Semantic       *semanticDelta  `json:"semantic,omitempty"`
+	ContentDiffs   []contentChange `json:"content_diffs,omitempty"`
+}
+
+// mainLineage reports how main advanced between the commits pinned by two
+// snapshots, when one commit is an ancestor of the other.
+type mainLineage struct {
+	Advanced     bool            `json:"advanced"`
+	CommitsAhead int             `json:"commits_ahead"`
+	Commits      []lineageCommit `json:"commits,omitempty"`
+}
+
+// lineageCommit is a single commit in a mainLineage.
+type lineageCommit struct {
+	SHA     string    `json:"sha"`
+	Subject string    `json:"subject"`
+	Date    time.Time `json:"date"`
+}
+
+// contentChange is a single changed file under research/, rendered as a
+// unified diff (or a binary summary line) by --content.
+type contentChange struct {
+	Status  string `json:"status"`
+	Path    string `json:"path"`
+	OldPath string `json:"old_path,omitempty"`
+	Diff    string `json:"diff"`
+}
+
+// semanticDelta is the embedding-based similarity between two snapshots.
+// HasBoth is false (and Score meaningless) when either snapshot lacks an
+// embedding or it could not be loaded.
+type semanticDelta struct {
+	Score   float64 `json:"score"`
+	HasBoth bool    `json:"has_both_embeddings"`
+}
+
+// fileChange describes a single file's status in a name-status diff
+type fileChange struct {
+	Status string `json:"status"`
+	Path   string `json:"path"`
 }
 
 type snapshotSummary struct {
@@ -63,7 +168,8 @@ type snapshotSummary struct {
 }
 
 func runDiff(cmd *cobra.Command, args []string) error {
-	if !git.IsGitRepo() {
+	ctx := cmd.Context()
+	if !git.IsGitRepo(ctx) {
 		return fmt.Errorf("not a git repository")
 	}
 
@@ -76,10 +182,10 @@ func runDiff(cmd *cobra.Command, args []string) error {
 	branch1 := fmt.Sprintf("snapshot/%s/%s", timestamp1, topic1)
 	branch2 := fmt.Sprintf("snapshot/%s/%s", timestamp2, topic2)
 
-	if !git.BranchExists(branch1) {
+	if !git.BranchExists(ctx, branch1) {
 		return fmt.Errorf("snapshot branch does not exist: %s", branch1)
 	}
-	if !git.BranchExists(branch2) {
+	if !git.BranchExists(ctx, branch2) {
 		return fmt.Errorf("snapshot branch does not exist: %s", branch2)
 	}
 
@@ -88,13 +194,17 @@ func runDiff(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to parse snapshot 1: %w", err)
 	}
-	info1.LoadMetadata()
+	if resolved, err := resolvedMetadata(ctx, info1, diffAt1); err == nil {
+		info1.Metadata = resolved
+	}
 
 	info2, err := parseSnapshotBranch(branch2)
 	if err != nil {
 		return fmt.Errorf("failed to parse snapshot 2: %w", err)
 	}
-	info2.LoadMetadata()
+	if resolved, err := resolvedMetadata(ctx, info2, diffAt2); err == nil {
+		info2.Metadata = resolved
+	}
 
 	// Build diff
 	diff := &snapshotDiff{
@@ -146,6 +256,9 @@ func runDiff(cmd *cobra.Command, args []string) error {
 
 	// Compare commits
 	diff.CommitChanged = diff.Snapshot1.Commit != diff.Snapshot2.Commit
+	if diff.CommitChanged && diff.Snapshot1.Commit != "" && diff.Snapshot2.Commit != "" {
+		diff.Lineage = computeMainLineage(ctx, diff.Snapshot1.Commit, diff.Snapshot2.Commit)
+	}
 
 	// Compare tags
 	tagMap1 := make(map[string]bool)
@@ -172,6 +285,39 @@ func runDiff(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	wantFiles, wantMeta, wantSemantic := diffSections()
+
+	// Compare tracked files between the two snapshot commits, split into
+	// code files (everything outside research/) and research artifacts.
+	if wantFiles {
+		nameStatus, err := git.DiffNameStatusBranches(ctx, branch1, branch2)
+		if err != nil {
+			return fmt.Errorf("failed to diff files: %w", err)
+		}
+		diff.CodeFiles, diff.ResearchFiles = splitFileChanges(nameStatus)
+		diff.Added, diff.Removed, diff.Modified = classifyFileChanges(diff.CodeFiles, diff.ResearchFiles)
+
+		if diffStat {
+			stat, err := git.DiffStatBranches(ctx, branch1, branch2)
+			if err != nil {
+				return fmt.Errorf("failed to diff stat: %w", err)
+			}
+			diff.Stat = strings.TrimRight(stat, "\n")
+		}
+	}
+
+	if wantSemantic {
+		diff.Semantic = compareEmbeddings(ctx, branch1, info1, branch2, info2)
+	}
+
+	if diffContent {
+		contentDiffs, err := computeContentDiffs(ctx, branch1, branch2, diffContext)
+		if err != nil {
+			return fmt.Errorf("failed to diff research content: %w", err)
+		}
+		diff.ContentDiffs = contentDiffs
+	}
+
 	// Output JSON if requested
 	if diffJSON {
 		output, err := json.MarshalIndent(diff, "", "  ")
@@ -204,47 +350,284 @@ func runDiff(cmd *cobra.Command, args []string) error {
 	fmt.Printf("Time Difference: %s\n", diff.TimeDifference)
 	fmt.Println()
 
-	if diff.ModeChanged {
-		fmt.Printf("Mode: %s → %s\n", diff.ModeFrom, diff.ModeTo)
-	} else {
-		fmt.Printf("Mode: %s (unchanged)\n", diff.Snapshot1.Mode)
-	}
-	fmt.Println()
+	if wantMeta {
+		if diff.ModeChanged {
+			fmt.Printf("Mode: %s → %s\n", diff.ModeFrom, diff.ModeTo)
+		} else {
+			fmt.Printf("Mode: %s (unchanged)\n", diff.Snapshot1.Mode)
+		}
+		fmt.Println()
+
+		if len(diff.TagsAdded) > 0 || len(diff.TagsRemoved) > 0 {
+			fmt.Println("Tags:")
+			if len(diff.TagsShared) > 0 {
+				fmt.Printf("  Shared:  %v\n", diff.TagsShared)
+			}
+			if len(diff.TagsAdded) > 0 {
+				fmt.Printf("  Added:   %v\n", diff.TagsAdded)
+			}
+			if len(diff.TagsRemoved) > 0 {
+				fmt.Printf("  Removed: %v\n", diff.TagsRemoved)
+			}
+			fmt.Println()
+		} else if len(diff.TagsShared) > 0 {
+			fmt.Printf("Tags: %v (unchanged)\n\n", diff.TagsShared)
+		}
 
-	if len(diff.TagsAdded) > 0 || len(diff.TagsRemoved) > 0 {
-		fmt.Println("Tags:")
-		if len(diff.TagsShared) > 0 {
-			fmt.Printf("  Shared:  %v\n", diff.TagsShared)
+		if diff.CommitChanged {
+			fmt.Printf("Commit: %s → %s\n", diff.Snapshot1.Commit[:8], diff.Snapshot2.Commit[:8])
+		} else {
+			fmt.Printf("Commit: %s (unchanged)\n", diff.Snapshot1.Commit[:8])
 		}
-		if len(diff.TagsAdded) > 0 {
-			fmt.Printf("  Added:   %v\n", diff.TagsAdded)
+		if diff.Lineage != nil && diff.Lineage.Advanced {
+			fmt.Printf("Main advanced by %d commit(s) between snapshot1 and snapshot2:\n", diff.Lineage.CommitsAhead)
+			for _, c := range diff.Lineage.Commits {
+				fmt.Printf("  %s  %s\n", c.SHA[:8], c.Subject)
+			}
 		}
-		if len(diff.TagsRemoved) > 0 {
-			fmt.Printf("  Removed: %v\n", diff.TagsRemoved)
+		fmt.Println()
+
+		if diff.NotesChanged {
+			fmt.Println("Notes Changed:")
+			fmt.Printf("  Snapshot 1: %s\n", truncate(diff.Snapshot1.Notes, 100))
+			fmt.Printf("  Snapshot 2: %s\n", truncate(diff.Snapshot2.Notes, 100))
+		} else {
+			fmt.Println("Notes: (unchanged)")
 		}
 		fmt.Println()
-	} else if len(diff.TagsShared) > 0 {
-		fmt.Printf("Tags: %v (unchanged)\n\n", diff.TagsShared)
 	}
 
-	if diff.CommitChanged {
-		fmt.Printf("Commit: %s → %s\n", diff.Snapshot1.Commit[:8], diff.Snapshot2.Commit[:8])
-	} else {
-		fmt.Printf("Commit: %s (unchanged)\n", diff.Snapshot1.Commit[:8])
+	if wantSemantic {
+		fmt.Println("Semantic Similarity:")
+		if diff.Semantic != nil && diff.Semantic.HasBoth {
+			fmt.Printf("  Cosine similarity: %.4f\n", diff.Semantic.Score)
+		} else {
+			fmt.Println("  (unavailable: one or both snapshots lack an embedding)")
+		}
+		fmt.Println()
 	}
-	fmt.Println()
 
-	if diff.NotesChanged {
-		fmt.Println("Notes Changed:")
-		fmt.Printf("  Snapshot 1: %s\n", truncate(diff.Snapshot1.Notes, 100))
-		fmt.Printf("  Snapshot 2: %s\n", truncate(diff.Snapshot2.Notes, 100))
-	} else {
-		fmt.Println("Notes: (unchanged)")
+	if diffContent {
+		printContentDiffs(diff.ContentDiffs)
+	}
+
+	if !wantFiles {
+		return nil
+	}
+
+	if diffStat {
+		fmt.Println("Diffstat:")
+		if diff.Stat != "" {
+			fmt.Println(diff.Stat)
+		} else {
+			fmt.Println("  (no changes)")
+		}
+		return nil
+	}
+
+	printFileChanges("Code Files", diff.CodeFiles)
+	printFileChanges("Research Files", diff.ResearchFiles)
+
+	if !diffNameOnly && (len(diff.CodeFiles) > 0 || len(diff.ResearchFiles) > 0) {
+		fullDiff, err := git.DiffBranches(ctx, branch1, branch2)
+		if err != nil {
+			return fmt.Errorf("failed to diff branches: %w", err)
+		}
+		fmt.Println("Full Diff:")
+		fmt.Println(fullDiff)
 	}
 
 	return nil
 }
 
+// computeMainLineage reports how main advanced between two snapshots'
+// pinned commits, when one commit is an ancestor of the other. Returns nil
+// if neither is an ancestor of the other (they diverged past a shared
+// point, or either commit is missing).
+func computeMainLineage(ctx context.Context, commit1, commit2 string) *mainLineage {
+	var older, newer string
+	switch {
+	case git.IsAncestor(ctx, commit1, commit2):
+		older, newer = commit1, commit2
+	case git.IsAncestor(ctx, commit2, commit1):
+		older, newer = commit2, commit1
+	default:
+		return nil
+	}
+
+	entries, err := git.CommitsBetween(ctx, older, newer)
+	if err != nil {
+		return nil
+	}
+
+	commits := make([]lineageCommit, len(entries))
+	for i, e := range entries {
+		commits[i] = lineageCommit{SHA: e.SHA, Subject: e.Subject, Date: e.Date}
+	}
+
+	return &mainLineage{Advanced: true, CommitsAhead: len(commits), Commits: commits}
+}
+
+// compareEmbeddings loads both snapshots' embeddings (if present) and
+// returns their cosine similarity. HasBoth is false if either snapshot
+// has no embedding or it fails to load.
+func compareEmbeddings(ctx context.Context, branch1 string, info1 snapshotInfo, branch2 string, info2 snapshotInfo) *semanticDelta {
+	if info1.Metadata == nil || info2.Metadata == nil {
+		return &semanticDelta{}
+	}
+	hasEmbedding1 := info1.Metadata.Embedding != "" || info1.Metadata.EmbeddingDelta != ""
+	hasEmbedding2 := info2.Metadata.Embedding != "" || info2.Metadata.EmbeddingDelta != ""
+	if !hasEmbedding1 || !hasEmbedding2 {
+		return &semanticDelta{}
+	}
+
+	vec1, err := loadSnapshotEmbedding(ctx, branch1, info1, info1.Metadata)
+	if err != nil {
+		return &semanticDelta{}
+	}
+	vec2, err := loadSnapshotEmbedding(ctx, branch2, info2, info2.Metadata)
+	if err != nil {
+		return &semanticDelta{}
+	}
+
+	similarity, err := embeddings.CosineSimilarity(vec1, vec2)
+	if err != nil {
+		return &semanticDelta{}
+	}
+
+	return &semanticDelta{Score: similarity, HasBoth: true}
+}
+
+// computeContentDiffs renders a unified diff for every changed file under
+// research/ between branch1 and branch2, via internal/diff. Renamed files
+// are diffed against their old content; binary blobs (like embedding.bin)
+// fall back to a summary line.
+func computeContentDiffs(ctx context.Context, branch1, branch2 string, context int) ([]contentChange, error) {
+	nameStatus, err := git.DiffNameStatusBranchesPath(ctx, branch1, branch2, "research/")
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []contentChange
+	for _, line := range strings.Split(nameStatus, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		status := fields[0]
+		oldPath, newPath := fields[1], fields[1]
+		if strings.HasPrefix(status, "R") && len(fields) >= 3 {
+			oldPath, newPath = fields[1], fields[2]
+		}
+
+		var oldContent, newContent []byte
+		if status[0] != 'A' {
+			if content, err := gitShow(ctx, branch1, oldPath); err == nil {
+				oldContent = []byte(content)
+			}
+		}
+		if status[0] != 'D' {
+			if content, err := gitShow(ctx, branch2, newPath); err == nil {
+				newContent = []byte(content)
+			}
+		}
+
+		cc := contentChange{
+			Status: status,
+			Path:   newPath,
+			Diff:   unifieddiff.FormatBlobs(oldPath, newPath, oldContent, newContent, context),
+		}
+		if oldPath != newPath {
+			cc.OldPath = oldPath
+		}
+		changes = append(changes, cc)
+	}
+
+	return changes, nil
+}
+
+// printContentDiffs renders the --content section; no-op when empty.
+func printContentDiffs(changes []contentChange) {
+	if len(changes) == 0 {
+		return
+	}
+
+	fmt.Println("Content Diff (research/):")
+	for _, c := range changes {
+		if c.OldPath != "" {
+			fmt.Printf("%s %s -> %s\n", c.Status, c.OldPath, c.Path)
+		} else {
+			fmt.Printf("%s %s\n", c.Status, c.Path)
+		}
+		if c.Diff != "" {
+			fmt.Println(c.Diff)
+		}
+	}
+}
+
+// splitFileChanges parses `git diff --name-status` output into code and
+// research-directory file changes.
+func splitFileChanges(nameStatus string) (code, research []fileChange) {
+	for _, line := range strings.Split(nameStatus, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		// Renames report as "R100 old new"; use the new path.
+		fc := fileChange{Status: fields[0], Path: fields[len(fields)-1]}
+
+		if strings.HasPrefix(fc.Path, "research/") {
+			research = append(research, fc)
+		} else {
+			code = append(code, fc)
+		}
+	}
+	return code, research
+}
+
+// classifyFileChanges flattens code and research file changes into the
+// added/removed/modified path lists used by the --json restic-style
+// summary, folding renames and copies into "modified" since their content
+// carries forward rather than appearing or disappearing outright.
+func classifyFileChanges(code, research []fileChange) (added, removed, modified []string) {
+	for _, fc := range append(append([]fileChange{}, code...), research...) {
+		switch fc.Status[0] {
+		case 'A':
+			added = append(added, fc.Path)
+		case 'D':
+			removed = append(removed, fc.Path)
+		default:
+			modified = append(modified, fc.Path)
+		}
+	}
+	return
+}
+
+// printFileChanges renders a labeled list of file changes; no-op when empty.
+func printFileChanges(label string, changes []fileChange) {
+	if len(changes) == 0 {
+		return
+	}
+
+	fmt.Printf("%s (%d):\n", label, len(changes))
+	for _, fc := range changes {
+		fmt.Printf("  %s  %s\n", fc.Status, fc.Path)
+	}
+	fmt.Println()
+}
+
 func truncate(s string, maxLen int) string {
 	if len(s) <= maxLen {
 		return s
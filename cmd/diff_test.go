@@ -0,0 +1,47 @@
+package cmd
+
+import "testing"
+
+func TestSplitFileChanges(t *testing.T) {
+	nameStatus := "M\tcmd/diff.go\nA\tresearch/2025-11-14T2252-audit/notes.md\nD\tresearch/2025-11-14T2252-audit/old.md"
+
+	code, research := splitFileChanges(nameStatus)
+
+	if len(code) != 1 || code[0].Path != "cmd/diff.go" {
+		t.Errorf("expected 1 code file, got %v", code)
+	}
+	if len(research) != 2 {
+		t.Errorf("expected 2 research files, got %v", research)
+	}
+}
+
+func TestClassifyFileChanges(t *testing.T) {
+	code := []fileChange{{Status: "A", Path: "new.go"}, {Status: "M", Path: "changed.go"}}
+	research := []fileChange{{Status: "D", Path: "research/2025-11-14T2252-audit/old.md"}, {Status: "R100", Path: "research/2025-11-14T2252-audit/renamed.md"}}
+
+	added, removed, modified := classifyFileChanges(code, research)
+
+	if len(added) != 1 || added[0] != "new.go" {
+		t.Errorf("expected added=[new.go], got %v", added)
+	}
+	if len(removed) != 1 || removed[0] != "research/2025-11-14T2252-audit/old.md" {
+		t.Errorf("expected removed=[old.md], got %v", removed)
+	}
+	if len(modified) != 2 {
+		t.Errorf("expected 2 modified (changed + renamed), got %v", modified)
+	}
+}
+
+func TestDiffSectionsSemanticIsAdditive(t *testing.T) {
+	defer func() {
+		diffFilesOnly, diffMetaOnly, diffSemanticOnly, diffSemantic = false, false, false, false
+	}()
+
+	diffFilesOnly = true
+	diffSemantic = true
+
+	files, meta, semantic := diffSections()
+	if !files || meta || !semantic {
+		t.Errorf("expected files-only + --semantic to keep files on, meta off, semantic forced on; got files=%v meta=%v semantic=%v", files, meta, semantic)
+	}
+}
@@ -0,0 +1,559 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alpkeskin/gotoon"
+	"github.com/pders01/git-context/internal/config"
+	"github.com/pders01/git-context/internal/embeddings"
+	"github.com/pders01/git-context/internal/git"
+	"github.com/pders01/git-context/internal/models"
+	"github.com/pders01/git-context/internal/ollama"
+	"github.com/spf13/cobra"
+)
+
+var (
+	findPath       string
+	findTags       []string
+	findTopic      string
+	findContent    string
+	findMode       string
+	findSince      string
+	findUntil      string
+	findSemantic   string
+	findLimit      int
+	findJSON       bool
+	findToon       bool
+	findInTree     bool
+	findName       bool
+	findRegex      bool
+	findIgnoreCase bool
+	findSnapshot   []string
+)
+
+var findCmd = &cobra.Command{
+	Use:   "find [pattern]",
+	Short: "Search across every snapshot for files, tags, or note content",
+	Long: `Search across all snapshot branches without having to enumerate them
+by hand. [pattern], if given, is matched case-insensitively against each
+snapshot's topic, tags, and notes.
+
+Flags narrow or change what's searched:
+  --path GLOB       Only snapshots containing a tracked file matching GLOB
+  --tag TAG         Only snapshots carrying this tag (repeatable, AND logic)
+  --topic TOPIC     Only snapshots with this exact topic
+  --content REGEX   Only snapshots whose notes.md matches REGEX
+  --mode MODE       Only snapshots in this mode
+  --since DATE      Only snapshots on or after DATE (YYYY-MM-DD)
+  --until DATE      Only snapshots on or before DATE (YYYY-MM-DD)
+  --semantic QUERY  Rank matches by cosine similarity to QUERY's embedding
+  --limit N         With --semantic, keep only the top N matches (default 10)
+
+--in-tree switches find from matching snapshot metadata to a restic-find-
+style search of every file in each matching snapshot's tree, modeled on
+"restic find": [pattern] is matched against file content line by line
+(or file paths with --name), with --regex and --ignore-case controlling
+how. Matches are streamed via "git cat-file --batch" and grouped per
+snapshot with path, line number, and a trimmed snippet. --snapshot
+restricts the search to specific branches (repeatable) instead of every
+snapshot/* branch.
+
+Examples:
+  context find "rate limiting"
+  context find --path "*.patch"
+  context find --tag security --since 2025-10-01
+  context find --content "TODO|FIXME"
+  context find --semantic "how did auth evolve" --limit 5
+  context find "rate limiter" --in-tree
+  context find "TODO|FIXME" --in-tree --regex --ignore-case
+  context find "config.go" --in-tree --name
+  context find "panic(" --in-tree --snapshot snapshot/2025-11-14T0930/security-audit`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runFind,
+}
+
+func init() {
+	rootCmd.AddCommand(findCmd)
+
+	findCmd.Flags().StringVar(&findPath, "path", "", "Only snapshots containing a tracked file matching this glob")
+	findCmd.Flags().StringSliceVar(&findTags, "tag", []string{}, "Only snapshots carrying this tag (repeatable, AND logic)")
+	findCmd.Flags().StringVar(&findTopic, "topic", "", "Only snapshots with this exact topic")
+	findCmd.Flags().StringVar(&findContent, "content", "", "Only snapshots whose notes.md matches this regular expression")
+	findCmd.Flags().StringVar(&findMode, "mode", "", "Only snapshots in this mode")
+	findCmd.Flags().StringVar(&findSince, "since", "", "Only snapshots on or after this date (YYYY-MM-DD)")
+	findCmd.Flags().StringVar(&findUntil, "until", "", "Only snapshots on or before this date (YYYY-MM-DD)")
+	findCmd.Flags().StringVar(&findSemantic, "semantic", "", "Rank matches by cosine similarity to this query")
+	findCmd.Flags().IntVar(&findLimit, "limit", 10, "With --semantic, keep only the top N matches")
+	findCmd.Flags().BoolVar(&findInTree, "in-tree", false, "Search file content (or names, with --name) across every matching snapshot's tree")
+	findCmd.Flags().BoolVar(&findName, "name", false, "With --in-tree, match file paths instead of file content")
+	findCmd.Flags().BoolVar(&findRegex, "regex", false, "With --in-tree, treat [pattern] as a regular expression")
+	findCmd.Flags().BoolVar(&findIgnoreCase, "ignore-case", false, "With --in-tree, match case-insensitively")
+	findCmd.Flags().StringSliceVar(&findSnapshot, "snapshot", []string{}, "With --in-tree, restrict the search to this snapshot branch (repeatable)")
+	findCmd.Flags().BoolVar(&findJSON, "json", false, "Output as JSON")
+	findCmd.Flags().BoolVar(&findToon, "toon", false, "Output in LLM-friendly toon format")
+}
+
+type findResult struct {
+	Info          snapshotInfo    `json:"info"`
+	Metadata      models.Metadata `json:"metadata"`
+	MatchedPath   string          `json:"matched_path,omitempty"`
+	Excerpt       string          `json:"excerpt,omitempty"`
+	SemanticScore float64         `json:"semantic_score,omitempty"`
+}
+
+func runFind(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if !git.IsGitRepo(ctx) {
+		return fmt.Errorf("not a git repository")
+	}
+
+	if findInTree {
+		return runFindInTree(ctx, args)
+	}
+
+	var pattern string
+	if len(args) > 0 {
+		pattern = strings.ToLower(args[0])
+	}
+
+	var since, until time.Time
+	if findSince != "" {
+		t, err := time.Parse("2006-01-02", findSince)
+		if err != nil {
+			return fmt.Errorf("invalid --since date format (use YYYY-MM-DD): %w", err)
+		}
+		since = t
+	}
+	if findUntil != "" {
+		t, err := time.Parse("2006-01-02", findUntil)
+		if err != nil {
+			return fmt.Errorf("invalid --until date format (use YYYY-MM-DD): %w", err)
+		}
+		until = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	filter := SnapshotFilter{Topic: findTopic, Tags: findTags, Since: since, Until: until}
+
+	var contentRe *regexp.Regexp
+	if findContent != "" {
+		re, err := regexp.Compile(findContent)
+		if err != nil {
+			return fmt.Errorf("invalid --content regular expression: %w", err)
+		}
+		contentRe = re
+	}
+
+	var queryEmbedding []float64
+	if findSemantic != "" {
+		if !config.GetEmbeddingsEnabled() || !ollama.IsAvailable(ctx, config.GetOllamaURL()) {
+			return fmt.Errorf("--semantic requires Ollama to be running and reachable at %s", config.GetOllamaURL())
+		}
+		client, err := ollama.NewClient(config.GetOllamaURL(), config.GetEmbeddingModel())
+		if err != nil {
+			return fmt.Errorf("failed to create Ollama client: %w", err)
+		}
+		queryEmbedding, err = client.GenerateEmbedding(ctx, findSemantic)
+		if err != nil {
+			return fmt.Errorf("failed to embed --semantic query: %w", err)
+		}
+	}
+
+	branches, err := git.ListBranches(ctx, "snapshot/*")
+	if err != nil {
+		return fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	var results []findResult
+
+	for _, branch := range branches {
+		info, err := parseSnapshotBranch(branch)
+		if err != nil {
+			continue
+		}
+		info.LoadMetadata(ctx)
+		if info.Metadata == nil {
+			continue
+		}
+		metadata := *info.Metadata
+
+		if findMode != "" && string(metadata.Mode) != findMode {
+			continue
+		}
+		if !filter.MatchesTopicAndDate(info) {
+			continue
+		}
+		if !filter.MatchesTags(info.Metadata) {
+			continue
+		}
+
+		if pattern != "" && !matchesGenericPattern(pattern, &metadata) {
+			continue
+		}
+
+		result := findResult{Info: info, Metadata: metadata}
+
+		if findPath != "" {
+			matched, err := findMatchingPath(ctx, branch, findPath)
+			if err != nil || matched == "" {
+				continue
+			}
+			result.MatchedPath = matched
+		}
+
+		if contentRe != nil {
+			excerpt, ok := findMatchingExcerpt(ctx, branch, info, contentRe)
+			if !ok {
+				continue
+			}
+			result.Excerpt = excerpt
+		}
+
+		if queryEmbedding != nil {
+			if metadata.Embedding == "" && metadata.EmbeddingDelta == "" {
+				continue
+			}
+			vec, err := loadSnapshotEmbedding(ctx, branch, info, &metadata)
+			if err != nil {
+				continue
+			}
+			similarity, err := embeddings.CosineSimilarity(queryEmbedding, vec)
+			if err != nil {
+				continue
+			}
+			result.SemanticScore = similarity
+		}
+
+		results = append(results, result)
+	}
+
+	if queryEmbedding != nil {
+		sort.Slice(results, func(i, j int) bool {
+			return results[i].SemanticScore > results[j].SemanticScore
+		})
+		if findLimit > 0 && len(results) > findLimit {
+			results = results[:findLimit]
+		}
+	} else {
+		sort.Slice(results, func(i, j int) bool {
+			return results[i].Info.Timestamp.After(results[j].Info.Timestamp)
+		})
+	}
+
+	if len(results) == 0 {
+		fmt.Println("No snapshots match")
+		return nil
+	}
+
+	if findJSON {
+		output, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(output))
+		return nil
+	}
+
+	if findToon {
+		output, err := gotoon.Encode(results)
+		if err != nil {
+			return fmt.Errorf("failed to encode Toon: %w", err)
+		}
+		fmt.Println(output)
+		return nil
+	}
+
+	fmt.Printf("Found %d matching snapshot(s):\n\n", len(results))
+	for i, r := range results {
+		fmt.Printf("%d. %s\n", i+1, r.Info.Branch)
+		if queryEmbedding != nil {
+			fmt.Printf("   Similarity: %.4f\n", r.SemanticScore)
+		}
+		if r.MatchedPath != "" {
+			fmt.Printf("   Matched path: %s\n", r.MatchedPath)
+		}
+		if r.Excerpt != "" {
+			fmt.Printf("   Excerpt: %s\n", r.Excerpt)
+		}
+		if len(r.Metadata.Tags) > 0 {
+			fmt.Printf("   Tags: %v\n", r.Metadata.Tags)
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
+
+// matchesGenericPattern reports whether pattern (already lower-cased)
+// appears in a snapshot's topic, tags, or notes.
+func matchesGenericPattern(pattern string, metadata *models.Metadata) bool {
+	haystack := strings.ToLower(fmt.Sprintf("%s %s %v", metadata.Topic, metadata.Notes, metadata.Tags))
+	return strings.Contains(haystack, pattern)
+}
+
+// findMatchingPath returns the first tracked file in branch's tree whose
+// path matches glob, or "" if none match.
+func findMatchingPath(ctx context.Context, branch, glob string) (string, error) {
+	names, err := git.ListTreeRecursive(ctx, branch)
+	if err != nil {
+		return "", err
+	}
+	for _, name := range names {
+		if ok, _ := path.Match(glob, name); ok {
+			return name, nil
+		}
+		if ok, _ := path.Match(glob, path.Base(name)); ok {
+			return name, nil
+		}
+	}
+	return "", nil
+}
+
+// findMatchingExcerpt greps a snapshot's notes.md for re, returning the
+// first matching line trimmed for display.
+func findMatchingExcerpt(ctx context.Context, branch string, info snapshotInfo, re *regexp.Regexp) (string, bool) {
+	notesPath := models.ResearchPath(info.Timestamp, info.Topic) + "/notes.md"
+	content, err := gitShow(ctx, branch, notesPath)
+	if err != nil {
+		return "", false
+	}
+	for _, line := range strings.Split(content, "\n") {
+		if re.MatchString(line) {
+			return truncate(strings.TrimSpace(line), 120), true
+		}
+	}
+	return "", false
+}
+
+// treeMatcher tests a single string (a line of content, or a file path)
+// against the pattern --in-tree was given, honoring --regex and
+// --ignore-case.
+type treeMatcher struct {
+	re      *regexp.Regexp
+	literal string
+}
+
+func newTreeMatcher(pattern string) (treeMatcher, error) {
+	if findRegex {
+		expr := pattern
+		if findIgnoreCase {
+			expr = "(?i)" + expr
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return treeMatcher{}, fmt.Errorf("invalid --regex pattern: %w", err)
+		}
+		return treeMatcher{re: re}, nil
+	}
+	literal := pattern
+	if findIgnoreCase {
+		literal = strings.ToLower(literal)
+	}
+	return treeMatcher{literal: literal}, nil
+}
+
+func (m treeMatcher) MatchString(s string) bool {
+	if m.re != nil {
+		return m.re.MatchString(s)
+	}
+	if findIgnoreCase {
+		return strings.Contains(strings.ToLower(s), m.literal)
+	}
+	return strings.Contains(s, m.literal)
+}
+
+// treeMatch is one hit found while walking a snapshot's tree: a matching
+// path (--name), or a matching line within a matching path (content mode).
+type treeMatch struct {
+	Path string `json:"path"`
+	Line int    `json:"line,omitempty"`
+	Text string `json:"text,omitempty"`
+}
+
+// treeFindResult groups every match found within a single snapshot.
+type treeFindResult struct {
+	Info    snapshotInfo `json:"info"`
+	Matches []treeMatch  `json:"matches"`
+}
+
+// findInTreeBranches resolves the candidate snapshot branches for an
+// --in-tree search: --snapshot's explicit list if given, otherwise every
+// snapshot/* branch narrowed by --topic, --tag, --since, and --until.
+func findInTreeBranches(ctx context.Context, filter SnapshotFilter) ([]snapshotInfo, error) {
+	if len(findSnapshot) > 0 {
+		var infos []snapshotInfo
+		for _, branch := range findSnapshot {
+			if !git.BranchExists(ctx, branch) {
+				return nil, fmt.Errorf("snapshot branch does not exist: %s", branch)
+			}
+			info, err := parseSnapshotBranch(branch)
+			if err != nil {
+				return nil, fmt.Errorf("invalid snapshot branch %s: %w", branch, err)
+			}
+			infos = append(infos, info)
+		}
+		return infos, nil
+	}
+
+	branches, err := git.ListBranches(ctx, "snapshot/*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	var infos []snapshotInfo
+	for _, branch := range branches {
+		info, err := parseSnapshotBranch(branch)
+		if err != nil {
+			continue
+		}
+		if !filter.MatchesTopicAndDate(info) {
+			continue
+		}
+		info.LoadMetadata(ctx)
+		if !filter.MatchesTags(info.Metadata) {
+			continue
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// runFindInTree implements "context find --in-tree", a restic-find-style
+// search of every tracked file in each matching snapshot's tree, rather
+// than the snapshot-metadata search the bare "context find" mode runs.
+// Content matches are streamed through a single long-lived
+// "git cat-file --batch" subprocess so throughput doesn't pay a
+// process-spawn cost per file.
+func runFindInTree(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("--in-tree requires a pattern argument")
+	}
+	pattern := args[0]
+
+	matcher, err := newTreeMatcher(pattern)
+	if err != nil {
+		return err
+	}
+
+	var since, until time.Time
+	if findSince != "" {
+		t, err := time.Parse("2006-01-02", findSince)
+		if err != nil {
+			return fmt.Errorf("invalid --since date format (use YYYY-MM-DD): %w", err)
+		}
+		since = t
+	}
+	if findUntil != "" {
+		t, err := time.Parse("2006-01-02", findUntil)
+		if err != nil {
+			return fmt.Errorf("invalid --until date format (use YYYY-MM-DD): %w", err)
+		}
+		until = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	filter := SnapshotFilter{Topic: findTopic, Tags: findTags, Since: since, Until: until}
+
+	infos, err := findInTreeBranches(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	var batch *git.BatchCatFile
+	if !findName {
+		batch, err = git.NewBatchCatFile(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to start cat-file --batch: %w", err)
+		}
+		defer batch.Close()
+	}
+
+	var results []treeFindResult
+	for _, info := range infos {
+		branch := info.Branch
+		paths, err := git.ListTreeRecursive(ctx, branch)
+		if err != nil {
+			continue
+		}
+
+		var matches []treeMatch
+		for _, p := range paths {
+			if findPath != "" {
+				if ok, _ := path.Match(findPath, p); !ok {
+					if ok, _ := path.Match(findPath, path.Base(p)); !ok {
+						continue
+					}
+				}
+			}
+
+			if findName {
+				if matcher.MatchString(p) || matcher.MatchString(path.Base(p)) {
+					matches = append(matches, treeMatch{Path: p})
+				}
+				continue
+			}
+
+			content, err := batch.Read(branch + ":" + p)
+			if err != nil {
+				continue
+			}
+			for i, line := range strings.Split(string(content), "\n") {
+				if matcher.MatchString(line) {
+					matches = append(matches, treeMatch{
+						Path: p,
+						Line: i + 1,
+						Text: truncate(strings.TrimSpace(line), 160),
+					})
+				}
+			}
+		}
+
+		if len(matches) > 0 {
+			results = append(results, treeFindResult{Info: info, Matches: matches})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Info.Timestamp.After(results[j].Info.Timestamp)
+	})
+
+	if len(results) == 0 {
+		fmt.Println("No matches")
+		return nil
+	}
+
+	if findJSON {
+		output, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(output))
+		return nil
+	}
+
+	if findToon {
+		output, err := gotoon.Encode(results)
+		if err != nil {
+			return fmt.Errorf("failed to encode Toon: %w", err)
+		}
+		fmt.Println(output)
+		return nil
+	}
+
+	for _, r := range results {
+		fmt.Printf("%s\n", r.Info.Branch)
+		for _, m := range r.Matches {
+			if m.Line > 0 {
+				fmt.Printf("  %s:%d: %s\n", m.Path, m.Line, m.Text)
+			} else {
+				fmt.Printf("  %s\n", m.Path)
+			}
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
@@ -0,0 +1,387 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alpkeskin/gotoon"
+	"github.com/pders01/git-context/internal/config"
+	"github.com/pders01/git-context/internal/git"
+	"github.com/spf13/cobra"
+)
+
+var (
+	forgetKeepLast    int
+	forgetKeepHourly  int
+	forgetKeepDaily   int
+	forgetKeepWeekly  int
+	forgetKeepMonthly int
+	forgetKeepYearly  int
+	forgetKeepWithin  string
+	forgetKeepTags    []string
+	forgetGroupBy     string
+	forgetDryRun      bool
+	forgetPrune       bool
+	forgetJSON        bool
+	forgetToon        bool
+)
+
+var forgetCmd = &cobra.Command{
+	Use:   "forget",
+	Short: "Apply retention policies to remove old snapshots",
+	Long: `Apply restic-style retention policies to snapshot branches.
+
+Snapshots are grouped (by topic by default) and, within each group, the
+youngest snapshot in every active retention bucket is kept:
+
+  --keep-last N      Keep the N most recent snapshots regardless of bucket
+  --keep-hourly N    Keep one snapshot per hour for the last N hours with a snapshot
+  --keep-daily N     Keep one snapshot per day for the last N days with a snapshot
+  --keep-weekly N    Keep one snapshot per ISO week for the last N weeks with a snapshot
+  --keep-monthly N   Keep one snapshot per month for the last N months with a snapshot
+  --keep-yearly N    Keep one snapshot per year for the last N years with a snapshot
+  --keep-within DUR  Keep everything newer than DUR (e.g. 7d, 2w, 6m, 1y)
+  --keep-tag TAG     Always keep snapshots carrying this tag (repeatable)
+
+Snapshots whose tags intersect the configured retention.preserve_tags are
+always kept in addition to the policies above.
+
+Flag values fall back to the [retention] section of config.toml when unset.
+
+Examples:
+  context forget --keep-last 5 --keep-daily 7
+  context forget --keep-weekly 4 --keep-tag important --dry-run
+  context forget --keep-within 7d --keep-tag important
+  context forget --keep-monthly 12 --prune`,
+	RunE: runForget,
+}
+
+func init() {
+	rootCmd.AddCommand(forgetCmd)
+
+	forgetCmd.Flags().IntVar(&forgetKeepLast, "keep-last", 0, "Keep the N most recent snapshots")
+	forgetCmd.Flags().IntVar(&forgetKeepHourly, "keep-hourly", 0, "Keep N hourly snapshots")
+	forgetCmd.Flags().IntVar(&forgetKeepDaily, "keep-daily", 0, "Keep N daily snapshots")
+	forgetCmd.Flags().IntVar(&forgetKeepWeekly, "keep-weekly", 0, "Keep N weekly snapshots")
+	forgetCmd.Flags().IntVar(&forgetKeepMonthly, "keep-monthly", 0, "Keep N monthly snapshots")
+	forgetCmd.Flags().IntVar(&forgetKeepYearly, "keep-yearly", 0, "Keep N yearly snapshots")
+	forgetCmd.Flags().StringVar(&forgetKeepWithin, "keep-within", "", "Keep everything newer than this duration (e.g. 7d, 2w, 6m, 1y)")
+	forgetCmd.Flags().StringSliceVar(&forgetKeepTags, "keep-tag", []string{}, "Always keep snapshots with this tag (repeatable)")
+	forgetCmd.Flags().StringVar(&forgetGroupBy, "group-by", "topic", "Group snapshots before applying policies: topic, tags, related_branch (comma-separated, or none)")
+	forgetCmd.Flags().BoolVar(&forgetDryRun, "dry-run", true, "Show what would be forgotten without deleting")
+	forgetCmd.Flags().BoolVar(&forgetPrune, "prune", false, "Actually delete branches (overrides dry-run)")
+	forgetCmd.Flags().BoolVar(&forgetJSON, "json", false, "Output as JSON")
+	forgetCmd.Flags().BoolVar(&forgetToon, "toon", false, "Output in LLM-friendly toon format")
+}
+
+// forgetPolicy is a single retention bucket rule
+type forgetPolicy struct {
+	name   string
+	keep   int
+	bucket func(snapshotInfo) string
+}
+
+type forgetDecision struct {
+	Branch string `json:"branch"`
+	Kept   bool   `json:"kept"`
+	Reason string `json:"reason"`
+}
+
+type forgetGroupResult struct {
+	Group     string           `json:"group"`
+	Decisions []forgetDecision `json:"decisions"`
+}
+
+func runForget(cmd *cobra.Command, args []string) error {
+	ctx := commandContext(cmd)
+	if !git.IsGitRepo(ctx) {
+		return fmt.Errorf("not a git repository")
+	}
+
+	// --prune overrides the default dry-run, as documented in its flag help
+	// and the Long example above. If the user explicitly passed --dry-run
+	// themselves, honor that instead of silently ignoring it. cmd is nil in
+	// unit tests that call runForget directly, so guard the Changed check.
+	dryRunExplicit := cmd != nil && cmd.Flags().Changed("dry-run")
+	if forgetPrune && !dryRunExplicit {
+		forgetDryRun = false
+	}
+
+	keepLast := firstNonZero(forgetKeepLast, config.GetKeepLast())
+	keepHourly := firstNonZero(forgetKeepHourly, config.GetKeepHourly())
+	keepDaily := firstNonZero(forgetKeepDaily, config.GetKeepDaily())
+	keepWeekly := firstNonZero(forgetKeepWeekly, config.GetKeepWeekly())
+	keepMonthly := firstNonZero(forgetKeepMonthly, config.GetKeepMonthly())
+	keepYearly := firstNonZero(forgetKeepYearly, config.GetKeepYearly())
+
+	keepWithinStr := forgetKeepWithin
+	if keepWithinStr == "" {
+		keepWithinStr = config.GetKeepWithin()
+	}
+	var keepWithin time.Duration
+	if keepWithinStr != "" {
+		d, err := parseRetentionDuration(keepWithinStr)
+		if err != nil {
+			return fmt.Errorf("invalid --keep-within duration: %w", err)
+		}
+		keepWithin = d
+	}
+
+	keepTags := forgetKeepTags
+	if len(keepTags) == 0 {
+		keepTags = config.GetKeepTags()
+	}
+	preserveTags := config.GetPreserveTags()
+
+	branches, err := git.ListBranches(ctx, "snapshot/*")
+	if err != nil {
+		return fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	if len(branches) == 0 {
+		fmt.Println("No snapshots found")
+		return nil
+	}
+
+	var snapshots []snapshotInfo
+	for _, branch := range branches {
+		info, err := parseSnapshotBranch(branch)
+		if err != nil {
+			continue
+		}
+		info.LoadMetadata(ctx)
+		snapshots = append(snapshots, info)
+	}
+
+	groups := groupSnapshots(snapshots, forgetGroupBy)
+
+	policies := []forgetPolicy{
+		{"keep-hourly", keepHourly, func(s snapshotInfo) string { return s.Timestamp.Format("2006-01-02T15") }},
+		{"keep-daily", keepDaily, func(s snapshotInfo) string { return s.Timestamp.Format("2006-01-02") }},
+		{"keep-weekly", keepWeekly, func(s snapshotInfo) string { return isoWeekKey(s.Timestamp) }},
+		{"keep-monthly", keepMonthly, func(s snapshotInfo) string { return s.Timestamp.Format("2006-01") }},
+		{"keep-yearly", keepYearly, func(s snapshotInfo) string { return s.Timestamp.Format("2006") }},
+	}
+
+	var groupNames []string
+	for name := range groups {
+		groupNames = append(groupNames, name)
+	}
+	sort.Strings(groupNames)
+
+	var results []forgetGroupResult
+	var toDelete []string
+
+	for _, name := range groupNames {
+		group := groups[name]
+		sort.Slice(group, func(i, j int) bool {
+			return group[i].Timestamp.After(group[j].Timestamp)
+		})
+
+		used := make(map[string]map[string]bool)
+		for _, p := range policies {
+			used[p.name] = make(map[string]bool)
+		}
+
+		var decisions []forgetDecision
+		for i, s := range group {
+			var reasons []string
+
+			if keepLast > 0 && i < keepLast {
+				reasons = append(reasons, "keep-last")
+			}
+
+			if keepWithin > 0 && time.Since(s.Timestamp) <= keepWithin {
+				reasons = append(reasons, "keep-within")
+			}
+
+			for _, p := range policies {
+				if p.keep <= 0 {
+					continue
+				}
+				key := p.bucket(s)
+				if used[p.name][key] {
+					continue
+				}
+				if len(used[p.name]) >= p.keep {
+					continue
+				}
+				used[p.name][key] = true
+				reasons = append(reasons, p.name)
+			}
+
+			if s.Metadata != nil {
+				if tagsIntersect(s.Metadata.Tags, keepTags) {
+					reasons = append(reasons, "keep-tag")
+				}
+				if config.ShouldPreserve(s.Metadata.Tags) || tagsIntersect(s.Metadata.Tags, preserveTags) {
+					reasons = append(reasons, "preserve-tag")
+				}
+			}
+
+			kept := len(reasons) > 0
+			reason := strings.Join(reasons, ",")
+			if !kept {
+				reason = "no matching policy"
+				toDelete = append(toDelete, s.Branch)
+			}
+
+			decisions = append(decisions, forgetDecision{
+				Branch: s.Branch,
+				Kept:   kept,
+				Reason: reason,
+			})
+		}
+
+		results = append(results, forgetGroupResult{Group: name, Decisions: decisions})
+	}
+
+	if forgetJSON {
+		output, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(output))
+	} else if forgetToon {
+		output, err := gotoon.Encode(results)
+		if err != nil {
+			return fmt.Errorf("failed to encode Toon: %w", err)
+		}
+		fmt.Println(output)
+	} else {
+		printForgetResults(results)
+	}
+
+	if len(toDelete) == 0 {
+		fmt.Println("\nNo snapshots to forget")
+		return nil
+	}
+
+	if forgetPrune && !forgetDryRun {
+		fmt.Printf("\nForgetting %d snapshot(s)...\n", len(toDelete))
+		for _, branch := range toDelete {
+			fmt.Printf("  Deleting %s...\n", branch)
+			sha, _ := git.RevParseBranch(ctx, branch)
+			if err := git.DeleteBranch(ctx, branch, true); err != nil {
+				fmt.Printf("    Error: %v\n", err)
+				continue
+			}
+			if sha != "" {
+				getMetadataCache().Invalidate(sha)
+			}
+			fmt.Printf("    ✓ Deleted\n")
+		}
+		getMetadataCache().Save()
+	} else {
+		fmt.Println("\nThis is a dry run. Use --prune to actually forget snapshots.")
+	}
+
+	return nil
+}
+
+func printForgetResults(results []forgetGroupResult) {
+	for _, r := range results {
+		fmt.Printf("Group: %s\n", r.Group)
+		for _, d := range r.Decisions {
+			status := "forget"
+			if d.Kept {
+				status = "kept"
+			}
+			fmt.Printf("  [%-6s] %s  (%s)\n", status, d.Branch, d.Reason)
+		}
+		fmt.Println()
+	}
+}
+
+// groupSnapshots buckets snapshots by the requested comma-separated keys:
+// topic, tags, related_branch, or none
+func groupSnapshots(snapshots []snapshotInfo, groupBy string) map[string][]snapshotInfo {
+	groups := make(map[string][]snapshotInfo)
+
+	for _, s := range snapshots {
+		var keys []string
+		for _, field := range strings.Split(groupBy, ",") {
+			switch strings.TrimSpace(field) {
+			case "topic":
+				keys = append(keys, s.Topic)
+			case "tags":
+				if s.Metadata != nil && len(s.Metadata.Tags) > 0 {
+					keys = append(keys, strings.Join(s.Metadata.Tags, "+"))
+				}
+			case "related_branch":
+				if s.Metadata != nil && s.Metadata.RelatedBranch != "" {
+					keys = append(keys, s.Metadata.RelatedBranch)
+				}
+			}
+		}
+
+		key := strings.Join(keys, "/")
+		if key == "" {
+			key = "all"
+		}
+		groups[key] = append(groups[key], s)
+	}
+
+	return groups
+}
+
+// parseRetentionDuration parses restic-style retention durations: an
+// integer followed by one of h(our), d(ay), w(eek), m(onth), or y(ear).
+// Months and years are approximated as 30 and 365 days respectively, which
+// is precise enough for a retention cutoff.
+func parseRetentionDuration(s string) (time.Duration, error) {
+	if len(s) < 2 {
+		return 0, fmt.Errorf("duration %q must be a number followed by h, d, w, m, or y", s)
+	}
+
+	unit := s[len(s)-1]
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil {
+		return 0, fmt.Errorf("duration %q must be a number followed by h, d, w, m, or y", s)
+	}
+
+	day := 24 * time.Hour
+	switch unit {
+	case 'h':
+		return time.Duration(n) * time.Hour, nil
+	case 'd':
+		return time.Duration(n) * day, nil
+	case 'w':
+		return time.Duration(n) * 7 * day, nil
+	case 'm':
+		return time.Duration(n) * 30 * day, nil
+	case 'y':
+		return time.Duration(n) * 365 * day, nil
+	default:
+		return 0, fmt.Errorf("unknown duration unit %q (use h, d, w, m, or y)", string(unit))
+	}
+}
+
+func isoWeekKey(t time.Time) string {
+	year, week := t.ISOWeek()
+	return fmt.Sprintf("%04d-W%02d", year, week)
+}
+
+func tagsIntersect(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func firstNonZero(values ...int) int {
+	for _, v := range values {
+		if v != 0 {
+			return v
+		}
+	}
+	return 0
+}
@@ -0,0 +1,255 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pders01/git-context/internal/models"
+	"github.com/pders01/git-context/internal/testutil"
+	"github.com/spf13/viper"
+)
+
+func TestForgetNoSnapshots(t *testing.T) {
+	repo := testutil.NewTempGitRepo(t)
+	defer repo.Cleanup()
+
+	oldWd, _ := os.Getwd()
+	os.Chdir(repo.Path)
+	defer os.Chdir(oldWd)
+
+	forgetKeepLast = 0
+	forgetDryRun = true
+	forgetPrune = false
+
+	err := runForget(nil, []string{})
+	if err != nil {
+		t.Fatalf("forget command failed: %v", err)
+	}
+}
+
+func TestForgetKeepLastDryRun(t *testing.T) {
+	repo := testutil.NewTempGitRepo(t)
+	defer repo.Cleanup()
+
+	oldWd, _ := os.Getwd()
+	os.Chdir(repo.Path)
+	defer os.Chdir(oldWd)
+
+	createTestSnapshot(t, "snap-a", "full", []string{})
+	createTestSnapshot(t, "snap-b", "full", []string{})
+
+	forgetKeepLast = 1
+	forgetKeepHourly = 0
+	forgetKeepDaily = 0
+	forgetKeepWeekly = 0
+	forgetKeepMonthly = 0
+	forgetKeepYearly = 0
+	forgetKeepTags = []string{}
+	forgetGroupBy = "topic"
+	forgetDryRun = true
+	forgetPrune = false
+
+	err := runForget(nil, []string{})
+	if err != nil {
+		t.Fatalf("forget command failed: %v", err)
+	}
+
+	// Dry run must not delete anything, even though snap-a/snap-b are
+	// in different topic groups and each keeps its own newest snapshot.
+	branches := repo.GetBranches()
+	snapshotCount := 0
+	for _, branch := range branches {
+		if strings.Contains(branch, "snapshot") {
+			snapshotCount++
+		}
+	}
+
+	if snapshotCount != 2 {
+		t.Errorf("expected 2 snapshots after dry-run, got %d", snapshotCount)
+	}
+}
+
+func TestForgetKeepWithin(t *testing.T) {
+	repo := testutil.NewTempGitRepo(t)
+	defer repo.Cleanup()
+
+	oldWd, _ := os.Getwd()
+	os.Chdir(repo.Path)
+	defer os.Chdir(oldWd)
+
+	createTestSnapshot(t, "fresh-snap", "full", []string{})
+
+	forgetKeepLast = 0
+	forgetKeepHourly = 0
+	forgetKeepDaily = 0
+	forgetKeepWeekly = 0
+	forgetKeepMonthly = 0
+	forgetKeepYearly = 0
+	forgetKeepWithin = "1d"
+	forgetKeepTags = []string{}
+	forgetGroupBy = "topic"
+	forgetDryRun = false
+	forgetPrune = true
+	defer func() {
+		forgetKeepWithin = ""
+		forgetDryRun = true
+		forgetPrune = false
+	}()
+
+	err := runForget(nil, []string{})
+	if err != nil {
+		t.Fatalf("forget command failed: %v", err)
+	}
+
+	branches := repo.GetBranches()
+	snapshotCount := 0
+	for _, branch := range branches {
+		if strings.Contains(branch, "snapshot") {
+			snapshotCount++
+		}
+	}
+
+	if snapshotCount != 1 {
+		t.Errorf("expected snapshot created just now to survive --keep-within 1d, got %d", snapshotCount)
+	}
+}
+
+func TestForgetPruneActuallyDeletesBranches(t *testing.T) {
+	repo := testutil.NewTempGitRepo(t)
+	defer repo.Cleanup()
+
+	oldWd, _ := os.Getwd()
+	os.Chdir(repo.Path)
+	defer os.Chdir(oldWd)
+
+	createTestSnapshot(t, "important-work", "full", []string{"important"})
+	createTestSnapshot(t, "stale-work", "full", []string{})
+
+	forgetKeepLast = 0
+	forgetKeepHourly = 0
+	forgetKeepDaily = 0
+	forgetKeepWeekly = 0
+	forgetKeepMonthly = 0
+	forgetKeepYearly = 0
+	forgetKeepTags = []string{"important"}
+	forgetGroupBy = "topic"
+	forgetDryRun = false
+	forgetPrune = true
+	defer func() {
+		forgetDryRun = true
+		forgetPrune = false
+	}()
+
+	err := runForget(nil, []string{})
+	if err != nil {
+		t.Fatalf("forget command failed: %v", err)
+	}
+
+	branches := repo.GetBranches()
+	if !branchesContainTopic(branches, "important-work") {
+		t.Errorf("expected keep-tag snapshot for important-work to survive pruning")
+	}
+	if branchesContainTopic(branches, "stale-work") {
+		t.Errorf("expected untagged stale-work snapshot to be deleted by pruning")
+	}
+}
+
+func branchesContainTopic(branches []string, topic string) bool {
+	for _, b := range branches {
+		if strings.Contains(b, topic) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestGroupSnapshotsByRelatedBranch(t *testing.T) {
+	snapshots := []snapshotInfo{
+		{Branch: "snapshot/a", Metadata: &models.Metadata{RelatedBranch: "feature/auth"}},
+		{Branch: "snapshot/b", Metadata: &models.Metadata{RelatedBranch: "feature/auth"}},
+		{Branch: "snapshot/c", Metadata: &models.Metadata{RelatedBranch: "feature/billing"}},
+		{Branch: "snapshot/d", Metadata: nil},
+	}
+
+	groups := groupSnapshots(snapshots, "related_branch")
+
+	if len(groups["feature/auth"]) != 2 {
+		t.Errorf("expected 2 snapshots grouped under feature/auth, got %d", len(groups["feature/auth"]))
+	}
+	if len(groups["feature/billing"]) != 1 {
+		t.Errorf("expected 1 snapshot grouped under feature/billing, got %d", len(groups["feature/billing"]))
+	}
+	if len(groups["all"]) != 1 {
+		t.Errorf("expected the metadata-less snapshot to fall into the \"all\" group, got %d", len(groups["all"]))
+	}
+}
+
+func TestParseRetentionDuration(t *testing.T) {
+	cases := map[string]bool{
+		"7d":  true,
+		"2w":  true,
+		"6m":  true,
+		"1y":  true,
+		"3h":  true,
+		"":    false,
+		"7":   false,
+		"7x":  false,
+		"abc": false,
+	}
+
+	for input, wantOK := range cases {
+		_, err := parseRetentionDuration(input)
+		if wantOK && err != nil {
+			t.Errorf("parseRetentionDuration(%q) unexpected error: %v", input, err)
+		}
+		if !wantOK && err == nil {
+			t.Errorf("parseRetentionDuration(%q) expected an error, got none", input)
+		}
+	}
+}
+
+func TestForgetPreservesTaggedSnapshots(t *testing.T) {
+	repo := testutil.NewTempGitRepo(t)
+	defer repo.Cleanup()
+
+	oldWd, _ := os.Getwd()
+	os.Chdir(repo.Path)
+	defer os.Chdir(oldWd)
+
+	createTestSnapshot(t, "important-work", "full", []string{"important"})
+
+	forgetKeepLast = 0
+	forgetKeepHourly = 0
+	forgetKeepDaily = 0
+	forgetKeepWeekly = 0
+	forgetKeepMonthly = 0
+	forgetKeepYearly = 0
+	forgetKeepTags = []string{}
+	forgetGroupBy = "topic"
+	forgetDryRun = false
+	forgetPrune = true
+	viper.Set("retention.preserve_tags", []string{"important"})
+	defer func() {
+		viper.Set("retention.preserve_tags", nil)
+		forgetDryRun = true
+		forgetPrune = false
+	}()
+
+	err := runForget(nil, []string{})
+	if err != nil {
+		t.Fatalf("forget command failed: %v", err)
+	}
+
+	branches := repo.GetBranches()
+	snapshotCount := 0
+	for _, branch := range branches {
+		if strings.Contains(branch, "snapshot") {
+			snapshotCount++
+		}
+	}
+
+	if snapshotCount != 1 {
+		t.Errorf("expected 1 snapshot to survive (preserve_tags), got %d", snapshotCount)
+	}
+}
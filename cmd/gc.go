@@ -0,0 +1,364 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/alpkeskin/gotoon"
+	"github.com/pders01/git-context/internal/embeddings"
+	"github.com/pders01/git-context/internal/git"
+	"github.com/pders01/git-context/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	gcRepackEmbeddings bool
+	gcWindow           int
+	gcMinSimilarity    float64
+	gcPruneObjects     bool
+	gcDryRun           bool
+	gcJSON             bool
+	gcToon             bool
+)
+
+var gcCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Reclaim space in the snapshot store",
+	Long: `Run maintenance tasks that shrink the snapshot store without
+changing what it records.
+
+--repack-embeddings walks snapshot/* branches in chronological order and,
+for every snapshot after the first, looks for the most cosine-similar
+already-processed embedding among the last --window snapshots. If one is
+similar enough (cosine similarity >= --min-similarity), the snapshot's
+embedding is rewritten as a quantized delta against that base
+(embedding.delta, with embedding_base/embedding_delta/embedding_scale set
+in meta.json) and embedding.bin is removed. search and find resolve
+delta-compressed embeddings transparently by walking the base chain. The
+first embedding encountered in the graph is always kept in full.
+
+--prune-objects walks every snapshot/* branch's embedding pointer (see
+internal/embeddings.Put) to build the set of content-addressed objects
+still referenced by some snapshot, then deletes everything else from
+.git/context/objects. It's safe to run at any time: the store is purely a
+local dedup cache, never committed to any branch, and a pruned object is
+simply regenerated the next time a snapshot references it.
+
+Examples:
+  context gc --repack-embeddings
+  context gc --repack-embeddings --window 16 --min-similarity 0.97
+  context gc --repack-embeddings --dry-run
+  context gc --prune-objects`,
+	RunE: runGC,
+}
+
+func init() {
+	rootCmd.AddCommand(gcCmd)
+
+	gcCmd.Flags().BoolVar(&gcRepackEmbeddings, "repack-embeddings", false, "Re-select delta bases and compress embeddings across the snapshot graph")
+	gcCmd.Flags().IntVar(&gcWindow, "window", 8, "Number of recent resolved embeddings to consider as a delta base")
+	gcCmd.Flags().Float64Var(&gcMinSimilarity, "min-similarity", 0.95, "Minimum cosine similarity required to accept a delta base")
+	gcCmd.Flags().BoolVar(&gcPruneObjects, "prune-objects", false, "Delete content-addressed embedding objects no longer referenced by any snapshot")
+	gcCmd.Flags().BoolVar(&gcDryRun, "dry-run", false, "Show what would be repacked without committing")
+	gcCmd.Flags().BoolVar(&gcJSON, "json", false, "Output as JSON")
+	gcCmd.Flags().BoolVar(&gcToon, "toon", false, "Output in LLM-friendly toon format")
+}
+
+// gcDecision is what gc decided (or would decide) to do with one
+// snapshot's embedding.
+type gcDecision struct {
+	Branch     string  `json:"branch"`
+	Action     string  `json:"action"` // kept-raw, repacked, would-repack, skipped, error
+	Base       string  `json:"base,omitempty"`
+	Similarity float64 `json:"similarity,omitempty"`
+	Reason     string  `json:"reason,omitempty"`
+}
+
+func runGC(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if !git.IsGitRepo(ctx) {
+		return fmt.Errorf("not a git repository")
+	}
+
+	if !gcRepackEmbeddings && !gcPruneObjects {
+		fmt.Println("Nothing to do: pass --repack-embeddings or --prune-objects")
+		return nil
+	}
+
+	if gcPruneObjects {
+		if err := pruneObjects(ctx); err != nil {
+			return err
+		}
+	}
+
+	if !gcRepackEmbeddings {
+		return nil
+	}
+
+	branches, err := git.ListBranches(ctx, "snapshot/*")
+	if err != nil {
+		return fmt.Errorf("failed to list branches: %w", err)
+	}
+	if len(branches) == 0 {
+		fmt.Println("No snapshots found")
+		return nil
+	}
+
+	var snapshots []snapshotInfo
+	for _, branch := range branches {
+		info, err := parseSnapshotBranch(branch)
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, info)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Timestamp.Before(snapshots[j].Timestamp)
+	})
+
+	var window []embeddings.BaseCandidate
+	var decisions []gcDecision
+	repacked := 0
+
+	for _, info := range snapshots {
+		metadata, err := resolvedMetadata(ctx, info, "")
+		if err != nil {
+			decisions = append(decisions, gcDecision{Branch: info.Branch, Action: "error", Reason: fmt.Sprintf("failed to read metadata: %v", err)})
+			continue
+		}
+
+		if metadata.Embedding == "" && metadata.EmbeddingDelta == "" {
+			decisions = append(decisions, gcDecision{Branch: info.Branch, Action: "skipped", Reason: "no embedding"})
+			continue
+		}
+
+		vec, err := resolveSnapshotEmbedding(ctx, info.Branch, info, metadata, 0)
+		if err != nil {
+			decisions = append(decisions, gcDecision{Branch: info.Branch, Action: "error", Reason: fmt.Sprintf("failed to resolve embedding: %v", err)})
+			continue
+		}
+
+		if len(window) == 0 {
+			decisions = append(decisions, gcDecision{Branch: info.Branch, Action: "kept-raw", Reason: "first embedding in the graph"})
+			window = pushWindow(window, embeddings.BaseCandidate{Ref: info.Branch, Vector: vec}, gcWindow)
+			continue
+		}
+
+		best, sim, ok := embeddings.SelectBase(vec, window)
+		if !ok || sim < gcMinSimilarity {
+			reason := "no candidate met --min-similarity"
+			if !ok {
+				reason = "no comparable candidate in window"
+			}
+			decisions = append(decisions, gcDecision{Branch: info.Branch, Action: "kept-raw", Similarity: sim, Reason: reason})
+			window = pushWindow(window, embeddings.BaseCandidate{Ref: info.Branch, Vector: vec}, gcWindow)
+			continue
+		}
+
+		if metadata.EmbeddingDelta != "" && metadata.EmbeddingBase == best.Ref {
+			decisions = append(decisions, gcDecision{Branch: info.Branch, Action: "skipped", Base: best.Ref, Similarity: sim, Reason: "already delta-compressed against the optimal base"})
+			window = pushWindow(window, embeddings.BaseCandidate{Ref: info.Branch, Vector: vec}, gcWindow)
+			continue
+		}
+
+		if gcDryRun {
+			decisions = append(decisions, gcDecision{Branch: info.Branch, Action: "would-repack", Base: best.Ref, Similarity: sim})
+			window = pushWindow(window, embeddings.BaseCandidate{Ref: info.Branch, Vector: vec}, gcWindow)
+			continue
+		}
+
+		if err := repackEmbedding(ctx, info, *metadata, best.Ref, best.Vector, vec); err != nil {
+			decisions = append(decisions, gcDecision{Branch: info.Branch, Action: "error", Base: best.Ref, Similarity: sim, Reason: err.Error()})
+			window = pushWindow(window, embeddings.BaseCandidate{Ref: info.Branch, Vector: vec}, gcWindow)
+			continue
+		}
+
+		repacked++
+		decisions = append(decisions, gcDecision{Branch: info.Branch, Action: "repacked", Base: best.Ref, Similarity: sim})
+		window = pushWindow(window, embeddings.BaseCandidate{Ref: info.Branch, Vector: vec}, gcWindow)
+	}
+
+	if gcJSON {
+		output, err := json.MarshalIndent(decisions, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(output))
+		return nil
+	}
+	if gcToon {
+		output, err := gotoon.Encode(decisions)
+		if err != nil {
+			return fmt.Errorf("failed to encode Toon: %w", err)
+		}
+		fmt.Println(output)
+		return nil
+	}
+
+	printGCReport(decisions, repacked)
+	return nil
+}
+
+// pushWindow appends c to window, trimmed to the most recent size entries.
+func pushWindow(window []embeddings.BaseCandidate, c embeddings.BaseCandidate, size int) []embeddings.BaseCandidate {
+	window = append(window, c)
+	if len(window) > size {
+		window = window[len(window)-size:]
+	}
+	return window
+}
+
+func printGCReport(decisions []gcDecision, repacked int) {
+	for _, d := range decisions {
+		switch d.Action {
+		case "repacked":
+			fmt.Printf("  ✓ %s -> delta against %s (similarity %.4f)\n", d.Branch, d.Base, d.Similarity)
+		case "would-repack":
+			fmt.Printf("  + %s -> would delta against %s (similarity %.4f)\n", d.Branch, d.Base, d.Similarity)
+		case "error":
+			fmt.Printf("  ✗ %s (%s)\n", d.Branch, d.Reason)
+		default:
+			fmt.Printf("  = %s (%s)\n", d.Branch, d.Reason)
+		}
+	}
+	fmt.Printf("\n%d snapshot(s) repacked\n", repacked)
+}
+
+// pruneObjects walks every snapshot/* branch's embedding pointer to build
+// the set of content-addressed objects still in use, then deletes
+// everything else under .git/context/objects.
+func pruneObjects(ctx context.Context) error {
+	branches, err := git.ListBranches(ctx, "snapshot/*")
+	if err != nil {
+		return fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	reachable := make(map[string]bool)
+	for _, branch := range branches {
+		info, err := parseSnapshotBranch(branch)
+		if err != nil {
+			continue
+		}
+		metadata, err := resolvedMetadata(ctx, info, "")
+		if err != nil || metadata.Embedding == "" {
+			continue
+		}
+
+		payloadPath := filepath.Join(models.ResearchPath(info.Timestamp, info.Topic), metadata.Embedding)
+		payload, err := gitShow(ctx, branch, payloadPath)
+		if err != nil || !embeddings.IsPointer([]byte(payload)) {
+			continue
+		}
+		ptr, err := embeddings.ParsePointer([]byte(payload))
+		if err != nil {
+			continue
+		}
+		reachable[ptr.SHA256] = true
+	}
+
+	if gcDryRun {
+		fmt.Printf("Would check %d object(s) against %d reachable embedding(s)\n", countObjects(), len(reachable))
+		return nil
+	}
+
+	removed, err := embeddings.Prune(casObjectsDir(), reachable)
+	if err != nil {
+		return fmt.Errorf("failed to prune embedding objects: %w", err)
+	}
+	fmt.Printf("Pruned %d unreferenced embedding object(s)\n", removed)
+	return nil
+}
+
+// countObjects returns how many objects currently exist in the
+// content-addressed store, for --dry-run reporting.
+func countObjects() int {
+	count := 0
+	shards, err := os.ReadDir(casObjectsDir())
+	if err != nil {
+		return 0
+	}
+	for _, shard := range shards {
+		if !shard.IsDir() {
+			continue
+		}
+		entries, err := os.ReadDir(filepath.Join(casObjectsDir(), shard.Name()))
+		if err != nil {
+			continue
+		}
+		count += len(entries)
+	}
+	return count
+}
+
+// repackEmbedding rewrites a snapshot's embedding as a quantized delta
+// against baseBranch, committing the change on the snapshot branch
+// itself, the same way repairEmbedding commits an embedding fix.
+func repackEmbedding(ctx context.Context, info snapshotInfo, metadata models.Metadata, baseBranch string, baseVec, vec []float64) error {
+	branch := info.Branch
+	oldSHA, _ := git.RevParseBranch(ctx, branch)
+
+	worktreePath := filepath.Join(os.TempDir(), fmt.Sprintf("context-gc-%d", time.Now().UnixNano()))
+	if err := git.CreateWorktree(ctx, worktreePath, branch); err != nil {
+		return err
+	}
+	defer func() {
+		if err := git.RemoveWorktree(ctx, worktreePath); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to remove worktree: %v\n", err)
+		}
+	}()
+
+	researchPath := models.ResearchPath(info.Timestamp, info.Topic)
+	worktreeResearchPath := filepath.Join(worktreePath, researchPath)
+
+	delta, scale, err := embeddings.EncodeDelta(baseVec, vec)
+	if err != nil {
+		return fmt.Errorf("failed to encode delta: %w", err)
+	}
+
+	if metadata.Embedding != "" {
+		if err := os.Remove(filepath.Join(worktreeResearchPath, metadata.Embedding)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove raw embedding %s: %w", metadata.Embedding, err)
+		}
+	}
+
+	deltaPath := filepath.Join(worktreeResearchPath, "embedding.delta")
+	if err := os.WriteFile(deltaPath, delta, 0644); err != nil {
+		return fmt.Errorf("failed to write delta: %w", err)
+	}
+
+	metadata.Embedding = ""
+	metadata.EmbeddingDelta = "embedding.delta"
+	metadata.EmbeddingBase = baseBranch
+	metadata.EmbeddingScale = scale
+
+	metaBytes, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal repacked metadata: %w", err)
+	}
+	metaPath := models.MetadataPath(info.Timestamp, info.Topic)
+	if err := os.WriteFile(filepath.Join(worktreePath, metaPath), metaBytes, 0644); err != nil {
+		return fmt.Errorf("failed to write repacked metadata: %w", err)
+	}
+
+	if err := git.AddFilesInDir(ctx, worktreePath, researchPath); err != nil {
+		return err
+	}
+	if err := git.CommitInDir(ctx, worktreePath, fmt.Sprintf("gc --repack-embeddings: %s\n\nDelta-compressed embedding against %s", branch, baseBranch)); err != nil {
+		return fmt.Errorf("failed to commit repack: %w", err)
+	}
+
+	if oldSHA != "" {
+		getMetadataCache().Invalidate(oldSHA)
+		getMetadataCache().Save()
+		getEmbeddingCache().Invalidate(oldSHA)
+		getEmbeddingCache().Save()
+	}
+
+	return nil
+}
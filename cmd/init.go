@@ -27,7 +27,7 @@ func init() {
 }
 
 func runInit(cmd *cobra.Command, args []string) error {
-	if !git.IsGitRepo() {
+	if !git.IsGitRepo(commandContext(cmd)) {
 		return fmt.Errorf("not a git repository")
 	}
 
@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/alpkeskin/gotoon"
+	"github.com/pders01/git-context/internal/git"
+	"github.com/spf13/cobra"
+)
+
+var (
+	mergeBaseJSON bool
+	mergeBaseToon bool
+	ancestryJSON  bool
+	ancestryToon  bool
+)
+
+var mergeBaseCmd = &cobra.Command{
+	Use:   "merge-base <timestamp1> <topic1> <timestamp2> <topic2>",
+	Short: "Find the common ancestor commit between two snapshots",
+	Long: `Find the commit on main both snapshots ultimately descend from, by
+computing the merge base of their main_commit values.
+
+Example:
+  context merge-base 2025-11-14T2252 initial-reconnaissance 2025-11-20T0930 vulnerability-analysis`,
+	Args: cobra.ExactArgs(4),
+	RunE: runMergeBase,
+}
+
+var ancestryCmd = &cobra.Command{
+	Use:   "ancestry <timestamp> <topic>",
+	Short: "List snapshots whose main commit is an ancestor of this one",
+	Long: `Walk every snapshot branch and list those whose main_commit is an
+ancestor of the target snapshot's main_commit, ordered oldest to newest by
+committer date. This traces how a snapshot's view of main built on prior
+snapshots' views.
+
+Example:
+  context ancestry 2025-11-20T0930 vulnerability-analysis`,
+	Args: cobra.ExactArgs(2),
+	RunE: runAncestry,
+}
+
+func init() {
+	rootCmd.AddCommand(mergeBaseCmd)
+	rootCmd.AddCommand(ancestryCmd)
+
+	mergeBaseCmd.Flags().BoolVar(&mergeBaseJSON, "json", false, "Output as JSON")
+	mergeBaseCmd.Flags().BoolVar(&mergeBaseToon, "toon", false, "Output in LLM-friendly toon format")
+
+	ancestryCmd.Flags().BoolVar(&ancestryJSON, "json", false, "Output as JSON")
+	ancestryCmd.Flags().BoolVar(&ancestryToon, "toon", false, "Output in LLM-friendly toon format")
+}
+
+type mergeBaseResult struct {
+	Snapshot1 string `json:"snapshot1"`
+	Snapshot2 string `json:"snapshot2"`
+	Commit1   string `json:"commit1"`
+	Commit2   string `json:"commit2"`
+	Base      string `json:"merge_base"`
+}
+
+func runMergeBase(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if !git.IsGitRepo(ctx) {
+		return fmt.Errorf("not a git repository")
+	}
+
+	info1, commit1, err := lineageSnapshotCommit(ctx, args[0], args[1])
+	if err != nil {
+		return err
+	}
+	info2, commit2, err := lineageSnapshotCommit(ctx, args[2], args[3])
+	if err != nil {
+		return err
+	}
+
+	base, err := git.MergeBase(ctx, commit1, commit2)
+	if err != nil {
+		return fmt.Errorf("failed to compute merge base: %w", err)
+	}
+
+	result := mergeBaseResult{
+		Snapshot1: info1.Branch,
+		Snapshot2: info2.Branch,
+		Commit1:   commit1,
+		Commit2:   commit2,
+		Base:      base,
+	}
+
+	if mergeBaseJSON {
+		output, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(output))
+		return nil
+	}
+
+	if mergeBaseToon {
+		output, err := gotoon.Encode(result)
+		if err != nil {
+			return fmt.Errorf("failed to encode Toon: %w", err)
+		}
+		fmt.Println(output)
+		return nil
+	}
+
+	fmt.Printf("Snapshot 1: %s (%s)\n", result.Snapshot1, result.Commit1[:8])
+	fmt.Printf("Snapshot 2: %s (%s)\n", result.Snapshot2, result.Commit2[:8])
+	fmt.Printf("Merge base: %s\n", result.Base)
+
+	return nil
+}
+
+// lineageSnapshotCommit resolves a snapshot branch and its main_commit,
+// the starting point for merge-base and ancestry.
+func lineageSnapshotCommit(ctx context.Context, timestampStr, topic string) (snapshotInfo, string, error) {
+	branch := fmt.Sprintf("snapshot/%s/%s", timestampStr, topic)
+	if !git.BranchExists(ctx, branch) {
+		return snapshotInfo{}, "", fmt.Errorf("snapshot branch does not exist: %s", branch)
+	}
+
+	info, err := parseSnapshotBranch(branch)
+	if err != nil {
+		return snapshotInfo{}, "", fmt.Errorf("failed to parse snapshot: %w", err)
+	}
+
+	metadata, err := resolvedMetadata(ctx, info, "")
+	if err != nil {
+		return snapshotInfo{}, "", fmt.Errorf("failed to load metadata for %s: %w", branch, err)
+	}
+	if metadata.MainCommit == "" {
+		return snapshotInfo{}, "", fmt.Errorf("snapshot %s has no main_commit", branch)
+	}
+	info.Metadata = metadata
+
+	return info, metadata.MainCommit, nil
+}
+
+type ancestrySnapshot struct {
+	Branch     string    `json:"branch"`
+	Topic      string    `json:"topic"`
+	MainCommit string    `json:"main_commit"`
+	CommitDate time.Time `json:"commit_date"`
+}
+
+func runAncestry(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if !git.IsGitRepo(ctx) {
+		return fmt.Errorf("not a git repository")
+	}
+
+	targetInfo, targetCommit, err := lineageSnapshotCommit(ctx, args[0], args[1])
+	if err != nil {
+		return err
+	}
+
+	branches, err := git.ListBranches(ctx, "snapshot/*")
+	if err != nil {
+		return fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	var ancestry []ancestrySnapshot
+	for _, branch := range branches {
+		if branch == targetInfo.Branch {
+			continue
+		}
+
+		info, err := parseSnapshotBranch(branch)
+		if err != nil {
+			continue
+		}
+
+		metadata, err := resolvedMetadata(ctx, info, "")
+		if err != nil || metadata.MainCommit == "" {
+			continue
+		}
+		if metadata.MainCommit == targetCommit {
+			continue
+		}
+		if !git.IsAncestor(ctx, metadata.MainCommit, targetCommit) {
+			continue
+		}
+
+		date, err := git.CommitDate(ctx, metadata.MainCommit)
+		if err != nil {
+			continue
+		}
+
+		ancestry = append(ancestry, ancestrySnapshot{
+			Branch:     info.Branch,
+			Topic:      info.Topic,
+			MainCommit: metadata.MainCommit,
+			CommitDate: date,
+		})
+	}
+
+	sort.Slice(ancestry, func(i, j int) bool {
+		return ancestry[i].CommitDate.Before(ancestry[j].CommitDate)
+	})
+
+	if ancestryJSON {
+		output, err := json.MarshalIndent(ancestry, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(output))
+		return nil
+	}
+
+	if ancestryToon {
+		output, err := gotoon.Encode(ancestry)
+		if err != nil {
+			return fmt.Errorf("failed to encode Toon: %w", err)
+		}
+		fmt.Println(output)
+		return nil
+	}
+
+	if len(ancestry) == 0 {
+		fmt.Printf("No ancestor snapshots found for %s\n", targetInfo.Branch)
+		return nil
+	}
+
+	fmt.Printf("Ancestors of %s:\n\n", targetInfo.Branch)
+	for i, a := range ancestry {
+		fmt.Printf("%d. %s [%s]\n", i+1, a.Branch, a.CommitDate.Format("2006-01-02 15:04"))
+		fmt.Printf("   main_commit: %s\n", a.MainCommit[:8])
+	}
+
+	return nil
+}
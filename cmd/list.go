@@ -1,16 +1,22 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/alpkeskin/gotoon"
+	"github.com/pders01/git-context/internal/cache"
+	"github.com/pders01/git-context/internal/config"
 	"github.com/pders01/git-context/internal/git"
 	"github.com/pders01/git-context/internal/models"
+	"github.com/pders01/git-context/internal/query"
+	"github.com/pders01/git-context/internal/signing"
 	"github.com/spf13/cobra"
 )
 
@@ -22,6 +28,8 @@ var (
 	listToon    bool
 	listTags    []string
 	listGroupBy string
+	listFilter  string
+	listVerify  bool
 )
 
 var listCmd = &cobra.Command{
@@ -37,7 +45,10 @@ Examples:
   context list --today
   context list --since 2025-10-01
   context list --group-by tag
-  context list --group-by date`,
+  context list --group-by date
+  context list --group-by mode,tag,month
+  context list --filter 'tag=security,mode=poc,since=2025-10-01'
+  context list --verify  # drop unsigned snapshots, fail on bad signatures`,
 	RunE: runList,
 }
 
@@ -48,26 +59,38 @@ func init() {
 	listCmd.Flags().StringSliceVar(&listTags, "tag", []string{}, "Filter by tag(s) - multiple tags use AND logic")
 	listCmd.Flags().BoolVar(&listToday, "today", false, "Show only today's snapshots")
 	listCmd.Flags().StringVar(&listSince, "since", "", "Show snapshots since date (YYYY-MM-DD)")
-	listCmd.Flags().StringVar(&listGroupBy, "group-by", "", "Group output by: tag, date, or mode")
+	listCmd.Flags().StringVar(&listGroupBy, "group-by", "", "Group output by one or more comma-separated fields: tag, mode, topic, date, month")
+	listCmd.Flags().StringVar(&listFilter, "filter", "", "Filter by a comma-separated field=value expression (tag, mode, topic, since, until)")
 	listCmd.Flags().BoolVar(&listJSON, "json", false, "Output as JSON")
 	listCmd.Flags().BoolVar(&listToon, "toon", false, "Output in LLM-friendly toon format")
+	listCmd.Flags().BoolVar(&listVerify, "verify", false, "Drop unsigned snapshots; hard-fail if a signed snapshot's signature is invalid")
 }
 
 func runList(cmd *cobra.Command, args []string) error {
-	if !git.IsGitRepo() {
+	ctx := commandContext(cmd)
+	if !git.IsGitRepo(ctx) {
 		return fmt.Errorf("not a git repository")
 	}
 
 	// Validate --since date format early
+	var sinceDate time.Time
 	if listSince != "" {
-		_, err := time.Parse("2006-01-02", listSince)
+		parsed, err := time.Parse("2006-01-02", listSince)
 		if err != nil {
 			return fmt.Errorf("invalid --since date format (use YYYY-MM-DD): %w", err)
 		}
+		sinceDate = parsed
+	}
+	filter := SnapshotFilter{Topic: listTopic, Tags: listTags, Since: sinceDate}
+
+	// Validate --filter early
+	filterExpr, err := query.Parse(listFilter)
+	if err != nil {
+		return fmt.Errorf("invalid --filter expression: %w", err)
 	}
 
 	// Get all snapshot branches
-	branches, err := git.ListBranches("snapshot/*")
+	branches, err := git.ListBranches(ctx, "snapshot/*")
 	if err != nil {
 		return fmt.Errorf("failed to list branches: %w", err)
 	}
@@ -87,7 +110,7 @@ func runList(cmd *cobra.Command, args []string) error {
 		}
 
 		// Apply filters
-		if listTopic != "" && info.Topic != listTopic {
+		if !filter.MatchesTopicAndDate(info) {
 			continue
 		}
 
@@ -99,16 +122,6 @@ func runList(cmd *cobra.Command, args []string) error {
 			}
 		}
 
-		if listSince != "" {
-			sinceDate, err := time.Parse("2006-01-02", listSince)
-			if err != nil {
-				return fmt.Errorf("invalid --since date format (use YYYY-MM-DD): %w", err)
-			}
-			if info.Timestamp.Before(sinceDate) {
-				continue
-			}
-		}
-
 		snapshots = append(snapshots, info)
 	}
 
@@ -124,34 +137,29 @@ func runList(cmd *cobra.Command, args []string) error {
 
 	// Load metadata for all snapshots
 	for i := range snapshots {
-		snapshots[i].LoadMetadata()
+		snapshots[i].LoadMetadata(ctx)
 	}
 
 	// Apply tag filter (needs metadata loaded) - AND logic for multiple tags
-	if len(listTags) > 0 {
+	if len(filter.Tags) > 0 {
 		var filtered []snapshotInfo
 		for _, s := range snapshots {
-			if s.Metadata == nil {
-				continue
+			if filter.MatchesTags(s.Metadata) {
+				filtered = append(filtered, s)
 			}
+		}
+		snapshots = filtered
+	}
 
-			// Check if snapshot has ALL required tags (AND logic)
-			hasAllTags := true
-			for _, requiredTag := range listTags {
-				found := false
-				for _, snapshotTag := range s.Metadata.Tags {
-					if snapshotTag == requiredTag {
-						found = true
-						break
-					}
-				}
-				if !found {
-					hasAllTags = false
-					break
-				}
+	// Apply --filter (needs metadata loaded)
+	if listFilter != "" {
+		var filtered []snapshotInfo
+		for _, s := range snapshots {
+			var metadata models.Metadata
+			if s.Metadata != nil {
+				metadata = *s.Metadata
 			}
-
-			if hasAllTags {
+			if filterExpr.Match(query.Record{Metadata: metadata, Timestamp: s.Timestamp}) {
 				filtered = append(filtered, s)
 			}
 		}
@@ -163,6 +171,17 @@ func runList(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	if listVerify {
+		snapshots, err = filterVerifiedSnapshots(snapshots)
+		if err != nil {
+			return err
+		}
+		if len(snapshots) == 0 {
+			fmt.Println("No signed snapshots match the filter criteria")
+			return nil
+		}
+	}
+
 	// Handle grouping if requested
 	if listGroupBy != "" && !listJSON && !listToon {
 		return displayGrouped(snapshots, listGroupBy)
@@ -212,6 +231,7 @@ func runList(cmd *cobra.Command, args []string) error {
 			if s.HasEmbedding {
 				fmt.Printf("    Embedding: ✓\n")
 			}
+			printSignatureStatus(s, "    ")
 		}
 		fmt.Println()
 	}
@@ -219,18 +239,101 @@ func runList(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-// displayGrouped shows snapshots grouped by a specified field
+// printSignatureStatus prints a snapshot's signature line if LoadMetadata
+// found one, valid or not; unsigned snapshots print nothing.
+func printSignatureStatus(s snapshotInfo, indent string) {
+	if s.SignedBy == "" {
+		return
+	}
+	mark := "?"
+	switch {
+	case s.Signed:
+		mark = "✓"
+	case s.SignedBy == "BAD SIGNATURE":
+		mark = "✗"
+	}
+	fmt.Printf("%sSigned:  %s %s\n", indent, mark, s.SignedBy)
+}
+
+// filterVerifiedSnapshots drops unsigned snapshots and hard-fails if any
+// signed snapshot's signature doesn't check out, since that's a sign of
+// tampering rather than a simply-unsigned legacy snapshot.
+func filterVerifiedSnapshots(snapshots []snapshotInfo) ([]snapshotInfo, error) {
+	if config.GetSigningKey() == "" {
+		return nil, fmt.Errorf("--verify requires a signing.key to be configured")
+	}
+
+	var verified []snapshotInfo
+	for _, s := range snapshots {
+		switch {
+		case s.Signed:
+			verified = append(verified, s)
+		case s.SignedBy == "BAD SIGNATURE":
+			return nil, fmt.Errorf("snapshot %s failed signature verification", s.Branch)
+		}
+	}
+	return verified, nil
+}
+
+// displayGrouped shows snapshots grouped by one or more comma-separated
+// fields. A single legacy dimension (tag, date, or mode) keeps the
+// original per-value fan-out display, where a snapshot with multiple tags
+// appears once under each; a composite group-by (e.g. "mode,tag,month")
+// instead assigns each snapshot to a single group keyed by all dimensions
+// joined together.
 func displayGrouped(snapshots []snapshotInfo, groupBy string) error {
-	switch groupBy {
-	case "tag":
-		return displayGroupedByTag(snapshots)
-	case "date":
-		return displayGroupedByDate(snapshots)
-	case "mode":
-		return displayGroupedByMode(snapshots)
-	default:
-		return fmt.Errorf("invalid group-by value: %s (must be: tag, date, or mode)", groupBy)
+	dims := strings.Split(groupBy, ",")
+	for i := range dims {
+		dims[i] = strings.TrimSpace(dims[i])
+	}
+
+	if len(dims) == 1 {
+		switch dims[0] {
+		case "tag":
+			return displayGroupedByTag(snapshots)
+		case "date":
+			return displayGroupedByDate(snapshots)
+		case "mode":
+			return displayGroupedByMode(snapshots)
+		}
+	}
+
+	return displayGroupedComposite(snapshots, dims)
+}
+
+// displayGroupedComposite groups snapshots by a composite key built from
+// dims via query.GroupKey, the general case behind displayGrouped.
+func displayGroupedComposite(snapshots []snapshotInfo, dims []string) error {
+	groupOrder := []string{}
+	groups := make(map[string][]snapshotInfo)
+
+	for _, s := range snapshots {
+		var metadata models.Metadata
+		if s.Metadata != nil {
+			metadata = *s.Metadata
+		}
+		key, err := query.GroupKey(dims, query.Record{Metadata: metadata, Timestamp: s.Timestamp})
+		if err != nil {
+			return fmt.Errorf("invalid --group-by: %w", err)
+		}
+		if _, seen := groups[key]; !seen {
+			groupOrder = append(groupOrder, key)
+		}
+		groups[key] = append(groups[key], s)
+	}
+
+	sort.Strings(groupOrder)
+
+	fmt.Printf("Found %d snapshot(s) grouped by %s:\n\n", len(snapshots), strings.Join(dims, ","))
+	for _, key := range groupOrder {
+		items := groups[key]
+		fmt.Printf("━━━ %s (%d) ━━━\n\n", key, len(items))
+		for _, s := range items {
+			displaySnapshot(s, "  ")
+		}
 	}
+
+	return nil
 }
 
 // displayGroupedByTag groups snapshots by their tags
@@ -358,44 +461,187 @@ func displaySnapshot(s snapshotInfo, indent string) {
 		if s.HasEmbedding {
 			fmt.Printf("%s  Embedding: ✓\n", indent)
 		}
+		printSignatureStatus(s, indent+"  ")
 	}
 	fmt.Println()
 }
 
+// SnapshotFilter narrows a set of snapshots by topic, tags, and a date
+// range - the dimensions list and find both filter on, extracted here so
+// the two commands can't drift on what "topic" or "since" means. An
+// empty field imposes no constraint.
+type SnapshotFilter struct {
+	Topic string
+	Tags  []string
+	Since time.Time
+	Until time.Time
+}
+
+// MatchesTopicAndDate checks the filter dimensions available before a
+// snapshot's metadata has been loaded.
+func (f SnapshotFilter) MatchesTopicAndDate(info snapshotInfo) bool {
+	if f.Topic != "" && info.Topic != f.Topic {
+		return false
+	}
+	if !f.Since.IsZero() && info.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && info.Timestamp.After(f.Until) {
+		return false
+	}
+	return true
+}
+
+// MatchesTags reports whether metadata carries every tag in f.Tags (AND
+// logic); it requires metadata to already be loaded.
+func (f SnapshotFilter) MatchesTags(metadata *models.Metadata) bool {
+	if len(f.Tags) == 0 {
+		return true
+	}
+	if metadata == nil {
+		return false
+	}
+	for _, required := range f.Tags {
+		found := false
+		for _, tag := range metadata.Tags {
+			if tag == required {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
 type snapshotInfo struct {
-	Branch      string             `json:"branch"`
-	Timestamp   time.Time          `json:"timestamp"`
-	Topic       string             `json:"topic"`
-	Metadata    *models.Metadata   `json:"metadata,omitempty"`
-	HasEmbedding bool              `json:"has_embedding"`
+	Branch       string           `json:"branch"`
+	Timestamp    time.Time        `json:"timestamp"`
+	Topic        string           `json:"topic"`
+	Metadata     *models.Metadata `json:"metadata,omitempty"`
+	HasEmbedding bool             `json:"has_embedding"`
+	Signed       bool             `json:"signed"`
+	SignedBy     string           `json:"signed_by,omitempty"`
+}
+
+var (
+	metadataCacheOnce sync.Once
+	metadataCache     *cache.MetadataLRU
+)
+
+// getMetadataCache returns the process-wide metadata LRU, warmed from the
+// on-disk cache on first use.
+func getMetadataCache() *cache.MetadataLRU {
+	metadataCacheOnce.Do(func() {
+		metadataCache = cache.NewMetadataLRU(config.GetMetadataCacheBytes())
+	})
+	return metadataCache
 }
 
-func (s *snapshotInfo) LoadMetadata() *models.Metadata {
+func (s *snapshotInfo) LoadMetadata(ctx context.Context) *models.Metadata {
 	if s.Metadata != nil {
 		return s.Metadata
 	}
 
-	metaPath := models.MetadataPath(s.Timestamp, s.Topic)
-	metaContent, err := gitShow(s.Branch, metaPath)
+	mc := getMetadataCache()
+
+	sha, err := git.RevParseBranch(ctx, s.Branch)
 	if err != nil {
 		return nil
 	}
 
-	var metadata models.Metadata
-	if err := json.Unmarshal([]byte(metaContent), &metadata); err != nil {
+	if cached, ok := mc.Get(sha); ok {
+		s.Metadata = cached
+		if cached.Embedding != "" {
+			embeddingPath := models.ResearchPath(s.Timestamp, s.Topic) + "/" + cached.Embedding
+			_, err := gitShow(ctx, s.Branch, embeddingPath)
+			s.HasEmbedding = err == nil
+		}
+		s.loadSignatureStatus(ctx, cached)
+		return cached
+	}
+
+	// Resolve against the operation log (see context log / context tag), not
+	// just the base meta.json blob, so callers see tags/mode/notes as they
+	// currently stand rather than as they stood when the snapshot was
+	// created. The cache key above is the branch's current tip SHA, which
+	// changes whenever an op is appended, so a stale resolved view is never
+	// served from cache.
+	metadata, err := resolvedMetadata(ctx, *s, "")
+	if err != nil {
 		return nil
 	}
 
-	s.Metadata = &metadata
+	s.Metadata = metadata
+	mc.Add(sha, metadata)
+	mc.Save()
 
 	// Check if snapshot has embedding
 	if metadata.Embedding != "" {
 		embeddingPath := models.ResearchPath(s.Timestamp, s.Topic) + "/" + metadata.Embedding
-		_, err := gitShow(s.Branch, embeddingPath)
+		_, err := gitShow(ctx, s.Branch, embeddingPath)
 		s.HasEmbedding = err == nil
 	}
 
-	return &metadata
+	s.loadSignatureStatus(ctx, metadata)
+
+	return metadata
+}
+
+// loadSignatureStatus looks for research/<timestamp>/<topic>/manifest.sig
+// and, if a signing.key is configured, verifies it, populating Signed and
+// SignedBy. A present-but-unverifiable signature (no key configured, or a
+// verifier that can't be constructed) is left unsigned with a SignedBy
+// note rather than a false positive; a present, checkable, invalid
+// signature is reported as "BAD SIGNATURE" rather than silently dropped.
+func (s *snapshotInfo) loadSignatureStatus(ctx context.Context, metadata *models.Metadata) {
+	if metadata.SignFormat == "" {
+		return
+	}
+
+	researchPath := models.ResearchPath(s.Timestamp, s.Topic)
+	sigContent, err := gitShow(ctx, s.Branch, researchPath+"/manifest.sig")
+	if err != nil {
+		return
+	}
+
+	key := config.GetSigningKey()
+	if key == "" {
+		s.SignedBy = "unverified (no signing.key configured)"
+		return
+	}
+
+	verifier, err := signing.NewVerifier(signing.Format(metadata.SignFormat), key)
+	if err != nil {
+		s.SignedBy = "unverified (" + err.Error() + ")"
+		return
+	}
+
+	manifest := signing.Manifest{
+		TreeHash:   metadata.TreeHash,
+		MainCommit: metadata.MainCommit,
+		CreatedAt:  metadata.CreatedAt,
+	}
+	if metadata.Embedding != "" {
+		embeddingPath := models.ResearchPath(s.Timestamp, s.Topic) + "/" + metadata.Embedding
+		embeddingContent, err := gitShow(ctx, s.Branch, embeddingPath)
+		if err != nil {
+			s.SignedBy = "BAD SIGNATURE"
+			return
+		}
+		manifest.Embedding = []byte(embeddingContent)
+	}
+
+	identity, err := signing.VerifyManifest(verifier, manifest, []byte(sigContent))
+	if err != nil {
+		s.SignedBy = "BAD SIGNATURE"
+		return
+	}
+
+	s.Signed = true
+	s.SignedBy = identity
 }
 
 func parseSnapshotBranch(branch string) (snapshotInfo, error) {
@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/alpkeskin/gotoon"
+	"github.com/pders01/git-context/internal/git"
+	"github.com/pders01/git-context/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	logJSON bool
+	logToon bool
+)
+
+var logCmd = &cobra.Command{
+	Use:   "log <timestamp> <topic>",
+	Short: "Show the operation history recorded against a snapshot",
+	Long: `Print a snapshot's operation log: one entry per mutation appended
+under research/<ts>/<topic>/ops/ since it was created (see context tag).
+
+The original meta.json blob is never rewritten, so TreeHash stays
+verifiable; this log, replayed over that blob, is what produces the
+resolved metadata context meta shows by default.
+
+Example:
+  context log 2025-11-14T0930 security-audit`,
+	Args: cobra.ExactArgs(2),
+	RunE: runLog,
+}
+
+func init() {
+	rootCmd.AddCommand(logCmd)
+	logCmd.Flags().BoolVar(&logJSON, "json", false, "Output as JSON")
+	logCmd.Flags().BoolVar(&logToon, "toon", false, "Output as TOON")
+}
+
+func runLog(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if !git.IsGitRepo(ctx) {
+		return fmt.Errorf("not a git repository")
+	}
+
+	info, err := parseSnapshotBranch(fmt.Sprintf("snapshot/%s/%s", args[0], args[1]))
+	if err != nil {
+		return fmt.Errorf("invalid snapshot: %w", err)
+	}
+	if !git.BranchExists(ctx, info.Branch) {
+		return fmt.Errorf("snapshot branch does not exist: %s", info.Branch)
+	}
+
+	ops, err := loadOps(ctx, info.Branch, info)
+	if err != nil {
+		return fmt.Errorf("failed to load operation log: %w", err)
+	}
+
+	if logJSON {
+		output, err := json.MarshalIndent(ops, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(output))
+		return nil
+	}
+
+	if logToon {
+		output, err := gotoon.Encode(ops)
+		if err != nil {
+			return fmt.Errorf("failed to encode Toon: %w", err)
+		}
+		fmt.Println(output)
+		return nil
+	}
+
+	if len(ops) == 0 {
+		fmt.Printf("No operations recorded against %s\n", info.Branch)
+		return nil
+	}
+
+	for _, op := range ops {
+		fmt.Printf("%s  %s\n", op.Timestamp.Format("2006-01-02 15:04:05"), op.Type)
+		switch op.Type {
+		case models.OpAddTag, models.OpRemoveTag:
+			fmt.Printf("  tag:    %s\n", op.Tag)
+		case models.OpSetTags:
+			fmt.Printf("  tags:   %v\n", op.Tags)
+		case models.OpEditNotes:
+			fmt.Printf("  notes:  %s\n", op.Notes)
+		case models.OpSetMode:
+			fmt.Printf("  mode:   %s\n", op.Mode)
+		case models.OpLinkSnapshot:
+			fmt.Printf("  branch: %s\n", op.RelatedBranch)
+		}
+	}
+
+	return nil
+}
+
+// loadOps reads and parses every op file under a snapshot's ops/
+// directory as of ref (a branch name or commit SHA), ordered by filename
+// (and therefore by the sequence number appendOp stamps on them). An
+// absent ops/ directory (no operations recorded yet) is not an error.
+func loadOps(ctx context.Context, ref string, info snapshotInfo) ([]models.Op, error) {
+	opsPath := models.OpsPath(info.Timestamp, info.Topic)
+
+	isTree, err := git.PathIsTreeInBranch(ctx, ref, opsPath)
+	if err != nil || !isTree {
+		return nil, nil
+	}
+
+	names, err := git.ListTreeInBranch(ctx, ref, opsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ops: %w", err)
+	}
+	sort.Strings(names)
+
+	ops := make([]models.Op, 0, len(names))
+	for _, name := range names {
+		content, err := gitShow(ctx, ref, opsPath+"/"+name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read op %s: %w", name, err)
+		}
+		var op models.Op
+		if err := json.Unmarshal([]byte(content), &op); err != nil {
+			return nil, fmt.Errorf("failed to parse op %s: %w", name, err)
+		}
+		ops = append(ops, op)
+	}
+
+	return ops, nil
+}
+
+// appendOp stamps op with the current time and commits it as a new file
+// under the snapshot's ops/ directory, on the snapshot branch itself, in
+// a scratch worktree (mirroring retagSnapshot). It never touches
+// meta.json, so the base blob stays verifiable against TreeHash.
+func appendOp(ctx context.Context, branch string, info snapshotInfo, op models.Op) error {
+	op.Timestamp = time.Now()
+
+	oldSHA, _ := git.RevParseBranch(ctx, branch)
+
+	worktreePath := filepath.Join(os.TempDir(), fmt.Sprintf("context-op-%d", time.Now().UnixNano()))
+	if err := git.CreateWorktree(ctx, worktreePath, branch); err != nil {
+		return err
+	}
+	defer func() {
+		if err := git.RemoveWorktree(ctx, worktreePath); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to remove worktree: %v\n", err)
+		}
+	}()
+
+	opsPath := models.OpsPath(info.Timestamp, info.Topic)
+	worktreeOpsPath := filepath.Join(worktreePath, opsPath)
+	if err := os.MkdirAll(worktreeOpsPath, 0755); err != nil {
+		return fmt.Errorf("failed to create ops directory: %w", err)
+	}
+
+	existing, err := os.ReadDir(worktreeOpsPath)
+	if err != nil {
+		return fmt.Errorf("failed to read ops directory: %w", err)
+	}
+
+	opBytes, err := json.MarshalIndent(&op, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal op: %w", err)
+	}
+
+	opFile := filepath.Join(worktreeOpsPath, fmt.Sprintf("%04d-%s.json", len(existing), op.Type))
+	if err := os.WriteFile(opFile, opBytes, 0644); err != nil {
+		return fmt.Errorf("failed to write op: %w", err)
+	}
+
+	if err := git.AddFilesInDir(ctx, worktreePath, opsPath); err != nil {
+		return err
+	}
+
+	commitMsg := fmt.Sprintf("op: %s on %s", op.Type, branch)
+	if err := git.CommitInDir(ctx, worktreePath, commitMsg); err != nil {
+		return fmt.Errorf("failed to commit op: %w", err)
+	}
+
+	if oldSHA != "" {
+		getMetadataCache().Invalidate(oldSHA)
+		getMetadataCache().Save()
+	}
+
+	return nil
+}
+
+// resolvedMetadata loads a snapshot's base meta.json and replays its
+// operation log over it, returning the effective view context meta and
+// context diff show by default. ref overrides which commit-ish to read
+// the op log from (see --at), defaulting to the snapshot branch itself.
+func resolvedMetadata(ctx context.Context, info snapshotInfo, ref string) (*models.Metadata, error) {
+	if ref == "" {
+		ref = info.Branch
+	}
+
+	metaPath := models.MetadataPath(info.Timestamp, info.Topic)
+	metaContent, err := gitShow(ctx, ref, metaPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata: %w", err)
+	}
+
+	var metadata models.Metadata
+	if err := json.Unmarshal([]byte(metaContent), &metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse metadata: %w", err)
+	}
+
+	ops, err := loadOps(ctx, ref, info)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load operation log: %w", err)
+	}
+
+	resolved := metadata.Resolve(ops)
+	return &resolved, nil
+}
@@ -1,10 +1,10 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"os/exec"
-	"time"
+	"path/filepath"
 
 	"github.com/pders01/git-context/internal/git"
 	"github.com/pders01/git-context/internal/models"
@@ -13,15 +13,22 @@ import (
 
 var (
 	metaJSON bool
+	metaAt   string
 )
 
 var metaCmd = &cobra.Command{
 	Use:   "meta <timestamp> <topic>",
 	Short: "Show metadata for a snapshot",
-	Long: `Display the metadata (meta.json) for a specific snapshot.
+	Long: `Display the metadata for a specific snapshot: the base meta.json with
+any operations from context log (see context tag) replayed over it.
+
+Pass --at <commit> to time-travel: only operations reachable from that
+commit on the snapshot branch are replayed, so you can see what the
+metadata looked like at an earlier point in its history.
 
 Example:
-  context meta 2025-11-14T0930 security-audit`,
+  context meta 2025-11-14T0930 security-audit
+  context meta 2025-11-14T0930 security-audit --at HEAD~2`,
 	Args: cobra.ExactArgs(2),
 	RunE: runMeta,
 }
@@ -29,59 +36,46 @@ Example:
 func init() {
 	rootCmd.AddCommand(metaCmd)
 	metaCmd.Flags().BoolVar(&metaJSON, "json", false, "Output as JSON")
+	metaCmd.Flags().StringVar(&metaAt, "at", "", "Resolve metadata as of this commit instead of the branch tip")
 }
 
 func runMeta(cmd *cobra.Command, args []string) error {
-	if !git.IsGitRepo() {
+	ctx := commandContext(cmd)
+	if !git.IsGitRepo(ctx) {
 		return fmt.Errorf("not a git repository")
 	}
 
-	timestampStr := args[0]
-	topic := args[1]
-
-	// Parse timestamp
-	timestamp, err := time.Parse("2006-01-02T1504", timestampStr)
+	info, err := parseSnapshotBranch(fmt.Sprintf("snapshot/%s/%s", args[0], args[1]))
 	if err != nil {
-		return fmt.Errorf("invalid timestamp format (use YYYY-MM-DDTHHMM): %w", err)
+		return fmt.Errorf("invalid snapshot: %w", err)
 	}
-
-	// Build branch name
-	branch := models.BranchName(timestamp, topic)
+	branch := info.Branch
 
 	// Check if branch exists
-	if !git.BranchExists(branch) {
+	if !git.BranchExists(ctx, branch) {
 		return fmt.Errorf("snapshot branch does not exist: %s", branch)
 	}
 
-	// Get metadata path
-	metaPath := models.MetadataPath(timestamp, topic)
-
-	// Read metadata using git show
-	metaContent, err := gitShow(branch, metaPath)
+	metadataPtr, err := resolvedMetadata(ctx, info, metaAt)
 	if err != nil {
-		return fmt.Errorf("failed to read metadata: %w", err)
-	}
-
-	// Parse metadata
-	var metadata models.Metadata
-	if err := json.Unmarshal([]byte(metaContent), &metadata); err != nil {
-		return fmt.Errorf("failed to parse metadata: %w", err)
+		return err
 	}
+	metadata := *metadataPtr
 
 	// Check if snapshot has embedding
 	hasEmbedding := false
 	if metadata.Embedding != "" {
-		embeddingPath := models.ResearchPath(timestamp, topic) + "/" + metadata.Embedding
-		_, err := gitShow(branch, embeddingPath)
+		embeddingPath := models.ResearchPath(info.Timestamp, info.Topic) + "/" + metadata.Embedding
+		_, err := gitShow(ctx, branch, embeddingPath)
 		hasEmbedding = err == nil
 	}
 
 	// Output JSON if requested
 	if metaJSON {
 		type metaOutput struct {
-			Branch       string           `json:"branch"`
-			Metadata     models.Metadata  `json:"metadata"`
-			HasEmbedding bool             `json:"has_embedding"`
+			Branch       string          `json:"branch"`
+			Metadata     models.Metadata `json:"metadata"`
+			HasEmbedding bool            `json:"has_embedding"`
 		}
 		output := metaOutput{
 			Branch:       branch,
@@ -123,12 +117,23 @@ func runMeta(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-// gitShow reads a file from a specific branch using git show
-func gitShow(branch, path string) (string, error) {
-	cmd := exec.Command("git", "show", fmt.Sprintf("%s:%s", branch, path))
-	output, err := cmd.Output()
-	if err != nil {
-		return "", err
-	}
-	return string(output), nil
+// gitShow reads a file from a specific branch, honoring GITCONTEXT_GIT_BACKEND
+// (see internal/git.Show).
+func gitShow(ctx context.Context, branch, path string) (string, error) {
+	return git.Show(ctx, branch, path)
+}
+
+// casObjectsDir is the root of the content-addressed embedding store (see
+// internal/embeddings.Put/Get/Resolve), local to this clone and never
+// committed to any branch.
+func casObjectsDir() string {
+	return filepath.Join(".git", "context", "objects")
+}
+
+// ollamaCacheDir is the root of the on-disk (text, model) embedding
+// cache ollama.Client consults before calling the API (see
+// ollama.Client.WithCacheDir), local to this clone and never committed to
+// any branch.
+func ollamaCacheDir() string {
+	return filepath.Join(".git", "context", "embeddings")
 }
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pders01/git-context/internal/git"
+	"github.com/spf13/cobra"
+)
+
+var (
+	mountTopic            string
+	mountSince            string
+	mountTags             []string
+	mountAllowOther       bool
+	mountSnapshotTemplate string
+)
+
+// defaultSnapshotTemplate is the time.Format layout used to render a
+// snapshot's directory name under topics/<topic>/ when --snapshot-template
+// is not given.
+const defaultSnapshotTemplate = "2006-01-02T1504"
+
+var mountCmd = &cobra.Command{
+	Use:   "mount <mountpoint>",
+	Short: "Mount snapshots as a read-only filesystem",
+	Long: `Expose all snapshots as a browsable, read-only virtual filesystem.
+
+The mount presents:
+  <mountpoint>/topics/<topic>/<timestamp>/{code,research,metadata.json}
+  <mountpoint>/tags/<tag>/<timestamp>-<topic> -> topics/<topic>/<timestamp>
+  <mountpoint>/latest/<topic> -> topics/<topic>/<newest timestamp>
+
+This lets tools like grep, ripgrep, and editors search across the entire
+snapshot history without checking out branches one at a time. File reads
+stream through a shared git cat-file --batch process rather than checking
+out blobs. Unmount with "context umount <mountpoint>", or Ctrl-C the
+process.
+
+--snapshot-template overrides the Go time.Format layout used for each
+snapshot's directory name (default "2006-01-02T1504"); it applies
+wherever a timestamp is rendered into a path, including tags/ and
+latest/.
+
+Examples:
+  context mount /mnt/context
+  context mount /mnt/context --topic security
+  context mount /mnt/context --tag important --since 2025-10-01
+  context mount /mnt/context --snapshot-template 2006-01-02`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMount,
+}
+
+func init() {
+	rootCmd.AddCommand(mountCmd)
+
+	mountCmd.Flags().StringVar(&mountTopic, "topic", "", "Only mount snapshots for this topic")
+	mountCmd.Flags().StringVar(&mountSince, "since", "", "Only mount snapshots since date (YYYY-MM-DD)")
+	mountCmd.Flags().StringSliceVar(&mountTags, "tag", []string{}, "Only mount snapshots with these tag(s)")
+	mountCmd.Flags().BoolVar(&mountAllowOther, "allow-other", false, "Allow other users to access the mount")
+	mountCmd.Flags().StringVar(&mountSnapshotTemplate, "snapshot-template", defaultSnapshotTemplate, "Go time.Format layout for a snapshot's directory name under topics/<topic>/")
+}
+
+// mountedSnapshots returns the snapshots that should be visible in the
+// mount, after applying --topic, --since, and --tag filters. It mirrors
+// the filter semantics of the list command.
+func mountedSnapshots(ctx context.Context) ([]snapshotInfo, error) {
+	branches, err := git.ListBranches(ctx, "snapshot/*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	var since time.Time
+	if mountSince != "" {
+		since, err = time.Parse("2006-01-02", mountSince)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --since date format (use YYYY-MM-DD): %w", err)
+		}
+	}
+
+	var snapshots []snapshotInfo
+	for _, branch := range branches {
+		info, err := parseSnapshotBranch(branch)
+		if err != nil {
+			continue
+		}
+
+		if mountTopic != "" && info.Topic != mountTopic {
+			continue
+		}
+		if !since.IsZero() && info.Timestamp.Before(since) {
+			continue
+		}
+
+		info.LoadMetadata(ctx)
+
+		if !(SnapshotFilter{Tags: mountTags}).MatchesTags(info.Metadata) {
+			continue
+		}
+
+		snapshots = append(snapshots, info)
+	}
+
+	return snapshots, nil
+}
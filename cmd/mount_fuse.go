@@ -0,0 +1,496 @@
+//go:build fuse
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path"
+	"sort"
+	"strings"
+	"syscall"
+
+	"bazil.org/fuse"
+	fusefs "bazil.org/fuse/fs"
+	"github.com/pders01/git-context/internal/git"
+	"github.com/spf13/cobra"
+)
+
+// runMount mounts all matching snapshots as a read-only FUSE filesystem at
+// the given mountpoint and blocks until it is unmounted or interrupted.
+func runMount(cmd *cobra.Command, args []string) error {
+	ctx := commandContext(cmd)
+	if !git.IsGitRepo(ctx) {
+		return fmt.Errorf("not a git repository")
+	}
+
+	mountpoint := args[0]
+
+	snapshots, err := mountedSnapshots(ctx)
+	if err != nil {
+		return err
+	}
+	if len(snapshots) == 0 {
+		return fmt.Errorf("no snapshots match the given filters")
+	}
+
+	options := []fuse.MountOption{
+		fuse.ReadOnly(),
+		fuse.FSName("git-context"),
+		fuse.Subtype("contextfs"),
+	}
+	if mountAllowOther {
+		options = append(options, fuse.AllowOther())
+	}
+
+	conn, err := fuse.Mount(mountpoint, options...)
+	if err != nil {
+		return fmt.Errorf("failed to mount %s: %w", mountpoint, err)
+	}
+	defer conn.Close()
+
+	batch, err := git.NewBatchCatFile(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start cat-file batch reader: %w", err)
+	}
+	defer batch.Close()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		fmt.Println("\nUnmounting...")
+		fuse.Unmount(mountpoint)
+	}()
+
+	fmt.Printf("Mounted %d snapshot(s) at %s (Ctrl-C to unmount)\n", len(snapshots), mountpoint)
+
+	template := mountSnapshotTemplate
+	if template == "" {
+		template = defaultSnapshotTemplate
+	}
+	if err := fusefs.Serve(conn, &contextFS{snapshots: snapshots, batch: batch, template: template}); err != nil {
+		return fmt.Errorf("fuse serve failed: %w", err)
+	}
+
+	<-conn.Ready
+	if err := conn.MountError; err != nil {
+		return fmt.Errorf("mount error: %w", err)
+	}
+
+	return nil
+}
+
+// contextFS is the root bazil.org/fuse filesystem backing `context mount`.
+type contextFS struct {
+	snapshots []snapshotInfo
+	batch     *git.BatchCatFile
+	// template is the time.Format layout used to render a snapshot's
+	// directory name (see --snapshot-template); defaultSnapshotTemplate
+	// when a contextFS is constructed directly (e.g. in tests).
+	template string
+}
+
+func (cfs *contextFS) snapshotTemplate() string {
+	if cfs.template == "" {
+		return defaultSnapshotTemplate
+	}
+	return cfs.template
+}
+
+func (cfs *contextFS) Root() (fusefs.Node, error) {
+	return &rootDir{fs: cfs}, nil
+}
+
+// rootDir is the mountpoint root, containing "topics", "tags", and "latest".
+type rootDir struct {
+	fs *contextFS
+}
+
+func (d *rootDir) Attr(ctx context.Context, a *fuse.Attr) error {
+	a.Mode = os.ModeDir | 0o555
+	return nil
+}
+
+func (d *rootDir) Lookup(ctx context.Context, name string) (fusefs.Node, error) {
+	switch name {
+	case "topics":
+		return &topicsDir{fs: d.fs}, nil
+	case "tags":
+		return &tagsDir{fs: d.fs}, nil
+	case "latest":
+		return &latestDir{fs: d.fs}, nil
+	}
+	return nil, fuse.ENOENT
+}
+
+func (d *rootDir) ReadDirAll(ctx context.Context) ([]fuse.Dirent, error) {
+	return []fuse.Dirent{
+		{Name: "topics", Type: fuse.DT_Dir},
+		{Name: "tags", Type: fuse.DT_Dir},
+		{Name: "latest", Type: fuse.DT_Dir},
+	}, nil
+}
+
+// topicsDir lists every distinct topic as a subdirectory.
+type topicsDir struct {
+	fs *contextFS
+}
+
+func (d *topicsDir) Attr(ctx context.Context, a *fuse.Attr) error {
+	a.Mode = os.ModeDir | 0o555
+	return nil
+}
+
+func (d *topicsDir) topics() []string {
+	seen := make(map[string]bool)
+	var topics []string
+	for _, s := range d.fs.snapshots {
+		if !seen[s.Topic] {
+			seen[s.Topic] = true
+			topics = append(topics, s.Topic)
+		}
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+func (d *topicsDir) Lookup(ctx context.Context, name string) (fusefs.Node, error) {
+	for _, t := range d.topics() {
+		if t == name {
+			return &topicDir{fs: d.fs, topic: name}, nil
+		}
+	}
+	return nil, fuse.ENOENT
+}
+
+func (d *topicsDir) ReadDirAll(ctx context.Context) ([]fuse.Dirent, error) {
+	var entries []fuse.Dirent
+	for _, t := range d.topics() {
+		entries = append(entries, fuse.Dirent{Name: t, Type: fuse.DT_Dir})
+	}
+	return entries, nil
+}
+
+// topicDir lists every snapshot timestamp under one topic.
+type topicDir struct {
+	fs    *contextFS
+	topic string
+}
+
+func (d *topicDir) Attr(ctx context.Context, a *fuse.Attr) error {
+	a.Mode = os.ModeDir | 0o555
+	return nil
+}
+
+func (d *topicDir) snapshot(timestamp string) *snapshotInfo {
+	template := d.fs.snapshotTemplate()
+	for i, s := range d.fs.snapshots {
+		if s.Topic == d.topic && s.Timestamp.Format(template) == timestamp {
+			return &d.fs.snapshots[i]
+		}
+	}
+	return nil
+}
+
+func (d *topicDir) Lookup(ctx context.Context, name string) (fusefs.Node, error) {
+	if s := d.snapshot(name); s != nil {
+		return &snapshotDir{fs: d.fs, info: *s}, nil
+	}
+	return nil, fuse.ENOENT
+}
+
+func (d *topicDir) ReadDirAll(ctx context.Context) ([]fuse.Dirent, error) {
+	template := d.fs.snapshotTemplate()
+	var entries []fuse.Dirent
+	for _, s := range d.fs.snapshots {
+		if s.Topic == d.topic {
+			entries = append(entries, fuse.Dirent{
+				Name: s.Timestamp.Format(template),
+				Type: fuse.DT_Dir,
+			})
+		}
+	}
+	return entries, nil
+}
+
+// latestDir contains a symlink per topic, pointing at that topic's newest
+// snapshot under topics/, so `latest/<topic>` is always current without the
+// caller having to know the most recent timestamp.
+type latestDir struct {
+	fs *contextFS
+}
+
+func (d *latestDir) Attr(ctx context.Context, a *fuse.Attr) error {
+	a.Mode = os.ModeDir | 0o555
+	return nil
+}
+
+// newest returns, per topic, the snapshotInfo with the latest timestamp.
+func (d *latestDir) newest() map[string]snapshotInfo {
+	newest := make(map[string]snapshotInfo)
+	for _, s := range d.fs.snapshots {
+		if cur, ok := newest[s.Topic]; !ok || s.Timestamp.After(cur.Timestamp) {
+			newest[s.Topic] = s
+		}
+	}
+	return newest
+}
+
+func (d *latestDir) Lookup(ctx context.Context, name string) (fusefs.Node, error) {
+	if s, ok := d.newest()[name]; ok {
+		target := path.Join("..", "topics", s.Topic, s.Timestamp.Format(d.fs.snapshotTemplate()))
+		return &tagSymlink{target: target}, nil
+	}
+	return nil, fuse.ENOENT
+}
+
+func (d *latestDir) ReadDirAll(ctx context.Context) ([]fuse.Dirent, error) {
+	var entries []fuse.Dirent
+	for topic := range d.newest() {
+		entries = append(entries, fuse.Dirent{Name: topic, Type: fuse.DT_Link})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries, nil
+}
+
+// snapshotDir exposes a single snapshot as code/, research/, and metadata.json.
+type snapshotDir struct {
+	fs   *contextFS
+	info snapshotInfo
+}
+
+func (d *snapshotDir) Attr(ctx context.Context, a *fuse.Attr) error {
+	a.Mode = os.ModeDir | 0o555
+	a.Mtime = d.info.Timestamp
+	return nil
+}
+
+func (d *snapshotDir) Lookup(ctx context.Context, name string) (fusefs.Node, error) {
+	switch name {
+	case "code":
+		return &treeDir{branch: d.info.Branch, prefix: "", batch: d.fs.batch}, nil
+	case "research":
+		return &treeDir{branch: d.info.Branch, prefix: "research", batch: d.fs.batch}, nil
+	case "metadata.json":
+		return &gitFile{branch: d.info.Branch, path: metadataRelPath(d.info), batch: d.fs.batch}, nil
+	}
+	return nil, fuse.ENOENT
+}
+
+func (d *snapshotDir) ReadDirAll(ctx context.Context) ([]fuse.Dirent, error) {
+	return []fuse.Dirent{
+		{Name: "code", Type: fuse.DT_Dir},
+		{Name: "research", Type: fuse.DT_Dir},
+		{Name: "metadata.json", Type: fuse.DT_File},
+	}, nil
+}
+
+// metadataRelPath returns meta.json's real path on info's snapshot branch
+// (research/<ts>/<topic>/meta.json, always keyed by the actual timestamp).
+// This is the on-disk git layout, independent of --snapshot-template, which
+// only affects how directory names are rendered in the mount.
+func metadataRelPath(info snapshotInfo) string {
+	return strings.TrimPrefix(fmt.Sprintf("research/%s/%s/meta.json",
+		info.Timestamp.Format("2006-01-02T1504"), info.Topic), "/")
+}
+
+// treeDir lazily lists a subtree of a snapshot commit via `git ls-tree`.
+type treeDir struct {
+	branch string
+	prefix string
+	batch  *git.BatchCatFile
+}
+
+func (d *treeDir) Attr(ctx context.Context, a *fuse.Attr) error {
+	a.Mode = os.ModeDir | 0o555
+	return nil
+}
+
+func (d *treeDir) Lookup(ctx context.Context, name string) (fusefs.Node, error) {
+	childPrefix := path.Join(d.prefix, name)
+
+	isDir, err := git.PathIsTreeInBranch(ctx, d.branch, childPrefix)
+	if err != nil {
+		return nil, fuse.ENOENT
+	}
+	if isDir {
+		return &treeDir{branch: d.branch, prefix: childPrefix, batch: d.batch}, nil
+	}
+	return &gitFile{branch: d.branch, path: childPrefix, batch: d.batch}, nil
+}
+
+func (d *treeDir) ReadDirAll(ctx context.Context) ([]fuse.Dirent, error) {
+	names, err := git.ListTreeInBranch(ctx, d.branch, d.prefix)
+	if err != nil {
+		return nil, fuse.EIO
+	}
+
+	var entries []fuse.Dirent
+	for _, n := range names {
+		isDir, err := git.PathIsTreeInBranch(ctx, d.branch, path.Join(d.prefix, n))
+		if err != nil {
+			continue
+		}
+		dt := fuse.DT_File
+		if isDir {
+			dt = fuse.DT_Dir
+		}
+		entries = append(entries, fuse.Dirent{Name: n, Type: dt})
+	}
+	return entries, nil
+}
+
+// gitFile is a single read-only file backed by a snapshot branch's tree.
+// Reads stream through a shared `git cat-file --batch` process (f.batch)
+// rather than spawning `git show` per read; batch is nil only when a
+// gitFile is constructed directly (e.g. in tests), in which case it falls
+// back to gitShow.
+type gitFile struct {
+	branch string
+	path   string
+	batch  *git.BatchCatFile
+}
+
+func (f *gitFile) content(ctx context.Context) ([]byte, error) {
+	if f.batch != nil {
+		return f.batch.Read(f.branch + ":" + f.path)
+	}
+	content, err := gitShow(ctx, f.branch, f.path)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(content), nil
+}
+
+func (f *gitFile) Attr(ctx context.Context, a *fuse.Attr) error {
+	content, err := f.content(ctx)
+	if err != nil {
+		return fuse.ENOENT
+	}
+	a.Mode = 0o444
+	a.Size = uint64(len(content))
+	return nil
+}
+
+func (f *gitFile) ReadAll(ctx context.Context) ([]byte, error) {
+	content, err := f.content(ctx)
+	if err != nil {
+		return nil, fuse.ENOENT
+	}
+	return content, nil
+}
+
+// tagsDir lists every distinct tag across the mounted snapshots.
+type tagsDir struct {
+	fs *contextFS
+}
+
+func (d *tagsDir) Attr(ctx context.Context, a *fuse.Attr) error {
+	a.Mode = os.ModeDir | 0o555
+	return nil
+}
+
+func (d *tagsDir) tags(ctx context.Context) []string {
+	seen := make(map[string]bool)
+	var tags []string
+	for _, s := range d.fs.snapshots {
+		s.LoadMetadata(ctx)
+		if s.Metadata == nil {
+			continue
+		}
+		for _, t := range s.Metadata.Tags {
+			if !seen[t] {
+				seen[t] = true
+				tags = append(tags, t)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+func (d *tagsDir) Lookup(ctx context.Context, name string) (fusefs.Node, error) {
+	for _, t := range d.tags(ctx) {
+		if t == name {
+			return &tagDir{fs: d.fs, tag: name}, nil
+		}
+	}
+	return nil, fuse.ENOENT
+}
+
+func (d *tagsDir) ReadDirAll(ctx context.Context) ([]fuse.Dirent, error) {
+	var entries []fuse.Dirent
+	for _, t := range d.tags(ctx) {
+		entries = append(entries, fuse.Dirent{Name: t, Type: fuse.DT_Dir})
+	}
+	return entries, nil
+}
+
+// tagDir contains a symlink per snapshot carrying this tag, pointing back
+// into the topics/ tree so the canonical snapshot layout lives in one place.
+type tagDir struct {
+	fs  *contextFS
+	tag string
+}
+
+func (d *tagDir) Attr(ctx context.Context, a *fuse.Attr) error {
+	a.Mode = os.ModeDir | 0o555
+	return nil
+}
+
+func (d *tagDir) matches(ctx context.Context) []snapshotInfo {
+	var matches []snapshotInfo
+	for _, s := range d.fs.snapshots {
+		s.LoadMetadata(ctx)
+		if s.Metadata == nil {
+			continue
+		}
+		for _, t := range s.Metadata.Tags {
+			if t == d.tag {
+				matches = append(matches, s)
+				break
+			}
+		}
+	}
+	return matches
+}
+
+func (d *tagDir) entryName(s snapshotInfo) string {
+	return fmt.Sprintf("%s-%s", s.Timestamp.Format(d.fs.snapshotTemplate()), s.Topic)
+}
+
+func (d *tagDir) Lookup(ctx context.Context, name string) (fusefs.Node, error) {
+	for _, s := range d.matches(ctx) {
+		if d.entryName(s) == name {
+			target := path.Join("..", "..", "topics", s.Topic, s.Timestamp.Format(d.fs.snapshotTemplate()))
+			return &tagSymlink{target: target}, nil
+		}
+	}
+	return nil, fuse.ENOENT
+}
+
+func (d *tagDir) ReadDirAll(ctx context.Context) ([]fuse.Dirent, error) {
+	var entries []fuse.Dirent
+	for _, s := range d.matches(ctx) {
+		entries = append(entries, fuse.Dirent{Name: d.entryName(s), Type: fuse.DT_Link})
+	}
+	return entries, nil
+}
+
+// tagSymlink is a symbolic link to a location under topics/<topic>/<timestamp>,
+// used by both tags/<tag>/<entry> and latest/<topic>.
+type tagSymlink struct {
+	target string
+}
+
+func (l *tagSymlink) Attr(ctx context.Context, a *fuse.Attr) error {
+	a.Mode = os.ModeSymlink | 0o444
+	return nil
+}
+
+func (l *tagSymlink) Readlink(ctx context.Context, req *fuse.ReadlinkRequest) (string, error) {
+	return l.target, nil
+}
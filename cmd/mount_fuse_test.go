@@ -0,0 +1,93 @@
+//go:build fuse
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"bazil.org/fuse"
+	"github.com/pders01/git-context/internal/testutil"
+)
+
+// TestMountFuseIntegration actually mounts a contextFS and exercises it
+// through the kernel FUSE path. It requires a working FUSE installation
+// (fusermount on Linux), so it only runs when TEST_FUSE=1 is set.
+func TestMountFuseIntegration(t *testing.T) {
+	if os.Getenv("TEST_FUSE") != "1" {
+		t.Skip("set TEST_FUSE=1 to run FUSE integration tests")
+	}
+
+	repo := testutil.NewTempGitRepo(t)
+	defer repo.Cleanup()
+
+	oldWd, _ := os.Getwd()
+	os.Chdir(repo.Path)
+	defer os.Chdir(oldWd)
+
+	createTestSnapshot(t, "mount-check", "full", []string{"important"})
+
+	mountpoint := t.TempDir()
+
+	mountTopic, mountSince, mountTags, mountAllowOther = "", "", nil, false
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- runMount(nil, []string{mountpoint})
+	}()
+
+	waitForMount(t, mountpoint)
+	defer func() {
+		fuse.Unmount(mountpoint)
+		if err := <-errCh; err != nil {
+			t.Errorf("runMount returned an error: %v", err)
+		}
+	}()
+
+	entries, err := os.ReadDir(mountpoint)
+	if err != nil {
+		t.Fatalf("failed to read mountpoint: %v", err)
+	}
+	names := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		names[e.Name()] = true
+	}
+	for _, want := range []string{"topics", "tags", "latest"} {
+		if !names[want] {
+			t.Errorf("expected %q at mount root, got %v", want, names)
+		}
+	}
+
+	target, err := os.Readlink(filepath.Join(mountpoint, "latest", "mount-check"))
+	if err != nil {
+		t.Fatalf("failed to read latest/mount-check symlink: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(mountpoint, "latest", target)); err != nil {
+		t.Errorf("latest/mount-check points at a nonexistent snapshot: %v", err)
+	}
+
+	metadataPath := filepath.Join(mountpoint, "latest", target, "metadata.json")
+	content, err := os.ReadFile(metadataPath)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", metadataPath, err)
+	}
+	if len(content) == 0 {
+		t.Error("expected non-empty metadata.json")
+	}
+}
+
+// waitForMount polls until the mountpoint is readable or t fails after a
+// short timeout.
+func waitForMount(t *testing.T, mountpoint string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := os.ReadDir(mountpoint); err == nil {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("mount at %s did not become ready in time", mountpoint)
+}
@@ -0,0 +1,16 @@
+//go:build !fuse
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// runMount is a stub used when this binary is built without the "fuse"
+// build tag (the default, since FUSE pulls in cgo/OS-specific bindings via
+// bazil.org/fuse). Build with `-tags fuse` to get the real mount command.
+func runMount(cmd *cobra.Command, args []string) error {
+	return fmt.Errorf("mount support was not compiled into this binary (rebuild with -tags fuse)")
+}
@@ -37,7 +37,8 @@ func init() {
 }
 
 func runOpen(cmd *cobra.Command, args []string) error {
-	if !git.IsGitRepo() {
+	ctx := commandContext(cmd)
+	if !git.IsGitRepo(ctx) {
 		return fmt.Errorf("not a git repository")
 	}
 
@@ -54,7 +55,7 @@ func runOpen(cmd *cobra.Command, args []string) error {
 	branch := models.BranchName(timestamp, topic)
 
 	// Check if branch exists
-	if !git.BranchExists(branch) {
+	if !git.BranchExists(ctx, branch) {
 		return fmt.Errorf("snapshot branch does not exist: %s", branch)
 	}
 
@@ -69,7 +70,7 @@ func runOpen(cmd *cobra.Command, args []string) error {
 	fmt.Printf("Path: %s\n", worktreePath)
 
 	// Create worktree
-	if err := git.CreateWorktree(worktreePath, branch); err != nil {
+	if err := git.CreateWorktree(ctx, worktreePath, branch); err != nil {
 		return err
 	}
 
@@ -1,19 +1,30 @@
 package cmd
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
-	"github.com/paulderscheid/git-context/internal/config"
-	"github.com/paulderscheid/git-context/internal/git"
-	"github.com/paulderscheid/git-context/internal/models"
+	"github.com/pders01/git-context/internal/config"
+	"github.com/pders01/git-context/internal/git"
+	"github.com/pders01/git-context/internal/models"
 	"github.com/spf13/cobra"
 )
 
 var (
 	pruneDryRun bool
 	pruneForce  bool
+
+	pruneKeepLast    int
+	pruneKeepHourly  int
+	pruneKeepDaily   int
+	pruneKeepWeekly  int
+	pruneKeepMonthly int
+	pruneKeepYearly  int
+	pruneKeepWithin  string
+	pruneKeepTag     []string
 )
 
 var pruneCmd = &cobra.Command{
@@ -21,16 +32,31 @@ var pruneCmd = &cobra.Command{
 	Short: "Remove old snapshots based on retention policy",
 	Long: `Remove snapshots older than the retention period.
 
-The retention policy is configured in ~/.config/context/config.toml:
+With no retention flags, the plain day-cutoff policy in
+~/.config/context/config.toml applies:
   [retention]
   days = 90
   preserve_tags = ["important", "security"]
 
 Snapshots with preserve tags will never be pruned.
 
+Passing any of --keep-last, --keep-hourly, --keep-daily, --keep-weekly,
+--keep-monthly, --keep-yearly, --keep-tag, or --keep-within switches to
+restic-style declarative retention instead: branches are sorted
+newest-first, and a snapshot is kept if it's among the N newest
+(--keep-last), the first one seen in an active hourly/daily/weekly/
+monthly/yearly bucket whose counter isn't exhausted, carries a tag
+listed in --keep-tag, or falls within --keep-within of now. Everything
+else is deleted (or listed, under --dry-run). The reason a snapshot
+survived is printed alongside it, e.g. "kept: keep-daily+tag=important".
+See also: context forget, which applies the same bucket logic grouped by
+topic rather than across all snapshots.
+
 Example:
-  context prune              # Show what would be pruned
-  context prune --force      # Actually prune snapshots`,
+  context prune                                  # Show what would be pruned
+  context prune --force                          # Actually prune snapshots
+  context prune --keep-last 5 --keep-daily 7
+  context prune --keep-hourly 24 --keep-weekly 4 --keep-tag important --force`,
 	RunE: runPrune,
 }
 
@@ -39,13 +65,53 @@ func init() {
 
 	pruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", true, "Show what would be pruned without deleting")
 	pruneCmd.Flags().BoolVar(&pruneForce, "force", false, "Actually delete branches (overrides dry-run)")
+
+	pruneCmd.Flags().IntVar(&pruneKeepLast, "keep-last", 0, "Keep the N most recent snapshots regardless of bucket")
+	pruneCmd.Flags().IntVar(&pruneKeepHourly, "keep-hourly", 0, "Keep one snapshot per hour for the last N hours with a snapshot")
+	pruneCmd.Flags().IntVar(&pruneKeepDaily, "keep-daily", 0, "Keep one snapshot per day for the last N days with a snapshot")
+	pruneCmd.Flags().IntVar(&pruneKeepWeekly, "keep-weekly", 0, "Keep one snapshot per ISO week for the last N weeks with a snapshot")
+	pruneCmd.Flags().IntVar(&pruneKeepMonthly, "keep-monthly", 0, "Keep one snapshot per month for the last N months with a snapshot")
+	pruneCmd.Flags().IntVar(&pruneKeepYearly, "keep-yearly", 0, "Keep one snapshot per year for the last N years with a snapshot")
+	pruneCmd.Flags().StringVar(&pruneKeepWithin, "keep-within", "", "Keep everything newer than this duration (e.g. 7d, 2w, 6m, 1y)")
+	pruneCmd.Flags().StringSliceVar(&pruneKeepTag, "keep-tag", []string{}, "Always keep snapshots with this tag (repeatable)")
 }
 
 func runPrune(cmd *cobra.Command, args []string) error {
-	if !git.IsGitRepo() {
+	ctx := commandContext(cmd)
+	if !git.IsGitRepo(ctx) {
 		return fmt.Errorf("not a git repository")
 	}
 
+	// --force overrides the default dry-run, as documented in its flag help
+	// and the Long description, mirroring the identical fix in context
+	// forget (see runForget). cmd is nil in unit tests that call runPrune
+	// directly, so guard the Changed check.
+	dryRunExplicit := cmd != nil && cmd.Flags().Changed("dry-run")
+	if pruneForce && !dryRunExplicit {
+		pruneDryRun = false
+	}
+
+	keepLast := firstNonZero(pruneKeepLast, config.GetKeepLast())
+	keepHourly := firstNonZero(pruneKeepHourly, config.GetKeepHourly())
+	keepDaily := firstNonZero(pruneKeepDaily, config.GetKeepDaily())
+	keepWeekly := firstNonZero(pruneKeepWeekly, config.GetKeepWeekly())
+	keepMonthly := firstNonZero(pruneKeepMonthly, config.GetKeepMonthly())
+	keepYearly := firstNonZero(pruneKeepYearly, config.GetKeepYearly())
+
+	keepWithinStr := pruneKeepWithin
+	if keepWithinStr == "" {
+		keepWithinStr = config.GetKeepWithin()
+	}
+
+	keepTags := pruneKeepTag
+	if len(keepTags) == 0 {
+		keepTags = config.GetKeepTags()
+	}
+
+	if keepLast > 0 || keepHourly > 0 || keepDaily > 0 || keepWeekly > 0 || keepMonthly > 0 || keepYearly > 0 || keepWithinStr != "" || len(keepTags) > 0 {
+		return runPruneWithPolicy(ctx, keepLast, keepHourly, keepDaily, keepWeekly, keepMonthly, keepYearly, keepWithinStr, keepTags)
+	}
+
 	// Get retention settings
 	retentionDays := config.GetRetentionDays()
 	preserveTags := config.GetPreserveTags()
@@ -57,7 +123,7 @@ func runPrune(cmd *cobra.Command, args []string) error {
 	fmt.Printf("Cutoff date: %s\n\n", cutoffDate.Format("2006-01-02"))
 
 	// Get all snapshot branches
-	branches, err := git.ListBranches("snapshot/*")
+	branches, err := git.ListBranches(ctx, "snapshot/*")
 	if err != nil {
 		return fmt.Errorf("failed to list branches: %w", err)
 	}
@@ -72,20 +138,21 @@ func runPrune(cmd *cobra.Command, args []string) error {
 	var toPreserve []pruneCandidate
 
 	for _, branch := range branches {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		info, err := parseSnapshotBranch(branch)
 		if err != nil {
 			continue
 		}
 
-		// Read metadata to check tags
-		metaPath := models.MetadataPath(info.Timestamp, info.Topic)
-		metaContent, err := gitShow(branch, metaPath)
-		var metadata *models.Metadata
-		if err == nil {
-			var m models.Metadata
-			if json.Unmarshal([]byte(metaContent), &m) == nil {
-				metadata = &m
-			}
+		// Read metadata to check tags, resolved against the operation log
+		// (see context log / context tag) so a tag added after the
+		// snapshot was created is still honored by preservation checks.
+		metadata, err := resolvedMetadata(ctx, info, "")
+		if err != nil {
+			metadata = nil
 		}
 
 		candidate := pruneCandidate{
@@ -143,23 +210,159 @@ func runPrune(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Perform deletion if --force is specified
+	deletePrunedBranches(ctx, toPrune)
+	return nil
+}
+
+// runPruneWithPolicy implements the restic-style retention described in
+// the prune command's --keep-* flags: every snapshot branch (not grouped
+// by topic, unlike context forget) is sorted newest-first and walked
+// once, keeping the first snapshot seen in each active bucket until its
+// counter is exhausted. It shares its bucket definitions and duration
+// parsing with context forget (see forgetPolicy, parseRetentionDuration).
+func runPruneWithPolicy(ctx context.Context, keepLast, keepHourly, keepDaily, keepWeekly, keepMonthly, keepYearly int, keepWithinStr string, keepTags []string) error {
+	var keepWithin time.Duration
+	if keepWithinStr != "" {
+		d, err := parseRetentionDuration(keepWithinStr)
+		if err != nil {
+			return fmt.Errorf("invalid --keep-within duration: %w", err)
+		}
+		keepWithin = d
+	}
+	preserveTags := config.GetPreserveTags()
+
+	branches, err := git.ListBranches(ctx, "snapshot/*")
+	if err != nil {
+		return fmt.Errorf("failed to list branches: %w", err)
+	}
+	if len(branches) == 0 {
+		fmt.Println("No snapshots found")
+		return nil
+	}
+
+	var snapshots []snapshotInfo
+	for _, branch := range branches {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		info, err := parseSnapshotBranch(branch)
+		if err != nil {
+			continue
+		}
+		info.LoadMetadata(ctx)
+		snapshots = append(snapshots, info)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Timestamp.After(snapshots[j].Timestamp)
+	})
+
+	policies := []forgetPolicy{
+		{"keep-hourly", keepHourly, func(s snapshotInfo) string { return s.Timestamp.Format("2006-01-02T15") }},
+		{"keep-daily", keepDaily, func(s snapshotInfo) string { return s.Timestamp.Format("2006-01-02") }},
+		{"keep-weekly", keepWeekly, func(s snapshotInfo) string { return isoWeekKey(s.Timestamp) }},
+		{"keep-monthly", keepMonthly, func(s snapshotInfo) string { return s.Timestamp.Format("2006-01") }},
+		{"keep-yearly", keepYearly, func(s snapshotInfo) string { return s.Timestamp.Format("2006") }},
+	}
+	used := make(map[string]map[string]bool, len(policies))
+	for _, p := range policies {
+		used[p.name] = make(map[string]bool)
+	}
+
+	var toPrune []pruneCandidate
+
+	fmt.Println("Retention policy:")
+	for i, s := range snapshots {
+		var reasons []string
+
+		if keepLast > 0 && i < keepLast {
+			reasons = append(reasons, "keep-last")
+		}
+
+		if keepWithin > 0 && time.Since(s.Timestamp) <= keepWithin {
+			reasons = append(reasons, "keep-within")
+		}
+
+		for _, p := range policies {
+			if p.keep <= 0 {
+				continue
+			}
+			key := p.bucket(s)
+			if used[p.name][key] {
+				continue
+			}
+			if len(used[p.name]) >= p.keep {
+				continue
+			}
+			used[p.name][key] = true
+			reasons = append(reasons, p.name)
+		}
+
+		if s.Metadata != nil {
+			for _, tag := range keepTags {
+				if tagsIntersect(s.Metadata.Tags, []string{tag}) {
+					reasons = append(reasons, "tag="+tag)
+				}
+			}
+			if config.ShouldPreserve(s.Metadata.Tags) || tagsIntersect(s.Metadata.Tags, preserveTags) {
+				reasons = append(reasons, "preserve-tag")
+			}
+		}
+
+		if len(reasons) > 0 {
+			fmt.Printf("  kept:   %s  (%s)\n", s.Branch, strings.Join(reasons, "+"))
+			continue
+		}
+
+		fmt.Printf("  delete: %s\n", s.Branch)
+		toPrune = append(toPrune, pruneCandidate{
+			Branch:   s.Branch,
+			Info:     s,
+			Metadata: s.Metadata,
+			Age:      time.Since(s.Timestamp),
+			Reason:   "no matching retention policy",
+		})
+	}
+	fmt.Println()
+
+	if len(toPrune) == 0 {
+		fmt.Println("No snapshots to prune")
+		return nil
+	}
+
+	deletePrunedBranches(ctx, toPrune)
+	return nil
+}
+
+// deletePrunedBranches deletes each candidate's branch when --force is
+// set (and --dry-run isn't), printing either the deletion progress or a
+// reminder that this was a dry run.
+func deletePrunedBranches(ctx context.Context, toPrune []pruneCandidate) {
 	if pruneForce && !pruneDryRun {
 		fmt.Println("Pruning snapshots...")
+		deleted := 0
 		for _, c := range toPrune {
+			if err := ctx.Err(); err != nil {
+				fmt.Printf("\nCancelled: %v\n", err)
+				break
+			}
 			fmt.Printf("  Deleting %s...\n", c.Branch)
-			if err := git.DeleteBranch(c.Branch, true); err != nil {
+			sha, _ := git.RevParseBranch(ctx, c.Branch)
+			if err := git.DeleteBranch(ctx, c.Branch, true); err != nil {
 				fmt.Printf("    Error: %v\n", err)
-			} else {
-				fmt.Printf("    ✓ Deleted\n")
+				continue
+			}
+			if sha != "" {
+				getMetadataCache().Invalidate(sha)
 			}
+			fmt.Printf("    ✓ Deleted\n")
+			deleted++
 		}
-		fmt.Printf("\n✓ Pruned %d snapshot(s)\n", len(toPrune))
+		getMetadataCache().Save()
+		fmt.Printf("\n✓ Pruned %d of %d snapshot(s)\n", deleted, len(toPrune))
 	} else {
 		fmt.Println("\nThis is a dry run. Use --force to actually prune snapshots.")
 	}
-
-	return nil
 }
 
 type pruneCandidate struct {
@@ -44,7 +44,8 @@ type relatedSnapshot struct {
 }
 
 func runRelated(cmd *cobra.Command, args []string) error {
-	if !git.IsGitRepo() {
+	ctx := cmd.Context()
+	if !git.IsGitRepo(ctx) {
 		return fmt.Errorf("not a git repository")
 	}
 
@@ -53,7 +54,7 @@ func runRelated(cmd *cobra.Command, args []string) error {
 
 	// Parse the target snapshot
 	targetBranch := fmt.Sprintf("snapshot/%s/%s", timestampStr, topic)
-	if !git.BranchExists(targetBranch) {
+	if !git.BranchExists(ctx, targetBranch) {
 		return fmt.Errorf("snapshot branch does not exist: %s", targetBranch)
 	}
 
@@ -62,14 +63,14 @@ func runRelated(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to parse snapshot: %w", err)
 	}
-	targetInfo.LoadMetadata()
+	targetInfo.LoadMetadata(ctx)
 
 	if targetInfo.Metadata == nil {
 		return fmt.Errorf("snapshot has no metadata")
 	}
 
 	// Get all snapshots
-	branches, err := git.ListBranches("snapshot/*")
+	branches, err := git.ListBranches(ctx, "snapshot/*")
 	if err != nil {
 		return fmt.Errorf("failed to list branches: %w", err)
 	}
@@ -86,7 +87,7 @@ func runRelated(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			continue
 		}
-		info.LoadMetadata()
+		info.LoadMetadata(ctx)
 
 		if info.Metadata == nil {
 			continue
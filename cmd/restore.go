@@ -0,0 +1,406 @@
+package cmd
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pders01/git-context/internal/archivestore"
+	"github.com/pders01/git-context/internal/git"
+	"github.com/spf13/cobra"
+)
+
+var (
+	restoreTarget       string
+	restoreCodeOnly     bool
+	restoreResearchOnly bool
+	restoreInclude      []string
+	restoreExclude      []string
+	restoreOverwrite    bool
+	restoreVerify       bool
+)
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <timestamp> <topic> --target DIR | restore <archive-url>",
+	Short: "Extract a snapshot's tree into a directory, or recreate snapshots from an archive",
+	Long: `Materialize a snapshot branch's files into an arbitrary directory
+without touching the working tree or checking out the branch.
+
+By default everything in the snapshot is extracted, including research/
+metadata. --code-only and --research-only mirror the save command's
+distinction between code and research/ artifacts. --include and --exclude
+further narrow the file set by glob (matched against the full path and
+the basename, same as "context find --path").
+
+--verify re-hashes every extracted file with git hash-object and compares
+it against the snapshot's tree entry, so you can confirm the extraction
+(or a subsequent edit) didn't silently diverge from the immutable snapshot.
+By default restore refuses to overwrite existing files; pass --overwrite
+to allow it.
+
+Given a single archive URL instead of a timestamp and topic, restore
+recreates the snapshot branches an archive created with "context archive"
+contains, using the archive's archive-manifest.json to map tar entries
+back to exact branch names. The URL accepts the same schemes as archive's
+--output: a local path, file://, s3://bucket/prefix, gcs://bucket/prefix,
+or ssh://host/path, with the same environment-variable credentials.
+Branches that already exist locally are left untouched.
+
+Examples:
+  context restore 2025-11-14T0930 security-audit --target /tmp/review
+  context restore 2025-11-14T0930 security-audit --target ./out --code-only
+  context restore 2025-11-14T0930 security-audit --target ./out --verify
+  context restore ./context-snapshots-all.tar.gz
+  context restore s3://my-bucket/backups/snapshots.tar.gz`,
+	Args: restoreArgs,
+	RunE: runRestore,
+}
+
+// restoreArgs accepts either one positional arg (an archive URL) or two
+// (a snapshot timestamp and topic, the original form).
+func restoreArgs(cmd *cobra.Command, args []string) error {
+	if len(args) == 1 || len(args) == 2 {
+		return nil
+	}
+	return fmt.Errorf("accepts 1 arg (archive URL) or 2 args (timestamp, topic), received %d", len(args))
+}
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+
+	restoreCmd.Flags().StringVar(&restoreTarget, "target", "", "Directory to extract the snapshot into (required)")
+	restoreCmd.Flags().BoolVar(&restoreCodeOnly, "code-only", false, "Only extract files outside research/")
+	restoreCmd.Flags().BoolVar(&restoreResearchOnly, "research-only", false, "Only extract files under research/")
+	restoreCmd.Flags().StringSliceVar(&restoreInclude, "include", []string{}, "Only extract files matching this glob (repeatable)")
+	restoreCmd.Flags().StringSliceVar(&restoreExclude, "exclude", []string{}, "Skip files matching this glob (repeatable)")
+	restoreCmd.Flags().BoolVar(&restoreOverwrite, "overwrite", false, "Allow overwriting existing files in the target directory")
+	restoreCmd.Flags().BoolVar(&restoreVerify, "verify", false, "Hash every extracted file and compare against the snapshot's tree")
+}
+
+func runRestore(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if !git.IsGitRepo(ctx) {
+		return fmt.Errorf("not a git repository")
+	}
+
+	if len(args) == 1 {
+		return runRestoreArchive(ctx, args[0])
+	}
+
+	if restoreTarget == "" {
+		return fmt.Errorf("required flag(s) \"target\" not set")
+	}
+
+	if restoreCodeOnly && restoreResearchOnly {
+		return fmt.Errorf("--code-only and --research-only are mutually exclusive")
+	}
+
+	timestampStr, topic := args[0], args[1]
+	info, err := parseSnapshotBranch(fmt.Sprintf("snapshot/%s/%s", timestampStr, topic))
+	if err != nil {
+		return fmt.Errorf("invalid snapshot: %w", err)
+	}
+	if !git.BranchExists(ctx, info.Branch) {
+		return fmt.Errorf("snapshot branch does not exist: %s", info.Branch)
+	}
+
+	paths, err := git.ListTreeRecursive(ctx, info.Branch)
+	if err != nil {
+		return fmt.Errorf("failed to list snapshot tree: %w", err)
+	}
+
+	paths, err = filterRestorePaths(paths)
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		fmt.Println("No files match the given filters")
+		return nil
+	}
+
+	if err := os.MkdirAll(restoreTarget, 0755); err != nil {
+		return fmt.Errorf("failed to create target directory: %w", err)
+	}
+
+	var mismatches []string
+
+	for _, p := range paths {
+		destPath := filepath.Join(restoreTarget, p)
+		if !restoreOverwrite {
+			if _, err := os.Stat(destPath); err == nil {
+				return fmt.Errorf("refusing to overwrite existing file %s (pass --overwrite)", destPath)
+			}
+		}
+
+		content, err := gitShow(ctx, info.Branch, p)
+		if err != nil {
+			return fmt.Errorf("failed to read %s from %s: %w", p, info.Branch, err)
+		}
+		if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", destPath, err)
+		}
+		if err := os.WriteFile(destPath, []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", destPath, err)
+		}
+
+		if restoreVerify {
+			ok, err := verifyRestoredFile(ctx, info.Branch, p, destPath)
+			if err != nil {
+				return fmt.Errorf("failed to verify %s: %w", p, err)
+			}
+			if !ok {
+				mismatches = append(mismatches, p)
+			}
+		}
+	}
+
+	fmt.Printf("✓ Restored %d file(s) from %s to %s\n", len(paths), info.Branch, restoreTarget)
+
+	if restoreVerify {
+		if len(mismatches) > 0 {
+			for _, m := range mismatches {
+				fmt.Printf("  MISMATCH: %s\n", m)
+			}
+			return fmt.Errorf("%d file(s) failed verification against the snapshot tree", len(mismatches))
+		}
+		fmt.Println("✓ All extracted files match the snapshot tree")
+	}
+
+	return nil
+}
+
+// filterRestorePaths narrows paths to --code-only/--research-only and
+// --include/--exclude, in that order.
+func filterRestorePaths(paths []string) ([]string, error) {
+	var result []string
+	for _, p := range paths {
+		if restoreCodeOnly && isUnderResearch(p) {
+			continue
+		}
+		if restoreResearchOnly && !isUnderResearch(p) {
+			continue
+		}
+
+		if len(restoreInclude) > 0 && !matchesAnyGlob(p, restoreInclude) {
+			continue
+		}
+		if len(restoreExclude) > 0 && matchesAnyGlob(p, restoreExclude) {
+			continue
+		}
+
+		result = append(result, p)
+	}
+	return result, nil
+}
+
+func isUnderResearch(p string) bool {
+	return strings.HasPrefix(p, "research/")
+}
+
+// matchesAnyGlob reports whether p (or its basename) matches any of globs.
+func matchesAnyGlob(p string, globs []string) bool {
+	for _, g := range globs {
+		if ok, _ := path.Match(g, p); ok {
+			return true
+		}
+		if ok, _ := path.Match(g, path.Base(p)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyRestoredFile reports whether the extracted file at destPath
+// hashes to the same blob SHA as branch's tree entry for p.
+func verifyRestoredFile(ctx context.Context, branch, p, destPath string) (bool, error) {
+	treeSHA, err := git.BlobSHA(ctx, branch, p)
+	if err != nil {
+		return false, err
+	}
+	localSHA, err := git.HashObject(ctx, destPath)
+	if err != nil {
+		return false, err
+	}
+	return treeSHA == localSHA, nil
+}
+
+// runRestoreArchive recreates the snapshot branches contained in the
+// archive at rawURL, using its archive-manifest.json to map tar entries
+// back to exact branch names.
+func runRestoreArchive(ctx context.Context, rawURL string) error {
+	backend, name, err := archivestore.Open(rawURL)
+	if err != nil {
+		return fmt.Errorf("failed to open archive source: %w", err)
+	}
+	defer backend.Close()
+
+	r, err := backend.Get(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to read archive: %w", err)
+	}
+	defer r.Close()
+
+	gzReader, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("failed to decompress archive: %w", err)
+	}
+	defer gzReader.Close()
+
+	tmpDir, err := os.MkdirTemp("", "context-restore-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	var branchPrefixes map[string]string
+
+	tarReader := tar.NewReader(gzReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive entry: %w", err)
+		}
+
+		if header.Name == archiveManifestName {
+			data, err := io.ReadAll(tarReader)
+			if err != nil {
+				return fmt.Errorf("failed to read archive manifest: %w", err)
+			}
+			if err := json.Unmarshal(data, &branchPrefixes); err != nil {
+				return fmt.Errorf("failed to parse archive manifest: %w", err)
+			}
+			continue
+		}
+
+		destPath := filepath.Join(tmpDir, header.Name)
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(destPath, 0755); err != nil {
+				return fmt.Errorf("failed to create %s: %w", destPath, err)
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+				return fmt.Errorf("failed to create directory for %s: %w", destPath, err)
+			}
+			f, err := os.Create(destPath)
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %w", destPath, err)
+			}
+			if _, err := io.Copy(f, tarReader); err != nil {
+				f.Close()
+				return fmt.Errorf("failed to write %s: %w", destPath, err)
+			}
+			f.Close()
+		}
+	}
+
+	if branchPrefixes == nil {
+		return fmt.Errorf("archive has no %s; it predates manifest support and branch names can't be reconstructed reliably", archiveManifestName)
+	}
+
+	for prefix, branch := range branchPrefixes {
+		if git.BranchExists(ctx, branch) {
+			fmt.Printf("  skip:  %s (already exists)\n", branch)
+			continue
+		}
+		if err := restoreBranchFromDir(ctx, branch, filepath.Join(tmpDir, prefix)); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", branch, err)
+		}
+		fmt.Printf("  restored: %s\n", branch)
+	}
+
+	return nil
+}
+
+// restoreBranchFromDir recreates branch from the files extracted into
+// dir, following the same create-branch/create-worktree/commit idiom
+// save and tag use rather than hand-building git objects.
+func restoreBranchFromDir(ctx context.Context, branch, dir string) error {
+	if err := git.CreateBranch(ctx, branch); err != nil {
+		return fmt.Errorf("failed to create branch: %w", err)
+	}
+
+	worktreePath, err := os.MkdirTemp("", "context-restore-worktree-*")
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(worktreePath); err != nil {
+		return err
+	}
+	defer os.RemoveAll(worktreePath)
+
+	if err := git.CreateWorktree(ctx, worktreePath, branch); err != nil {
+		return fmt.Errorf("failed to create worktree: %w", err)
+	}
+	defer func() {
+		if err := git.RemoveWorktree(ctx, worktreePath); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to remove worktree: %v\n", err)
+		}
+	}()
+
+	if err := git.RemoveAllFilesFromIndexInDir(ctx, worktreePath); err != nil {
+		return fmt.Errorf("failed to clear worktree index: %w", err)
+	}
+
+	if err := copyDir(dir, worktreePath); err != nil {
+		return fmt.Errorf("failed to copy restored files into worktree: %w", err)
+	}
+
+	if err := git.AddFilesInDir(ctx, worktreePath, "."); err != nil {
+		return fmt.Errorf("failed to stage restored files: %w", err)
+	}
+	if err := git.CommitInDir(ctx, worktreePath, fmt.Sprintf("Restore %s from archive", branch)); err != nil {
+		return fmt.Errorf("failed to commit restored files: %w", err)
+	}
+
+	return nil
+}
+
+// copyDir recursively copies the contents of src into dst, which must
+// already exist.
+func copyDir(src, dst string) error {
+	return filepath.Walk(src, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		destPath := filepath.Join(dst, rel)
+		if info.IsDir() {
+			return os.MkdirAll(destPath, 0755)
+		}
+
+		srcFile, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer srcFile.Close()
+
+		if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+			return err
+		}
+		dstFile, err := os.Create(destPath)
+		if err != nil {
+			return err
+		}
+		defer dstFile.Close()
+
+		_, err = io.Copy(dstFile, srcFile)
+		return err
+	})
+}
@@ -1,9 +1,12 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -25,12 +28,25 @@ both human developers and agentic tools.`,
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 }
 
+// commandContext returns cmd's context, falling back to
+// context.Background() when cmd is nil (unit tests call the run functions
+// directly) or was executed without a context.
+func commandContext(cmd *cobra.Command) context.Context {
+	if cmd == nil || cmd.Context() == nil {
+		return context.Background()
+	}
+	return cmd.Context()
+}
+
 func init() {
 	cobra.OnInitialize(initConfig)
 
@@ -58,6 +74,14 @@ func initConfig() {
 	// Set defaults
 	viper.SetDefault("retention.days", 90)
 	viper.SetDefault("retention.preserve_tags", []string{"important"})
+	viper.SetDefault("retention.keep_last", 0)
+	viper.SetDefault("retention.keep_hourly", 0)
+	viper.SetDefault("retention.keep_daily", 0)
+	viper.SetDefault("retention.keep_weekly", 0)
+	viper.SetDefault("retention.keep_monthly", 0)
+	viper.SetDefault("retention.keep_yearly", 0)
+	viper.SetDefault("retention.keep_tag", []string{})
+	viper.SetDefault("retention.keep_within", "")
 	viper.SetDefault("snapshot.default_mode", "full")
 	viper.SetDefault("snapshot.research_dir", "research")
 	viper.SetDefault("embeddings.enabled", true)
@@ -65,6 +89,8 @@ func initConfig() {
 	viper.SetDefault("embeddings.ollama_url", "http://localhost:11434")
 	viper.SetDefault("search.keyword_weight", 0.3)
 	viper.SetDefault("search.semantic_weight", 0.7)
+	viper.SetDefault("cache.metadata_bytes", 8*1024*1024)
+	viper.SetDefault("cache.embedding_bytes", 32*1024*1024)
 
 	if err := viper.ReadInConfig(); err == nil {
 		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
@@ -1,18 +1,24 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/pders01/git-context/internal/config"
 	"github.com/pders01/git-context/internal/embeddings"
 	"github.com/pders01/git-context/internal/git"
+	"github.com/pders01/git-context/internal/gitfilter"
+	"github.com/pders01/git-context/internal/index"
 	"github.com/pders01/git-context/internal/models"
 	"github.com/pders01/git-context/internal/ollama"
+	"github.com/pders01/git-context/internal/signing"
 	"github.com/spf13/cobra"
 )
 
@@ -23,6 +29,10 @@ var (
 	saveTags       []string
 	saveNoEmbed    bool
 	saveNotes      string
+	saveSign       bool
+	saveSignKey    string
+	saveSignFormat string
+	saveFilter     string
 )
 
 var saveCmd = &cobra.Command{
@@ -37,7 +47,10 @@ Modes:
   full (default)    - Full code tree + research artifacts
   research-only     - Only research/ + reference commit hash
   diff              - Store patch + research/ + reference commit
-  poc               - Only POC files + reference commit`,
+  poc               - Only POC files + reference commit
+
+If signing.requireSigned is set in config, --sign (or signing.enabled)
+becomes mandatory and save refuses to create an unsigned snapshot.`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runSave,
 }
@@ -51,11 +64,17 @@ func init() {
 	saveCmd.Flags().StringSliceVar(&saveTags, "tag", []string{}, "Add metadata tags")
 	saveCmd.Flags().BoolVar(&saveNoEmbed, "no-embed", false, "Skip embedding generation")
 	saveCmd.Flags().StringVar(&saveNotes, "notes", "", "Optional notes")
+	saveCmd.Flags().BoolVar(&saveSign, "sign", false, "Sign the snapshot commit and manifest")
+	saveCmd.Flags().StringVar(&saveSignKey, "sign-key", "", "Signing key path (overrides signing.key config)")
+	saveCmd.Flags().StringVar(&saveSignFormat, "sign-format", "", "Signature format: gpg|ssh|x509 (overrides signing.format config)")
+	saveCmd.Flags().StringVar(&saveFilter, "filter", "", "Blob filter for full/poc snapshots: blob:none|blob:limit=<size>|tree:<depth>|sparse:oid=<blob>")
 }
 
 func runSave(cmd *cobra.Command, args []string) error {
+	ctx := commandContext(cmd)
+
 	// Check if we're in a git repository
-	if !git.IsGitRepo() {
+	if !git.IsGitRepo(ctx) {
 		return fmt.Errorf("not a git repository")
 	}
 
@@ -80,17 +99,17 @@ func runSave(cmd *cobra.Command, args []string) error {
 	}
 
 	// Get current state BEFORE creating anything
-	currentBranch, err := git.GetCurrentBranch()
+	currentBranch, err := git.GetCurrentBranch(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get current branch: %w", err)
 	}
 
-	currentCommit, err := git.GetCurrentCommit()
+	currentCommit, err := git.GetCurrentCommit(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get current commit: %w", err)
 	}
 
-	treeHash, err := git.GetTreeHash()
+	treeHash, err := git.GetTreeHash(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get tree hash: %w", err)
 	}
@@ -100,30 +119,75 @@ func runSave(cmd *cobra.Command, args []string) error {
 	snapshotBranch := models.BranchName(timestamp, topic)
 
 	// Check if branch already exists
-	if git.BranchExists(snapshotBranch) {
+	if git.BranchExists(ctx, snapshotBranch) {
 		return fmt.Errorf("snapshot branch already exists: %s (snapshots are immutable)", snapshotBranch)
 	}
 
+	// Resolve signer up front so a bad --sign-key/--sign-format fails fast,
+	// before any branch or worktree has been created.
+	var signer signing.Signer
+	if saveSign || config.GetSigningEnabled() {
+		signFormat := saveSignFormat
+		if signFormat == "" {
+			signFormat = config.GetSigningFormat()
+		}
+		signKey := saveSignKey
+		if signKey == "" {
+			signKey = config.GetSigningKey()
+		}
+		signer, err = signing.NewSigner(signing.Format(signFormat), signKey)
+		if err != nil {
+			return fmt.Errorf("failed to initialize signer: %w", err)
+		}
+	} else if config.GetRequireSigned() {
+		return fmt.Errorf("signing.requireSigned is set: snapshots must be signed (pass --sign)")
+	}
+
+	// Resolve the blob filter up front too, for the same fail-fast reason.
+	filterSpec, err := gitfilter.Parse(saveFilter)
+	if err != nil {
+		return fmt.Errorf("invalid --filter: %w", err)
+	}
+
 	fmt.Printf("Creating snapshot: %s\n", snapshotBranch)
 	fmt.Printf("Mode: %s\n", mode)
 	fmt.Printf("From: %s @ %s\n", currentBranch, currentCommit[:8])
 
 	// Create snapshot branch (but don't checkout)
-	if err := git.CreateBranch(snapshotBranch); err != nil {
+	if err := git.CreateBranch(ctx, snapshotBranch); err != nil {
 		return err
 	}
 
+	// From here on the branch exists on disk. If the context is canceled
+	// before the snapshot commit lands, roll the branch back instead of
+	// leaving a half-populated snapshot behind; a fresh context is used
+	// for the rollback itself since ctx is already done.
+	snapshotCommitted := false
+	defer func() {
+		if snapshotCommitted || ctx.Err() == nil {
+			return
+		}
+		fmt.Fprintf(os.Stderr, "Warning: canceled, removing partial snapshot branch %s\n", snapshotBranch)
+		if err := git.DeleteBranch(context.Background(), snapshotBranch, true); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to remove partial branch: %v\n", err)
+		}
+	}()
+
 	// Create temporary worktree for snapshot
 	worktreePath := filepath.Join(os.TempDir(), fmt.Sprintf("context-snapshot-%d", timestamp.Unix()))
 	fmt.Printf("Creating worktree: %s\n", worktreePath)
 
-	if err := git.CreateWorktree(worktreePath, snapshotBranch); err != nil {
+	if err := git.CreateWorktree(ctx, worktreePath, snapshotBranch); err != nil {
 		return err
 	}
 
-	// Ensure we clean up the worktree
+	// Ensure we clean up the worktree, even if canceled mid-populate.
 	defer func() {
-		if err := git.RemoveWorktree(worktreePath); err != nil {
+		removeCtx := ctx
+		if ctx.Err() != nil {
+			removeCtx = context.Background()
+		}
+		if err := git.RemoveWorktree(removeCtx, worktreePath); err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: failed to remove worktree: %v\n", err)
 		}
 	}()
@@ -155,6 +219,12 @@ func runSave(cmd *cobra.Command, args []string) error {
 		Notes:         saveNotes,
 		TreeHash:      treeHash,
 	}
+	if signer != nil {
+		metadata.SignFormat = string(signer.Format())
+	}
+	if filterSpec.Kind != gitfilter.KindNone {
+		metadata.Filter = filterSpec.Raw
+	}
 
 	// Save metadata
 	metaPath := filepath.Join(worktreeResearchPath, "meta.json")
@@ -166,37 +236,93 @@ func runSave(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to write metadata: %w", err)
 	}
 
+	// Apply the blob filter (if any) before generating embeddings, so the
+	// chunked embedding pass below can substitute path+size metadata for
+	// filtered-out blobs instead of reading their placeholder content.
+	var filterOmissions []gitfilter.Omission
+	var filterChangedPaths []string
+	if filterSpec.Kind != gitfilter.KindNone {
+		switch mode {
+		case models.ModeFull:
+			filterOmissions, filterChangedPaths, err = applyBlobFilter(worktreePath, currentBranch, filterSpec, nil)
+		case models.ModePOC:
+			if len(saveInclude) > 0 {
+				filterOmissions, filterChangedPaths, err = applyBlobFilter(worktreePath, currentBranch, filterSpec, saveInclude)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to apply blob filter: %w", err)
+		}
+		if len(filterOmissions) > 0 {
+			if err := writeOmittedJSON(filepath.Join(worktreeResearchPath, "omitted.json"), filterOmissions); err != nil {
+				return err
+			}
+			fmt.Printf("  Filtered %d blob(s) via %s\n", len(filterOmissions), filterSpec.Raw)
+		}
+	}
+
 	// Generate embeddings if enabled
 	if !saveNoEmbed && config.GetEmbeddingsEnabled() {
-		if err := generateEmbedding(metadata, worktreeResearchPath, notesContent); err != nil {
+		if err := generateEmbedding(ctx, metadata, worktreeResearchPath); err != nil {
 			// Don't fail the snapshot, just warn
 			fmt.Fprintf(os.Stderr, "Warning: failed to generate embedding: %v\n", err)
 			fmt.Fprintln(os.Stderr, "Tip: Ensure Ollama is running and the model is available: ollama pull nomic-embed-text")
 		}
 	}
 
+	// Generate a chunked, multi-vector embedding index over the snapshot's
+	// research artifacts (and code, for modes that keep it) alongside the
+	// single snapshot-level embedding above
+	if !saveNoEmbed && config.GetEmbeddingsEnabled() {
+		if err := generateChunkedEmbedding(ctx, mode, metadata, worktreePath, worktreeResearchPath, saveInclude, filterOmissions); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to generate chunked embeddings: %v\n", err)
+		}
+	}
+
+	// Build a trigram substring index over the captured files so `context
+	// search` can find a literal or phrase term inside file content, not
+	// just in metadata. This is purely syntactic (no Ollama dependency), so
+	// it runs unconditionally rather than gated on --no-embed.
+	if err := buildSnapshotTrigramIndex(mode, worktreePath, worktreeResearchPath, saveInclude, filterOmissions); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to build trigram index: %v\n", err)
+	}
+
+	// Sign a detached manifest of the tree and embedding, if requested
+	if signer != nil {
+		if err := signSnapshotManifest(signer, metadata, treeHash, currentCommit, timestamp, worktreeResearchPath); err != nil {
+			return fmt.Errorf("failed to sign manifest: %w", err)
+		}
+		fmt.Printf("  ✓ Manifest signed (%s)\n", signer.Format())
+	}
+
 	// Handle different modes in the worktree
 	switch mode {
 	case models.ModeFull:
 		// Full snapshot - everything is already there
 		// Just add research directory
-		if err := git.AddFilesInDir(worktreePath, researchPath); err != nil {
+		if err := git.AddFilesInDir(ctx, worktreePath, researchPath); err != nil {
 			return err
 		}
+		// Re-stage any files the blob filter replaced with placeholders
+		if len(filterChangedPaths) > 0 {
+			if err := git.AddFilesInDir(ctx, worktreePath, filterChangedPaths...); err != nil {
+				return err
+			}
+		}
 
 	case models.ModeResearchOnly:
 		// Research only - remove everything except research/
 		fmt.Println("  Removing code files (research-only mode)...")
-		if err := git.RemoveAllFilesFromIndexInDir(worktreePath); err != nil {
+		if err := git.RemoveAllFilesFromIndexInDir(ctx, worktreePath); err != nil {
 			return err
 		}
-		if err := git.AddFilesInDir(worktreePath, researchPath); err != nil {
+		if err := git.AddFilesInDir(ctx, worktreePath, researchPath); err != nil {
 			return err
 		}
 
 	case models.ModeDiff:
 		// Diff mode - create patch file
-		diff, err := git.GetDiff(currentCommit)
+		diff, err := git.GetDiff(ctx, currentCommit)
 		if err != nil {
 			return fmt.Errorf("failed to get diff: %w", err)
 		}
@@ -205,10 +331,10 @@ func runSave(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("failed to write patch: %w", err)
 		}
 		fmt.Println("  Removing code files (diff mode - patch only)...")
-		if err := git.RemoveAllFilesFromIndexInDir(worktreePath); err != nil {
+		if err := git.RemoveAllFilesFromIndexInDir(ctx, worktreePath); err != nil {
 			return err
 		}
-		if err := git.AddFilesInDir(worktreePath, researchPath); err != nil {
+		if err := git.AddFilesInDir(ctx, worktreePath, researchPath); err != nil {
 			return err
 		}
 
@@ -218,13 +344,13 @@ func runSave(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("poc mode requires --include flag to specify files")
 		}
 		fmt.Println("  Removing code files (poc mode - selective inclusion)...")
-		if err := git.RemoveAllFilesFromIndexInDir(worktreePath); err != nil {
+		if err := git.RemoveAllFilesFromIndexInDir(ctx, worktreePath); err != nil {
 			return err
 		}
-		if err := git.AddFilesInDir(worktreePath, researchPath); err != nil {
+		if err := git.AddFilesInDir(ctx, worktreePath, researchPath); err != nil {
 			return err
 		}
-		if err := git.AddFilesInDir(worktreePath, saveInclude...); err != nil {
+		if err := git.AddFilesInDir(ctx, worktreePath, saveInclude...); err != nil {
 			return err
 		}
 	}
@@ -232,9 +358,26 @@ func runSave(cmd *cobra.Command, args []string) error {
 	// Commit the snapshot in the worktree
 	commitMsg := fmt.Sprintf("snapshot: %s\n\nMode: %s\nFrom: %s @ %s\nTags: %v",
 		topic, mode, currentBranch, currentCommit[:8], saveTags)
-	if err := git.CommitInDir(worktreePath, commitMsg); err != nil {
+	if err := git.CommitInDir(ctx, worktreePath, commitMsg); err != nil {
 		return fmt.Errorf("failed to commit snapshot: %w", err)
 	}
+	snapshotCommitted = true
+
+	// Re-sign the commit itself, embedding the signature in a gpgsig header
+	// the same way `git commit -S` would, so the snapshot's provenance is
+	// checkable without trusting whoever ran `context save`.
+	if signer != nil {
+		if err := signSnapshotCommit(ctx, signer, snapshotBranch); err != nil {
+			return fmt.Errorf("failed to sign snapshot commit: %w", err)
+		}
+		fmt.Println("  ✓ Snapshot commit signed")
+	}
+
+	if err := updateXrefIndexForSnapshot(ctx, snapshotBranch); err != nil {
+		// Don't fail the snapshot over indexing; it can be rebuilt later.
+		fmt.Fprintf(os.Stderr, "Warning: failed to update cross-reference index: %v\n", err)
+		fmt.Fprintln(os.Stderr, "Tip: run 'context xref --reindex' to rebuild it")
+	}
 
 	fmt.Printf("\n✓ Snapshot created: %s\n", snapshotBranch)
 	fmt.Printf("  Research: %s\n", researchPath)
@@ -266,10 +409,10 @@ func isValidMode(mode models.SnapshotMode) bool {
 }
 
 // generateEmbedding generates and stores an embedding for a snapshot
-func generateEmbedding(metadata *models.Metadata, researchPath, notesContent string) error {
+func generateEmbedding(ctx context.Context, metadata *models.Metadata, researchPath string) error {
 	// Check if Ollama is available
 	ollamaURL := config.GetOllamaURL()
-	if !ollama.IsAvailable(ollamaURL) {
+	if !ollama.IsAvailable(ctx, ollamaURL) {
 		return fmt.Errorf("Ollama is not available at %s", ollamaURL)
 	}
 
@@ -281,14 +424,18 @@ func generateEmbedding(metadata *models.Metadata, researchPath, notesContent str
 	if err != nil {
 		return fmt.Errorf("failed to create Ollama client: %w", err)
 	}
+	client = client.WithContext(ctx).WithCacheDir(ollamaCacheDir())
 
 	// Check if model is available
-	if err := client.CheckModel(); err != nil {
+	if err := client.CheckModel(ctx); err != nil {
 		return err
 	}
 
-	// Build text to embed: notes.md content + metadata
-	embeddingText := buildEmbeddingText(metadata, notesContent)
+	// Build text to embed. This must be deterministic (stable field
+	// ordering, no timestamps) so identical notes across snapshots hash to
+	// the same content-addressed key in generateEmbedding's CAS write
+	// below, and actually dedupe.
+	embeddingText := buildEmbeddingText(metadata)
 
 	// Truncate if too long (nomic-embed-text supports ~8K tokens, roughly 32K chars)
 	maxChars := 30000
@@ -298,7 +445,7 @@ func generateEmbedding(metadata *models.Metadata, researchPath, notesContent str
 	}
 
 	// Generate embedding
-	vec, err := client.GenerateEmbedding(embeddingText)
+	vec, err := client.GenerateEmbedding(ctx, embeddingText)
 	if err != nil {
 		return fmt.Errorf("failed to generate embedding: %w", err)
 	}
@@ -308,10 +455,22 @@ func generateEmbedding(metadata *models.Metadata, researchPath, notesContent str
 		return fmt.Errorf("invalid embedding: %w", err)
 	}
 
-	// Write embedding to file
+	// Store the vector in the content-addressed object store, keyed by the
+	// SHA-256 of embeddingText, and write a small pointer file in its place
+	// in the snapshot itself. Identical notes across snapshots collide on
+	// the same key, so repeated saves of unchanged notes cost nothing
+	// beyond the pointer file.
+	ptr, err := embeddings.Put(casObjectsDir(), embeddingText, vec)
+	if err != nil {
+		return fmt.Errorf("failed to store embedding: %w", err)
+	}
+	ptrBytes, err := json.Marshal(ptr)
+	if err != nil {
+		return fmt.Errorf("failed to marshal embedding pointer: %w", err)
+	}
 	embeddingPath := filepath.Join(researchPath, "embedding.bin")
-	if err := embeddings.WriteEmbedding(embeddingPath, vec); err != nil {
-		return fmt.Errorf("failed to write embedding: %w", err)
+	if err := os.WriteFile(embeddingPath, ptrBytes, 0644); err != nil {
+		return fmt.Errorf("failed to write embedding pointer: %w", err)
 	}
 
 	// Update metadata to reference embedding
@@ -332,26 +491,365 @@ func generateEmbedding(metadata *models.Metadata, researchPath, notesContent str
 	return nil
 }
 
-// buildEmbeddingText constructs the text to be embedded from metadata and notes
-func buildEmbeddingText(metadata *models.Metadata, notesContent string) string {
-	// Combine topic, tags, notes field, and notes.md content
+// signSnapshotManifest builds a detached manifest of the snapshot's tree,
+// reference commit, and embedding (if any), signs it with signer, and
+// writes the result to manifest.sig in the snapshot's research directory.
+func signSnapshotManifest(signer signing.Signer, metadata *models.Metadata, treeHash, mainCommit string, createdAt time.Time, researchPath string) error {
+	manifest := signing.Manifest{
+		TreeHash:   treeHash,
+		MainCommit: mainCommit,
+		CreatedAt:  createdAt,
+	}
+	if metadata.Embedding != "" {
+		embeddingBytes, err := os.ReadFile(filepath.Join(researchPath, metadata.Embedding))
+		if err != nil {
+			return fmt.Errorf("failed to read embedding for manifest: %w", err)
+		}
+		manifest.Embedding = embeddingBytes
+	}
+
+	sig, err := signing.SignManifest(signer, manifest)
+	if err != nil {
+		return err
+	}
+
+	manifestSigPath := filepath.Join(researchPath, "manifest.sig")
+	if err := os.WriteFile(manifestSigPath, sig, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest.sig: %w", err)
+	}
+	return nil
+}
+
+// signSnapshotCommit re-signs the commit at ref with signer, embedding the
+// signature in a gpgsig header the way `git commit -S` would, and repoints
+// ref at the newly written commit object.
+func signSnapshotCommit(ctx context.Context, signer signing.Signer, ref string) error {
+	raw, err := git.CatFileCommit(ctx, ref)
+	if err != nil {
+		return fmt.Errorf("failed to read commit %s: %w", ref, err)
+	}
+
+	sig, err := signer.Sign([]byte(raw))
+	if err != nil {
+		return err
+	}
+
+	signedRaw := signing.EmbedGPGSig(raw, sig)
+	newCommit, err := git.WriteCommitObject(ctx, []byte(signedRaw))
+	if err != nil {
+		return err
+	}
+
+	return git.UpdateRef(ctx, ref, newCommit)
+}
+
+// generateChunkedEmbedding builds a per-chunk, multi-vector embedding
+// index over a snapshot's research artifacts (and, for modes that keep
+// code, the code itself), storing it as research/<...>/embeddings.bin
+// plus a chunks.jsonl describing each vector's source chunk, for
+// per-chunk cosine ranking rather than snapshot-level ranking.
+func generateChunkedEmbedding(ctx context.Context, mode models.SnapshotMode, metadata *models.Metadata, worktreePath, researchPath string, include []string, omissions []gitfilter.Omission) error {
+	ollamaURL := config.GetOllamaURL()
+	if !ollama.IsAvailable(ctx, ollamaURL) {
+		return fmt.Errorf("Ollama is not available at %s", ollamaURL)
+	}
+
+	fmt.Println("  Generating chunked embeddings...")
+
+	var files []string
+	files = append(files, textFilesUnder(researchPath)...)
+	switch mode {
+	case models.ModeFull:
+		files = append(files, textFilesUnder(worktreePath)...)
+	case models.ModePOC:
+		for _, rel := range include {
+			files = append(files, filepath.Join(worktreePath, rel))
+		}
+	}
+
+	omittedByPath := make(map[string]gitfilter.Omission, len(omissions))
+	for _, o := range omissions {
+		omittedByPath[o.Path] = o
+	}
+
+	var chunks []embeddings.Chunk
+	var texts []string
+	seen := make(map[string]bool, len(files))
+	for _, abs := range files {
+		if seen[abs] {
+			continue
+		}
+		seen[abs] = true
+
+		rel, err := filepath.Rel(worktreePath, abs)
+		if err != nil {
+			continue
+		}
+
+		// A filtered-out blob contributes its path and size to the
+		// embedding text, not its (by-now placeholder) content.
+		if o, ok := omittedByPath[rel]; ok {
+			text := fmt.Sprintf("Omitted blob: %s (%d bytes)", o.Path, o.Size)
+			chunks = append(chunks, embeddings.Chunk{Path: rel, Start: 0, End: len(text)})
+			texts = append(texts, text)
+			continue
+		}
+
+		content, err := os.ReadFile(abs)
+		if err != nil || !isLikelyText(content) {
+			continue
+		}
+
+		for _, c := range embeddings.ChunkText(rel, string(content)) {
+			chunks = append(chunks, c)
+			texts = append(texts, string(content)[c.Start:c.End])
+		}
+	}
+
+	if len(chunks) == 0 {
+		return fmt.Errorf("no text chunks found to embed")
+	}
+
+	model := config.GetEmbeddingModel()
+	client, err := ollama.NewClient(ollamaURL, model)
+	if err != nil {
+		return fmt.Errorf("failed to create Ollama client: %w", err)
+	}
+	client = client.WithContext(ctx).WithCacheDir(ollamaCacheDir())
+	if err := client.CheckModel(ctx); err != nil {
+		return err
+	}
+
+	vecs, err := client.GenerateEmbeddings(ctx, texts)
+	if err != nil {
+		return fmt.Errorf("failed to generate chunked embeddings: %w", err)
+	}
+
+	embeddingsPath := filepath.Join(researchPath, "embeddings.bin")
+	if err := embeddings.WriteChunkedEmbeddings(embeddingsPath, vecs); err != nil {
+		return fmt.Errorf("failed to write chunked embeddings: %w", err)
+	}
+
+	chunksPath := filepath.Join(researchPath, "chunks.jsonl")
+	if err := writeChunksJSONL(chunksPath, chunks); err != nil {
+		return fmt.Errorf("failed to write chunks.jsonl: %w", err)
+	}
+
+	metadata.Chunked = &models.ChunkedEmbedding{
+		File:   "embeddings.bin",
+		Chunks: "chunks.jsonl",
+		Dim:    len(vecs[0]),
+		Count:  len(vecs),
+		Format: "float64",
+	}
+
+	metaPath := filepath.Join(researchPath, "meta.json")
+	metaBytes, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	if err := os.WriteFile(metaPath, metaBytes, 0644); err != nil {
+		return fmt.Errorf("failed to update metadata: %w", err)
+	}
+
+	fmt.Printf("  ✓ Chunked embeddings generated (%d chunks, %d dimensions)\n", len(vecs), len(vecs[0]))
+
+	return nil
+}
+
+// buildSnapshotTrigramIndex builds a trigram substring index (see
+// internal/index) over the same set of files a snapshot's mode captures,
+// and writes it to researchPath/index.trigram. Unlike embeddings, this
+// needs no Ollama round-trip, so it runs for every save regardless of
+// --no-embed.
+func buildSnapshotTrigramIndex(mode models.SnapshotMode, worktreePath, researchPath string, include []string, omissions []gitfilter.Omission) error {
+	var absPaths []string
+	absPaths = append(absPaths, textFilesUnder(researchPath)...)
+	switch mode {
+	case models.ModeFull:
+		absPaths = append(absPaths, textFilesUnder(worktreePath)...)
+	case models.ModePOC:
+		for _, rel := range include {
+			absPaths = append(absPaths, filepath.Join(worktreePath, rel))
+		}
+	}
+
+	omittedByPath := make(map[string]bool, len(omissions))
+	for _, o := range omissions {
+		omittedByPath[o.Path] = true
+	}
+
+	var files []index.File
+	seen := make(map[string]bool, len(absPaths))
+	for _, abs := range absPaths {
+		rel, err := filepath.Rel(worktreePath, abs)
+		if err != nil || seen[rel] || omittedByPath[rel] {
+			continue
+		}
+		seen[rel] = true
+
+		content, err := os.ReadFile(abs)
+		if err != nil || !isLikelyText(content) {
+			continue
+		}
+		files = append(files, index.File{Path: rel, Content: content})
+	}
+
+	if len(files) == 0 {
+		return nil
+	}
+
+	raw, err := index.Build(files)
+	if err != nil {
+		return fmt.Errorf("failed to build trigram index: %w", err)
+	}
+
+	indexPath := filepath.Join(researchPath, "index.trigram")
+	if err := os.WriteFile(indexPath, raw, 0644); err != nil {
+		return fmt.Errorf("failed to write trigram index: %w", err)
+	}
+
+	fmt.Printf("  ✓ Trigram index built (%d files)\n", len(files))
+	return nil
+}
+
+// textFilesUnder recursively lists every regular file under root, skipping
+// .git directories.
+func textFilesUnder(root string) []string {
+	var files []string
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		files = append(files, path)
+		return nil
+	})
+	return files
+}
+
+// isLikelyText reports whether content looks like text rather than a
+// binary file, by checking the first 512 bytes for a NUL byte.
+func isLikelyText(content []byte) bool {
+	if len(content) == 0 {
+		return false
+	}
+	limit := len(content)
+	if limit > 512 {
+		limit = 512
+	}
+	for _, b := range content[:limit] {
+		if b == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// writeChunksJSONL writes one JSON object per line, one per chunk, giving
+// the offsets and heading ReadChunkedEmbeddings' vectors correspond to.
+func writeChunksJSONL(path string, chunks []embeddings.Chunk) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create chunks.jsonl: %w", err)
+	}
+	defer file.Close()
+
+	enc := json.NewEncoder(file)
+	for _, c := range chunks {
+		if err := enc.Encode(c); err != nil {
+			return fmt.Errorf("failed to write chunk: %w", err)
+		}
+	}
+	return nil
+}
+
+// applyBlobFilter rewrites files under worktreePath that spec excludes,
+// replacing their content with a small placeholder pointing at the
+// original blob's SHA on relatedBranch. When paths is non-empty (poc mode,
+// where only explicitly --include'd files are added), only those files are
+// considered; otherwise every file under worktreePath is. It returns the
+// resulting omissions plus which paths (relative to worktreePath) were
+// rewritten and need to be re-staged.
+func applyBlobFilter(worktreePath, relatedBranch string, spec gitfilter.Spec, paths []string) ([]gitfilter.Omission, []string, error) {
+	var files []string
+	if len(paths) > 0 {
+		for _, rel := range paths {
+			files = append(files, filepath.Join(worktreePath, rel))
+		}
+	} else {
+		files = textFilesUnder(worktreePath)
+	}
+
+	var omissions []gitfilter.Omission
+	var changed []string
+	for _, abs := range files {
+		info, err := os.Stat(abs)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		rel, err := filepath.Rel(worktreePath, abs)
+		if err != nil {
+			continue
+		}
+		if !spec.ExcludesBlob(rel, info.Size()) {
+			continue
+		}
+
+		content, err := os.ReadFile(abs)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s for filtering: %w", rel, err)
+		}
+		sha := gitfilter.BlobSHA(content)
+		placeholder := gitfilter.Placeholder(rel, sha, info.Size(), relatedBranch, spec.Raw)
+		if err := os.WriteFile(abs, []byte(placeholder), info.Mode()); err != nil {
+			return nil, nil, fmt.Errorf("failed to write placeholder for %s: %w", rel, err)
+		}
+
+		omissions = append(omissions, gitfilter.Omission{Path: rel, SHA: sha, Size: info.Size(), Reason: spec.Raw})
+		changed = append(changed, rel)
+	}
+	return omissions, changed, nil
+}
+
+// writeOmittedJSON writes the blobs a filter excluded from a snapshot as a
+// JSON array, so a later `context restore` can rehydrate them on demand
+// from the parent branch.
+func writeOmittedJSON(path string, omissions []gitfilter.Omission) error {
+	data, err := json.MarshalIndent(omissions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal omitted.json: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write omitted.json: %w", err)
+	}
+	return nil
+}
+
+// buildEmbeddingText constructs the text to be embedded from metadata
+// alone, deliberately excluding anything instance-specific (created
+// timestamp, related branch, commit hash): identical topic/tags/notes
+// across snapshots must produce identical text, so they hash to the same
+// content-addressed embedding key (see generateEmbedding).
+func buildEmbeddingText(metadata *models.Metadata) string {
 	var parts []string
 
-	// Add topic
 	parts = append(parts, "Topic: "+metadata.Topic)
 
-	// Add tags
 	if len(metadata.Tags) > 0 {
-		parts = append(parts, "Tags: "+strings.Join(metadata.Tags, ", "))
+		tags := append([]string(nil), metadata.Tags...)
+		sort.Strings(tags)
+		parts = append(parts, "Tags: "+strings.Join(tags, ", "))
 	}
 
-	// Add notes from metadata
 	if metadata.Notes != "" {
 		parts = append(parts, "Notes: "+metadata.Notes)
 	}
 
-	// Add full notes.md content
-	parts = append(parts, notesContent)
-
 	return strings.Join(parts, "\n\n")
 }
@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pders01/git-context/internal/config"
+	"github.com/pders01/git-context/internal/git"
+	"github.com/pders01/git-context/internal/schedule"
+	"github.com/spf13/cobra"
+)
+
+var scheduleRunOnce bool
+
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Run context save automatically on cron schedules",
+	Long: `Run as a long-lived daemon that fires context save on the cron
+expressions configured under schedule.jobs in config.toml:
+
+  [[schedule.jobs]]
+  cron = "0 */4 * * *"
+  topic = "auto"
+  mode = "research-only"
+  tags = ["auto"]
+  dedup_within = "2h"
+
+  [[schedule.jobs]]
+  cron = "@daily"
+  topic = "daily"
+  mode = "full"
+
+cron accepts standard 5-field expressions and the @hourly/@daily/@weekly
+descriptors. A random jitter of up to 30s is added before each tick
+fires, so a fleet of repos sharing the same expression doesn't all hit
+Ollama at once. If dedup_within is set and a snapshot already exists for
+the job's topic within that window, the tick is skipped rather than
+creating a near-duplicate snapshot.
+
+Pass --run-once to fire every configured job a single time and exit,
+for teams that already have external cron or systemd timers and just
+want this command's dedup/jitter/logging behavior without a daemon.
+
+Every attempt, fired or skipped, is appended to .git/context/schedule.log.
+See context schedule status for next-fire times and last-run results.
+
+Examples:
+  context schedule
+  context schedule --run-once`,
+	RunE: runSchedule,
+}
+
+var scheduleStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show next-fire times and last-run results for schedule.jobs",
+	Long: `Print, per job in schedule.jobs, when it next fires and the outcome
+of its most recent tick recorded in .git/context/schedule.log (fired,
+skipped with a reason, or errored). This does not require a context
+schedule daemon to be running: next-fire times are computed directly
+from the cron expressions.`,
+	RunE: runScheduleStatus,
+}
+
+func init() {
+	rootCmd.AddCommand(scheduleCmd)
+	scheduleCmd.AddCommand(scheduleStatusCmd)
+
+	scheduleCmd.Flags().BoolVar(&scheduleRunOnce, "run-once", false, "Fire every configured job once and exit, instead of running as a daemon")
+}
+
+func scheduleLogPath() string {
+	return filepath.Join(".git", "context", "schedule.log")
+}
+
+func runSchedule(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if !git.IsGitRepo(ctx) {
+		return fmt.Errorf("not a git repository")
+	}
+
+	jobs, err := config.GetSchedules()
+	if err != nil {
+		return fmt.Errorf("failed to read schedule.jobs: %w", err)
+	}
+	if len(jobs) == 0 {
+		return fmt.Errorf("no schedule.jobs configured (see context schedule --help)")
+	}
+
+	logPath := scheduleLogPath()
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+		return fmt.Errorf("failed to create schedule log directory: %w", err)
+	}
+
+	jitter := schedule.MaxJitter
+	if scheduleRunOnce {
+		jitter = 0
+	}
+	sched := schedule.New(jobs, triggerScheduledSave, logPath, jitter)
+
+	if scheduleRunOnce {
+		for _, result := range sched.RunOnce(ctx) {
+			printScheduleResult(result)
+		}
+		return nil
+	}
+
+	fmt.Printf("Watching %d schedule job(s), logging to %s (Ctrl-C to stop)\n", len(jobs), logPath)
+	return sched.Start(ctx)
+}
+
+func printScheduleResult(result schedule.Result) {
+	switch {
+	case result.Error != "":
+		fmt.Printf("[%s] %s: error: %s\n", result.Time.Format(time.RFC3339), result.Topic, result.Error)
+	case result.Skipped:
+		fmt.Printf("[%s] %s: skipped (%s)\n", result.Time.Format(time.RFC3339), result.Topic, result.Reason)
+	default:
+		fmt.Printf("[%s] %s: snapshot created\n", result.Time.Format(time.RFC3339), result.Topic)
+	}
+}
+
+// triggerScheduledSave is the schedule.TriggerFunc that turns a fired
+// tick into a context save, after checking the job's dedup window.
+func triggerScheduledSave(ctx context.Context, job schedule.Job) (bool, string, error) {
+	if job.Topic == "" {
+		return false, "", fmt.Errorf("schedule job has no topic")
+	}
+
+	if job.DedupWithin != "" {
+		within, err := parseRetentionDuration(job.DedupWithin)
+		if err != nil {
+			return false, "", fmt.Errorf("invalid dedup_within: %w", err)
+		}
+		recent, err := mostRecentSnapshotForTopic(ctx, job.Topic)
+		if err != nil {
+			return false, "", err
+		}
+		if recent != nil && time.Since(*recent) < within {
+			return true, fmt.Sprintf("snapshot already exists within dedup_within=%s", job.DedupWithin), nil
+		}
+	}
+
+	saveTopic = job.Topic
+	saveMode = job.Mode
+	saveTags = job.Tags
+	saveInclude = nil
+	saveNoEmbed = false
+	saveNotes = ""
+	saveSign = false
+	saveSignKey = ""
+	saveSignFormat = ""
+	saveFilter = ""
+
+	if err := runSave(saveCmd, nil); err != nil {
+		return false, "", err
+	}
+	return false, "", nil
+}
+
+// mostRecentSnapshotForTopic returns the creation time of the newest
+// snapshot branch for topic, or nil if none exists.
+func mostRecentSnapshotForTopic(ctx context.Context, topic string) (*time.Time, error) {
+	branches, err := git.ListBranches(ctx, "snapshot/*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	var newest *time.Time
+	for _, branch := range branches {
+		info, err := parseSnapshotBranch(branch)
+		if err != nil || info.Topic != topic {
+			continue
+		}
+		if newest == nil || info.Timestamp.After(*newest) {
+			ts := info.Timestamp
+			newest = &ts
+		}
+	}
+	return newest, nil
+}
+
+func runScheduleStatus(cmd *cobra.Command, args []string) error {
+	jobs, err := config.GetSchedules()
+	if err != nil {
+		return fmt.Errorf("failed to read schedule.jobs: %w", err)
+	}
+	if len(jobs) == 0 {
+		fmt.Println("No schedule.jobs configured.")
+		return nil
+	}
+
+	results, err := schedule.ReadLog(scheduleLogPath())
+	if err != nil {
+		return err
+	}
+	last := schedule.LastByTopic(results)
+
+	now := time.Now()
+	sorted := append([]schedule.Job(nil), jobs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Topic < sorted[j].Topic })
+
+	for _, job := range sorted {
+		next, err := schedule.NextFire(job.Cron, now)
+		if err != nil {
+			fmt.Printf("%s: %s -- invalid: %v\n", job.Topic, job.Cron, err)
+			continue
+		}
+
+		fmt.Printf("%s: %s\n", job.Topic, job.Cron)
+		fmt.Printf("  next: %s\n", next.Format(time.RFC3339))
+
+		if r, ok := last[job.Topic]; ok {
+			switch {
+			case r.Error != "":
+				fmt.Printf("  last: %s error: %s\n", r.Time.Format(time.RFC3339), r.Error)
+			case r.Skipped:
+				fmt.Printf("  last: %s skipped (%s)\n", r.Time.Format(time.RFC3339), r.Reason)
+			default:
+				fmt.Printf("  last: %s snapshot created\n", r.Time.Format(time.RFC3339))
+			}
+		} else {
+			fmt.Printf("  last: never\n")
+		}
+	}
+
+	return nil
+}
@@ -1,22 +1,41 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
+	"unicode"
 
 	"github.com/alpkeskin/gotoon"
+	"github.com/pders01/git-context/internal/bm25"
+	"github.com/pders01/git-context/internal/cache"
 	"github.com/pders01/git-context/internal/config"
 	"github.com/pders01/git-context/internal/embeddings"
 	"github.com/pders01/git-context/internal/git"
+	"github.com/pders01/git-context/internal/index"
 	"github.com/pders01/git-context/internal/models"
 	"github.com/pders01/git-context/internal/ollama"
 	"github.com/spf13/cobra"
 )
 
+var (
+	embeddingCacheOnce sync.Once
+	searchEmbeddingLRU *cache.EmbeddingLRU
+)
+
+// getEmbeddingCache returns the process-wide embedding LRU, warmed from the
+// on-disk cache on first use.
+func getEmbeddingCache() *cache.EmbeddingLRU {
+	embeddingCacheOnce.Do(func() {
+		searchEmbeddingLRU = cache.NewEmbeddingLRU(config.GetEmbeddingCacheBytes())
+	})
+	return searchEmbeddingLRU
+}
+
 var (
 	searchTopic string
 	searchJSON  bool
@@ -59,10 +78,10 @@ func init() {
 }
 
 type searchQuery struct {
-	required []string   // +term (must include)
-	excluded []string   // -term (must exclude)
-	phrases  []string   // "exact phrase"
-	normal   []string   // regular terms
+	required []string // +term (must include)
+	excluded []string // -term (must exclude)
+	phrases  []string // "exact phrase"
+	normal   []string // regular terms
 }
 
 func parseSearchQuery(query string) searchQuery {
@@ -115,7 +134,8 @@ func parseSearchQuery(query string) searchQuery {
 }
 
 func runSearch(cmd *cobra.Command, args []string) error {
-	if !git.IsGitRepo() {
+	ctx := commandContext(cmd)
+	if !git.IsGitRepo(ctx) {
 		return fmt.Errorf("not a git repository")
 	}
 
@@ -126,7 +146,7 @@ func runSearch(cmd *cobra.Command, args []string) error {
 	embeddingQuery := query
 
 	// Get all snapshot branches
-	branches, err := git.ListBranches("snapshot/*")
+	branches, err := git.ListBranches(ctx, "snapshot/*")
 	if err != nil {
 		return fmt.Errorf("failed to list branches: %w", err)
 	}
@@ -140,10 +160,10 @@ func runSearch(cmd *cobra.Command, args []string) error {
 	var queryEmbedding []float64
 	useSemanticSearch := false
 
-	if config.GetEmbeddingsEnabled() && ollama.IsAvailable(config.GetOllamaURL()) {
+	if config.GetEmbeddingsEnabled() && ollama.IsAvailable(ctx, config.GetOllamaURL()) {
 		client, err := ollama.NewClient(config.GetOllamaURL(), config.GetEmbeddingModel())
 		if err == nil {
-			queryEmbedding, err = client.GenerateEmbedding(embeddingQuery)
+			queryEmbedding, err = client.GenerateEmbedding(ctx, embeddingQuery)
 			if err == nil {
 				useSemanticSearch = true
 				if !searchJSON && !searchToon {
@@ -161,91 +181,115 @@ func runSearch(cmd *cobra.Command, args []string) error {
 	keywordWeight := config.GetKeywordWeight()
 	semanticWeight := config.GetSemanticWeight()
 
-	// Search through snapshots
-	var results []searchResult
+	// First pass: read every snapshot's metadata once, so BM25's corpus
+	// statistics (document frequency, average field length) reflect the
+	// whole repository rather than just the branches the topic filter
+	// will keep.
+	var candidates []searchCandidate
 	for _, branch := range branches {
 		info, err := parseSnapshotBranch(branch)
 		if err != nil {
 			continue
 		}
 
-		// Apply topic filter
-		if searchTopic != "" && info.Topic != searchTopic {
-			continue
-		}
-
-		// Read metadata
-		metaPath := models.MetadataPath(info.Timestamp, info.Topic)
-		metaContent, err := gitShow(branch, metaPath)
+		// Resolve against the operation log (see context log / context tag),
+		// not just the base meta.json blob, so a tag added after the
+		// snapshot was created still counts toward corpus stats and scoring.
+		metadata, err := resolvedMetadata(ctx, info, "")
 		if err != nil {
 			continue
 		}
 
-		var metadata models.Metadata
-		if err := json.Unmarshal([]byte(metaContent), &metadata); err != nil {
+		candidates = append(candidates, searchCandidate{branch: branch, info: info, metadata: *metadata})
+	}
+
+	terms := bm25Terms(parsedQuery)
+	docs := make([]bm25.Doc, len(candidates))
+	for i, c := range candidates {
+		docs[i] = snapshotDoc(c.branch, &c.metadata)
+	}
+	stats := loadOrBuildBM25Stats(branches, docs, terms)
+	bm25Params := bm25.Params{K1: config.GetBM25K1(), B: config.GetBM25B(), FieldWeights: config.GetBM25FieldWeights()}
+
+	// Second pass: apply the topic filter and boolean operators, then
+	// score the survivors.
+	type scored struct {
+		candidate searchCandidate
+		rawBM25   float64
+		semantic  float64
+		hasEmbed  bool
+		semUsed   bool
+		hits      []Hit
+	}
+	var kept []scored
+	for i, c := range candidates {
+		if searchTopic != "" && c.info.Topic != searchTopic {
 			continue
 		}
-
-		// Calculate keyword relevance score with boolean operators
-		keywordScore, shouldExclude := calculateRelevance(parsedQuery, &metadata)
-		if shouldExclude {
+		if !passesBooleanFilters(parsedQuery, &c.metadata) {
 			continue
 		}
 
-		// Try to calculate semantic similarity
 		var semanticScore float64
 		hasEmbedding := false
 		usedSemantic := false
-
-		if useSemanticSearch && metadata.Embedding != "" {
-			// Load snapshot embedding from branch
-			embeddingPath := filepath.Join(models.ResearchPath(info.Timestamp, info.Topic), metadata.Embedding)
-			embeddingContent, err := gitShow(branch, embeddingPath)
+		if useSemanticSearch && (c.metadata.Embedding != "" || c.metadata.EmbeddingDelta != "") {
+			snapshotEmbedding, err := loadSnapshotEmbedding(ctx, c.branch, c.info, &c.metadata)
 			if err == nil {
-				// Write to temp file to read binary
-				tmpFile := filepath.Join("/tmp", fmt.Sprintf("embedding-%s-%s.bin", info.Timestamp.Format("20060102T1504"), info.Topic))
-				if err := os.WriteFile(tmpFile, []byte(embeddingContent), 0644); err == nil {
-					defer os.Remove(tmpFile)
-
-					snapshotEmbedding, err := embeddings.ReadEmbedding(tmpFile)
-					if err == nil {
-						similarity, err := embeddings.CosineSimilarity(queryEmbedding, snapshotEmbedding)
-						if err == nil {
-							// Convert similarity from [-1, 1] to [0, 100] for consistency
-							semanticScore = (similarity + 1) * 50
-							hasEmbedding = true
-							usedSemantic = true
-						}
-					}
+				similarity, err := embeddings.CosineSimilarity(queryEmbedding, snapshotEmbedding)
+				if err == nil {
+					// Convert similarity from [-1, 1] to [0, 100] for consistency
+					semanticScore = (similarity + 1) * 50
+					hasEmbedding = true
+					usedSemantic = true
 				}
 			}
 		}
 
-		// Calculate combined score
+		// Look for the query's literal/phrase terms inside the snapshot's
+		// captured file content via its trigram index (see internal/index),
+		// not just its metadata.
+		hits := searchContentHits(ctx, c.branch, c.info, parsedQuery)
+
+		kept = append(kept, scored{
+			candidate: c,
+			rawBM25:   bm25.Score(docs[i], terms, stats, bm25Params),
+			semantic:  semanticScore,
+			hasEmbed:  hasEmbedding,
+			semUsed:   usedSemantic,
+			hits:      hits,
+		})
+	}
+
+	rawScores := make([]float64, len(kept))
+	for i, k := range kept {
+		rawScores[i] = k.rawBM25
+	}
+	normalizedKeyword := bm25.Normalize(rawScores)
+
+	var results []searchResult
+	for i, k := range kept {
 		var finalScore float64
-		if usedSemantic {
-			// Hybrid: weighted combination
-			// Normalize keyword score to 0-100 range (divide by 2 for rough normalization)
-			normalizedKeyword := float64(keywordScore) / 2.0
-			if normalizedKeyword > 100 {
-				normalizedKeyword = 100
-			}
-			finalScore = keywordWeight*normalizedKeyword + semanticWeight*semanticScore
+		if k.semUsed {
+			finalScore = keywordWeight*normalizedKeyword[i] + semanticWeight*k.semantic
 		} else {
-			// Keyword only
-			finalScore = float64(keywordScore)
+			finalScore = normalizedKeyword[i]
 		}
+		// A content hit is strong, unambiguous evidence (a verified
+		// substring match, not a fuzzy score), so it adds on top of
+		// whatever the keyword/semantic score already found.
+		finalScore += float64(len(k.hits)) * 15
 
-		// Only include results with some relevance
-		if finalScore > 0 || keywordScore > 0 {
+		if finalScore > 0 || k.rawBM25 > 0 || len(k.hits) > 0 {
 			results = append(results, searchResult{
-				Info:          info,
-				Metadata:      metadata,
+				Info:          k.candidate.info,
+				Metadata:      k.candidate.metadata,
 				Score:         finalScore,
-				KeywordScore:  keywordScore,
-				SemanticScore: semanticScore,
-				HasEmbedding:  hasEmbedding,
-				UsedSemantic:  usedSemantic,
+				KeywordScore:  normalizedKeyword[i],
+				SemanticScore: k.semantic,
+				HasEmbedding:  k.hasEmbed,
+				UsedSemantic:  k.semUsed,
+				Hits:          k.hits,
 			})
 		}
 	}
@@ -285,7 +329,7 @@ func runSearch(cmd *cobra.Command, args []string) error {
 	for i, r := range results {
 		scoreDisplay := fmt.Sprintf("%.1f", r.Score)
 		if r.UsedSemantic {
-			scoreDisplay += fmt.Sprintf(" (keyword: %d, semantic: %.1f%%)", r.KeywordScore, r.SemanticScore)
+			scoreDisplay += fmt.Sprintf(" (keyword: %.1f, semantic: %.1f%%)", r.KeywordScore, r.SemanticScore)
 		} else {
 			scoreDisplay += " (keyword only)"
 		}
@@ -306,23 +350,238 @@ func runSearch(cmd *cobra.Command, args []string) error {
 			}
 			fmt.Printf("   Notes:   %s\n", notes)
 		}
+
+		if len(r.Hits) > 0 {
+			fmt.Printf("   Hits:\n")
+			for _, h := range r.Hits {
+				fmt.Printf("     %s:%d: %s\n", h.Path, h.Line, h.Snippet)
+			}
+		}
 		fmt.Println()
 	}
 
 	return nil
 }
 
+// maxEmbeddingChainDepth bounds how many EmbeddingBase hops
+// resolveSnapshotEmbedding will follow before giving up, guarding against a
+// cycle introduced by a corrupted or hand-edited meta.json.
+const maxEmbeddingChainDepth = 32
+
+// loadSnapshotEmbedding returns a snapshot's embedding vector, serving it
+// from the process-wide embedding LRU when available and only falling back
+// to `git show` plus a decode on a cache miss.
+func loadSnapshotEmbedding(ctx context.Context, branch string, info snapshotInfo, metadata *models.Metadata) ([]float64, error) {
+	return resolveSnapshotEmbedding(ctx, branch, info, metadata, 0)
+}
+
+// resolveSnapshotEmbedding resolves a snapshot's embedding, following its
+// EmbeddingBase chain when the snapshot is delta-compressed (see
+// `context gc --repack-embeddings`). Each hop is itself cached, so a base
+// shared by many snapshots is only resolved from git once.
+func resolveSnapshotEmbedding(ctx context.Context, branch string, info snapshotInfo, metadata *models.Metadata, depth int) ([]float64, error) {
+	if depth > maxEmbeddingChainDepth {
+		return nil, fmt.Errorf("embedding base chain for %s exceeds %d hops (possible cycle)", branch, maxEmbeddingChainDepth)
+	}
+
+	ec := getEmbeddingCache()
+
+	sha, err := git.RevParseBranch(ctx, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	if cached, ok := ec.Get(sha); ok {
+		return float32sToFloat64s(cached), nil
+	}
+
+	payloadName := metadata.Embedding
+	if metadata.EmbeddingDelta != "" {
+		payloadName = metadata.EmbeddingDelta
+	}
+	if payloadName == "" {
+		return nil, fmt.Errorf("snapshot %s has no stored embedding", branch)
+	}
+
+	payloadPath := filepath.Join(models.ResearchPath(info.Timestamp, info.Topic), payloadName)
+	payloadContent, err := gitShow(ctx, branch, payloadPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var base []float64
+	if metadata.EmbeddingDelta != "" {
+		baseInfo, err := parseSnapshotBranch(metadata.EmbeddingBase)
+		if err != nil {
+			return nil, fmt.Errorf("invalid embedding base branch %q: %w", metadata.EmbeddingBase, err)
+		}
+		baseMetadata, err := resolvedMetadata(ctx, baseInfo, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to load embedding base metadata for %s: %w", metadata.EmbeddingBase, err)
+		}
+		base, err = resolveSnapshotEmbedding(ctx, metadata.EmbeddingBase, baseInfo, baseMetadata, depth+1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve embedding base %s: %w", metadata.EmbeddingBase, err)
+		}
+	}
+
+	var vec []float64
+	if metadata.EmbeddingDelta == "" {
+		// A raw (non-delta) embedding may be a content-addressed Pointer
+		// rather than the vector itself; embeddings.Resolve handles both,
+		// and stays compatible with snapshots saved before dedup existed.
+		vec, err = embeddings.Resolve(casObjectsDir(), []byte(payloadContent))
+	} else {
+		vec, err = metadata.ResolveEmbedding([]byte(payloadContent), base)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ec.Add(sha, float64sToFloat32s(vec))
+	ec.Save()
+
+	return vec, nil
+}
+
+func float64sToFloat32s(vec []float64) []float32 {
+	out := make([]float32, len(vec))
+	for i, v := range vec {
+		out[i] = float32(v)
+	}
+	return out
+}
+
+func float32sToFloat64s(vec []float32) []float64 {
+	out := make([]float64, len(vec))
+	for i, v := range vec {
+		out[i] = float64(v)
+	}
+	return out
+}
+
 type searchResult struct {
-	Info            snapshotInfo      `json:"info"`
-	Metadata        models.Metadata   `json:"metadata"`
-	Score           float64           `json:"score"`
-	KeywordScore    int               `json:"keyword_score"`
-	SemanticScore   float64           `json:"semantic_score"`
-	HasEmbedding    bool              `json:"has_embedding"`
-	UsedSemantic    bool              `json:"used_semantic"`
+	Info          snapshotInfo    `json:"info"`
+	Metadata      models.Metadata `json:"metadata"`
+	Score         float64         `json:"score"`
+	KeywordScore  float64         `json:"keyword_score"`
+	SemanticScore float64         `json:"semantic_score"`
+	HasEmbedding  bool            `json:"has_embedding"`
+	UsedSemantic  bool            `json:"used_semantic"`
+	Hits          []Hit           `json:"hits,omitempty"`
+}
+
+// Hit is one verified substring match of a query term inside a snapshot's
+// captured file content, found via its trigram index.
+type Hit struct {
+	Path    string `json:"path"`
+	Line    int    `json:"line"`
+	Snippet string `json:"snippet"`
+}
+
+// maxHitsPerSnapshot bounds how many content hits searchContentHits
+// collects per snapshot, so a term that matches hundreds of files (or a
+// term shorter than a trigram, which falls back to scanning every indexed
+// file) can't blow up a single search.
+const maxHitsPerSnapshot = 5
+
+// searchContentHits looks up query's required/normal/phrase terms against
+// a snapshot's trigram index, verifying every trigram candidate with a
+// real substring match before trusting it (trigram membership alone is
+// necessary but not sufficient — see the internal/index doc comment). A
+// snapshot saved before the trigram index existed, or one gitShow can't
+// read, simply contributes no hits rather than failing the whole search.
+func searchContentHits(ctx context.Context, branch string, info snapshotInfo, query searchQuery) []Hit {
+	indexPath := filepath.Join(models.ResearchPath(info.Timestamp, info.Topic), "index.trigram")
+	raw, err := gitShow(ctx, branch, indexPath)
+	if err != nil {
+		return nil
+	}
+	idx, err := index.Open([]byte(raw))
+	if err != nil {
+		return nil
+	}
+
+	var terms []string
+	terms = append(terms, query.required...)
+	terms = append(terms, query.normal...)
+	terms = append(terms, query.phrases...)
+
+	var hits []Hit
+	seen := make(map[string]bool)
+	for _, term := range terms {
+		if len(hits) >= maxHitsPerSnapshot || term == "" {
+			break
+		}
+
+		var candidates []int
+		if len(term) < 3 {
+			// Too short to have a trigram of its own; fall back to
+			// checking every indexed file directly.
+			for i := range idx.Files {
+				candidates = append(candidates, i)
+			}
+		} else {
+			candidates = idx.CandidateFiles(term)
+		}
+
+		for _, fid := range candidates {
+			if len(hits) >= maxHitsPerSnapshot {
+				break
+			}
+			path := idx.Files[fid]
+			key := path + "\x00" + term
+			if seen[key] {
+				continue
+			}
+
+			content, err := gitShow(ctx, branch, path)
+			if err != nil {
+				continue
+			}
+			pos := strings.Index(strings.ToLower(content), strings.ToLower(term))
+			if pos < 0 {
+				continue
+			}
+			seen[key] = true
+
+			line := strings.Count(content[:pos], "\n") + 1
+			hits = append(hits, Hit{Path: path, Line: line, Snippet: snippetAround(content, pos, len(term))})
+		}
+	}
+
+	return hits
 }
 
-func calculateRelevance(query searchQuery, metadata *models.Metadata) (int, bool) {
+// snippetAround returns a single line of context around content[pos:pos+length].
+func snippetAround(content string, pos, length int) string {
+	start := pos - 30
+	if start < 0 {
+		start = 0
+	}
+	end := pos + length + 30
+	if end > len(content) {
+		end = len(content)
+	}
+	return strings.TrimSpace(strings.ReplaceAll(content[start:end], "\n", " "))
+}
+
+// searchCandidate is one snapshot branch that parsed successfully and
+// had readable metadata, before the topic filter or boolean operators
+// are applied. Gathering these in a first pass is what lets BM25's
+// corpus statistics (see loadOrBuildBM25Stats) see the whole repository
+// rather than just whatever the topic filter will keep.
+type searchCandidate struct {
+	branch   string
+	info     snapshotInfo
+	metadata models.Metadata
+}
+
+// passesBooleanFilters applies parseSearchQuery's required/excluded/
+// phrase operators against metadata's searchable text. This is the
+// boolean pre-filter calculateRelevance used to run before BM25 replaced
+// its scoring; ranking itself is now bm25.Score's job.
+func passesBooleanFilters(query searchQuery, metadata *models.Metadata) bool {
 	searchableText := strings.ToLower(fmt.Sprintf("%s %s %s %v",
 		metadata.Topic,
 		metadata.Notes,
@@ -330,58 +589,108 @@ func calculateRelevance(query searchQuery, metadata *models.Metadata) (int, bool
 		metadata.Tags,
 	))
 
-	// Check excluded terms first (must NOT contain)
 	for _, excluded := range query.excluded {
 		if strings.Contains(searchableText, excluded) {
-			return 0, true // shouldExclude
+			return false
 		}
 	}
-
-	// Check required terms (must ALL be present)
 	for _, required := range query.required {
 		if !strings.Contains(searchableText, required) {
-			return 0, true // shouldExclude
+			return false
 		}
 	}
-
-	// Check exact phrases (must ALL be present)
 	for _, phrase := range query.phrases {
 		if !strings.Contains(searchableText, phrase) {
-			return 0, true // shouldExclude
+			return false
 		}
 	}
+	return true
+}
 
-	// Calculate score from normal and required terms
-	score := 0
-	allTerms := append(query.normal, query.required...)
+// bm25Terms collects the distinct lowercase terms a BM25 pass scores
+// against: every required/normal word, plus each individual word inside
+// an exact phrase. A phrase still gates inclusion via
+// passesBooleanFilters; splitting it into words here just lets it also
+// contribute to ranking instead of only to the pass/fail decision.
+func bm25Terms(query searchQuery) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	add := func(words ...string) {
+		for _, w := range words {
+			if w != "" && !seen[w] {
+				seen[w] = true
+				terms = append(terms, w)
+			}
+		}
+	}
+	add(query.required...)
+	add(query.normal...)
+	for _, phrase := range query.phrases {
+		add(strings.Fields(phrase)...)
+	}
+	return terms
+}
 
-	for _, word := range allTerms {
-		// Count occurrences of each query word
-		count := strings.Count(searchableText, word)
-		score += count * 10
+// tokenize lowercases s and splits it into word tokens, discarding
+// punctuation, for BM25 term matching.
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
 
-		// Bonus points for exact matches in topic
-		if strings.Contains(strings.ToLower(metadata.Topic), word) {
-			score += 50
-		}
+// snapshotDoc builds the bm25.Doc for one snapshot's metadata. Field
+// names here (topic, tags, notes, related_branch) must match the keys
+// config.GetBM25FieldWeights() uses.
+func snapshotDoc(branch string, metadata *models.Metadata) bm25.Doc {
+	var tagTokens []string
+	for _, tag := range metadata.Tags {
+		tagTokens = append(tagTokens, tokenize(tag)...)
+	}
+	return bm25.Doc{
+		ID: branch,
+		Fields: map[string][]string{
+			"topic":          tokenize(metadata.Topic),
+			"tags":           tagTokens,
+			"notes":          tokenize(metadata.Notes),
+			"related_branch": tokenize(metadata.RelatedBranch),
+		},
+	}
+}
 
-		// Bonus points for tag matches
-		for _, tag := range metadata.Tags {
-			if strings.Contains(strings.ToLower(tag), word) {
-				score += 30
-			}
+// dfCachePath is the BM25 document-frequency sidecar `context search`
+// reads and writes, mirroring the repo-relative .git/context/* layout
+// casObjectsDir and scheduleLogPath already use.
+func dfCachePath() string {
+	return filepath.Join(".git", "context", "df.json")
+}
+
+// loadOrBuildBM25Stats returns BM25 corpus statistics for terms across
+// docs, reusing the df.json sidecar when the snapshot branch list
+// hasn't changed since it was last written and it already covers every
+// term this search needs. Otherwise it rebuilds stats from docs and
+// re-persists the cache; a failure to read or write the cache file isn't
+// fatal to the search itself.
+func loadOrBuildBM25Stats(branches []string, docs []bm25.Doc, terms []string) *bm25.Stats {
+	path := dfCachePath()
+	generation := bm25.Generation(branches)
+
+	if cached, err := bm25.LoadCache(path); err == nil && cached != nil && cached.Generation == generation {
+		if bm25StatsCoverTerms(cached.Stats, terms) {
+			return cached.Stats
 		}
 	}
 
-	// Bonus for exact phrase matches
-	for _, phrase := range query.phrases {
-		if strings.Contains(searchableText, phrase) {
-			score += 100 // High bonus for exact phrase match
-		}
-		if strings.Contains(strings.ToLower(metadata.Topic), phrase) {
-			score += 150 // Even higher for phrase in topic
+	stats := bm25.BuildStats(docs, terms)
+	_ = bm25.SaveCache(path, &bm25.Cache{Generation: generation, Stats: stats})
+	return stats
+}
+
+func bm25StatsCoverTerms(stats *bm25.Stats, terms []string) bool {
+	for _, t := range terms {
+		if _, ok := stats.TermDF[t]; !ok {
+			return false
 		}
 	}
-
-	return score, false // don't exclude
+	return true
 }
@@ -101,81 +101,100 @@ func TestSearchNoMatches(t *testing.T) {
 	}
 }
 
-func TestCalculateRelevance(t *testing.T) {
+func TestPassesBooleanFilters(t *testing.T) {
 	tests := []struct {
-		name      string
-		query     string
-		metadata  *models.Metadata
-		minScore  int // Minimum expected score
+		name     string
+		query    string
+		metadata *models.Metadata
+		want     bool
 	}{
 		{
-			name:  "exact topic match",
-			query: "security",
+			name:  "required term present",
+			query: "+security",
 			metadata: &models.Metadata{
 				Topic: "security",
 				Notes: "",
 				Tags:  []string{},
 			},
-			minScore: 50, // Bonus for topic match
+			want: true,
 		},
 		{
-			name:  "tag match",
-			query: "bug",
+			name:  "required term missing",
+			query: "+security",
 			metadata: &models.Metadata{
-				Topic: "test",
+				Topic: "performance",
+				Notes: "",
+				Tags:  []string{},
+			},
+			want: false,
+		},
+		{
+			name:  "excluded term present",
+			query: "-deprecated",
+			metadata: &models.Metadata{
+				Topic: "deprecated-api",
 				Notes: "",
-				Tags:  []string{"bug", "feature"},
+				Tags:  []string{},
 			},
-			minScore: 30, // Bonus for tag match
+			want: false,
 		},
 		{
-			name:  "notes match",
-			query: "vulnerability",
+			name:  "exact phrase present",
+			query: `"found vulnerability"`,
 			metadata: &models.Metadata{
 				Topic: "security",
 				Notes: "Found vulnerability in authentication",
 				Tags:  []string{},
 			},
-			minScore: 10, // Word occurrence
+			want: true,
 		},
 		{
-			name:  "multiple word match",
-			query: "security vulnerability",
+			name:  "exact phrase missing",
+			query: `"found vulnerability"`,
 			metadata: &models.Metadata{
 				Topic: "security",
-				Notes: "Found vulnerability in authentication",
-				Tags:  []string{"security"},
+				Notes: "Nothing relevant here",
+				Tags:  []string{},
 			},
-			minScore: 100, // Multiple matches across fields
+			want: false,
 		},
 		{
-			name:  "no match",
+			name:  "normal term never excludes",
 			query: "xyz",
 			metadata: &models.Metadata{
 				Topic: "test",
 				Notes: "Some notes",
 				Tags:  []string{"tag"},
 			},
-			minScore: 0,
+			want: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			parsedQuery := parseSearchQuery(tt.query)
-			score, shouldExclude := calculateRelevance(parsedQuery, tt.metadata)
-
-			if shouldExclude {
-				t.Errorf("unexpected exclusion for query: %s", tt.query)
-			}
-
-			if score < tt.minScore {
-				t.Errorf("expected score >= %d, got %d", tt.minScore, score)
+			if got := passesBooleanFilters(parsedQuery, tt.metadata); got != tt.want {
+				t.Errorf("passesBooleanFilters(%q) = %v, want %v", tt.query, got, tt.want)
 			}
 		})
 	}
 }
 
+func TestBM25TermsDedupesAndSplitsPhrases(t *testing.T) {
+	parsed := parseSearchQuery(`+security "found vulnerability" security`)
+	terms := bm25Terms(parsed)
+
+	want := map[string]bool{"security": true, "found": true, "vulnerability": true}
+	if len(terms) != len(want) {
+		t.Fatalf("expected %d distinct terms, got %v", len(want), terms)
+	}
+	for _, term := range terms {
+		if !want[term] {
+			t.Errorf("unexpected term %q", term)
+		}
+	}
+}
+
 func TestSearchRanking(t *testing.T) {
 	repo := testutil.NewTempGitRepo(t)
 	defer repo.Cleanup()
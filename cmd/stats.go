@@ -64,12 +64,13 @@ type dailyActivity struct {
 }
 
 func runStats(cmd *cobra.Command, args []string) error {
-	if !git.IsGitRepo() {
+	ctx := cmd.Context()
+	if !git.IsGitRepo(ctx) {
 		return fmt.Errorf("not a git repository")
 	}
 
 	// Get all snapshot branches
-	branches, err := git.ListBranches("snapshot/*")
+	branches, err := git.ListBranches(ctx, "snapshot/*")
 	if err != nil {
 		return fmt.Errorf("failed to list branches: %w", err)
 	}
@@ -92,7 +93,7 @@ func runStats(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			continue
 		}
-		info.LoadMetadata()
+		info.LoadMetadata(ctx)
 		snapshots = append(snapshots, info)
 	}
 
@@ -0,0 +1,334 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pders01/git-context/internal/git"
+	"github.com/pders01/git-context/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	tagAdd                 []string
+	tagRemove              []string
+	tagSet                 []string
+	tagFilter              []string
+	tagRegenerateEmbedding bool
+	tagSquash              bool
+)
+
+var tagCmd = &cobra.Command{
+	Use:   "tag [timestamp] [topic]",
+	Short: "Add, remove, or replace tags on existing snapshots",
+	Long: `Mutate the tags on one or more existing snapshots without rewriting
+history: the snapshot branch gains a new commit appending an op_set_tags
+entry to its operation log (see context log), leaving the original
+meta.json blob untouched so TreeHash stays verifiable. context meta and
+context diff show the resolved result.
+
+Single-snapshot mode targets one snapshot by timestamp and topic:
+  context tag 2025-11-14T0930 security-audit --add urgent
+
+Bulk mode targets every snapshot carrying one or more existing tags,
+selected with --tag (repeatable), and skips the positional arguments:
+  context tag --tag draft --add reviewed --remove draft
+
+--set replaces the tag list outright and cannot be combined with --add
+or --remove. If the snapshot has an embedding and its content depends on
+tags (see buildEmbeddingText), pass --regenerate-embedding to recompute
+it after the mutation.
+
+Every mutation so far only appends to the snapshot's operation log (see
+context log); meta.json itself is never rewritten, so a long-lived
+snapshot can accumulate a growing trail of op_set_tags entries. context
+forget, context prune, context list --filter, and context search all
+resolve against this log too, so e.g. "context tag <ts> <topic> --add
+important" followed by "context forget --keep-tag important" preserves
+the snapshot without any further step. Pass --squash (alone, or combined
+with --add/--remove/--set) to fold that log into a fresh meta.json and
+clear ops/, landing it as a single commit once a user wants the resolved
+tags persisted in the blob itself rather than replayed on every read.`,
+	Args: cobra.MaximumNArgs(2),
+	RunE: runTag,
+}
+
+func init() {
+	rootCmd.AddCommand(tagCmd)
+
+	tagCmd.Flags().StringSliceVar(&tagAdd, "add", []string{}, "Tag to add (repeatable)")
+	tagCmd.Flags().StringSliceVar(&tagRemove, "remove", []string{}, "Tag to remove (repeatable)")
+	tagCmd.Flags().StringSliceVar(&tagSet, "set", []string{}, "Replace the tag list entirely (repeatable)")
+	tagCmd.Flags().StringSliceVar(&tagFilter, "tag", []string{}, "Bulk mode: select snapshots carrying this existing tag (repeatable)")
+	tagCmd.Flags().BoolVar(&tagRegenerateEmbedding, "regenerate-embedding", false, "Recompute the snapshot's embedding after mutating tags")
+	tagCmd.Flags().BoolVar(&tagSquash, "squash", false, "Fold the operation log into meta.json and clear ops/")
+}
+
+func runTag(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if !git.IsGitRepo(ctx) {
+		return fmt.Errorf("not a git repository")
+	}
+
+	if len(tagSet) > 0 && (len(tagAdd) > 0 || len(tagRemove) > 0) {
+		return fmt.Errorf("--set cannot be combined with --add or --remove")
+	}
+	if len(tagAdd) == 0 && len(tagRemove) == 0 && len(tagSet) == 0 && !tagSquash {
+		return fmt.Errorf("nothing to do: specify --add, --remove, --set, or --squash")
+	}
+
+	var branches []string
+
+	switch {
+	case len(args) == 2:
+		timestampStr, topic := args[0], args[1]
+		info, err := parseSnapshotBranch(fmt.Sprintf("snapshot/%s/%s", timestampStr, topic))
+		if err != nil {
+			return fmt.Errorf("invalid snapshot: %w", err)
+		}
+		if !git.BranchExists(ctx, info.Branch) {
+			return fmt.Errorf("snapshot branch does not exist: %s", info.Branch)
+		}
+		branches = []string{info.Branch}
+
+	case len(args) == 0:
+		if len(tagFilter) == 0 {
+			return fmt.Errorf("bulk mode requires --tag to select snapshots (or pass <timestamp> <topic> for a single snapshot)")
+		}
+		all, err := git.ListBranches(ctx, "snapshot/*")
+		if err != nil {
+			return fmt.Errorf("failed to list branches: %w", err)
+		}
+		for _, branch := range all {
+			info, err := parseSnapshotBranch(branch)
+			if err != nil {
+				continue
+			}
+			// LoadMetadata resolves the operation log (see context log), so a
+			// tag added by a prior context tag invocation is visible here too,
+			// not just the tags baked into the original meta.json blob.
+			info.LoadMetadata(ctx)
+			if info.Metadata != nil && tagsIntersect(info.Metadata.Tags, tagFilter) {
+				branches = append(branches, branch)
+			}
+		}
+		if len(branches) == 0 {
+			fmt.Println("No snapshots match --tag filter")
+			return nil
+		}
+
+	default:
+		return fmt.Errorf("expected either <timestamp> <topic> or --tag for bulk mode")
+	}
+
+	hasMutation := len(tagAdd) > 0 || len(tagRemove) > 0 || len(tagSet) > 0
+
+	for _, branch := range branches {
+		if hasMutation {
+			if err := retagSnapshot(ctx, branch); err != nil {
+				return fmt.Errorf("failed to update %s: %w", branch, err)
+			}
+			fmt.Printf("✓ Updated tags on %s\n", branch)
+		}
+
+		if tagSquash {
+			if err := squashSnapshotOps(ctx, branch); err != nil {
+				return fmt.Errorf("failed to squash operation log on %s: %w", branch, err)
+			}
+			fmt.Printf("✓ Folded operation log into meta.json on %s\n", branch)
+		}
+	}
+
+	return nil
+}
+
+// squashSnapshotOps resolves branch's base meta.json against its
+// accumulated operation log (see resolvedMetadata) and rewrites meta.json
+// with that resolved view, removing ops/ so the log doesn't linger once
+// its effect has been folded in. Landed as a single commit in a scratch
+// worktree, mirroring retagWithEmbeddingRegen.
+func squashSnapshotOps(ctx context.Context, branch string) error {
+	info, err := parseSnapshotBranch(branch)
+	if err != nil {
+		return err
+	}
+
+	resolved, err := resolvedMetadata(ctx, info, "")
+	if err != nil {
+		return fmt.Errorf("failed to resolve metadata: %w", err)
+	}
+
+	oldSHA, _ := git.RevParseBranch(ctx, branch)
+
+	worktreePath := filepath.Join(os.TempDir(), fmt.Sprintf("context-squash-%d", time.Now().UnixNano()))
+	if err := git.CreateWorktree(ctx, worktreePath, branch); err != nil {
+		return err
+	}
+	defer func() {
+		if err := git.RemoveWorktree(ctx, worktreePath); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to remove worktree: %v\n", err)
+		}
+	}()
+
+	researchPath := models.ResearchPath(info.Timestamp, info.Topic)
+	worktreeResearchPath := filepath.Join(worktreePath, researchPath)
+	metaPath := filepath.Join(worktreeResearchPath, "meta.json")
+	opsPath := filepath.Join(worktreeResearchPath, "ops")
+
+	metaBytes, err := json.MarshalIndent(resolved, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal resolved metadata: %w", err)
+	}
+	if err := os.WriteFile(metaPath, metaBytes, 0644); err != nil {
+		return fmt.Errorf("failed to write metadata: %w", err)
+	}
+	if err := os.RemoveAll(opsPath); err != nil {
+		return fmt.Errorf("failed to remove ops directory: %w", err)
+	}
+
+	if err := git.AddFilesInDir(ctx, worktreePath, researchPath); err != nil {
+		return err
+	}
+
+	commitMsg := fmt.Sprintf("tag: squash operation log for %s", branch)
+	if err := git.CommitInDir(ctx, worktreePath, commitMsg); err != nil {
+		return fmt.Errorf("failed to commit squash: %w", err)
+	}
+
+	if oldSHA != "" {
+		getMetadataCache().Invalidate(oldSHA)
+		getMetadataCache().Save()
+	}
+
+	return nil
+}
+
+// retagSnapshot mutates a single snapshot branch's tags. Ordinarily this
+// appends an op_set_tags entry to the snapshot's operation log (see
+// context log) rather than rewriting meta.json, so the base blob (and
+// TreeHash) stays verifiable. --regenerate-embedding is a documented
+// exception: embeddings aren't part of the op vocabulary, and
+// generateEmbedding's output is entangled with the full Metadata struct,
+// so that path still rewrites meta.json directly in a scratch worktree.
+func retagSnapshot(ctx context.Context, branch string) error {
+	info, err := parseSnapshotBranch(branch)
+	if err != nil {
+		return err
+	}
+
+	resolved, err := resolvedMetadata(ctx, info, "")
+	if err != nil {
+		return fmt.Errorf("failed to load metadata: %w", err)
+	}
+
+	newTags := applyTagMutation(resolved.Tags)
+
+	if tagRegenerateEmbedding && resolved.Embedding != "" {
+		return retagWithEmbeddingRegen(ctx, branch, info, newTags)
+	}
+
+	if err := appendOp(ctx, branch, info, models.Op{Type: models.OpSetTags, Tags: newTags}); err != nil {
+		return fmt.Errorf("failed to append tag op: %w", err)
+	}
+
+	return nil
+}
+
+// retagWithEmbeddingRegen rewrites meta.json directly in a scratch
+// worktree, setting tags and regenerating the embedding together, since
+// buildEmbeddingText may depend on tags. See retagSnapshot for why this
+// bypasses the operation log.
+func retagWithEmbeddingRegen(ctx context.Context, branch string, info snapshotInfo, newTags []string) error {
+	oldSHA, _ := git.RevParseBranch(ctx, branch)
+
+	worktreePath := filepath.Join(os.TempDir(), fmt.Sprintf("context-tag-%d", time.Now().UnixNano()))
+	if err := git.CreateWorktree(ctx, worktreePath, branch); err != nil {
+		return err
+	}
+	defer func() {
+		if err := git.RemoveWorktree(ctx, worktreePath); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to remove worktree: %v\n", err)
+		}
+	}()
+
+	researchPath := models.ResearchPath(info.Timestamp, info.Topic)
+	worktreeResearchPath := filepath.Join(worktreePath, researchPath)
+	metaPath := filepath.Join(worktreeResearchPath, "meta.json")
+
+	metaBytes, err := os.ReadFile(metaPath)
+	if err != nil {
+		return fmt.Errorf("failed to read metadata: %w", err)
+	}
+
+	var metadata models.Metadata
+	if err := json.Unmarshal(metaBytes, &metadata); err != nil {
+		return fmt.Errorf("failed to parse metadata: %w", err)
+	}
+
+	metadata.Tags = newTags
+
+	if err := generateEmbedding(ctx, &metadata, worktreeResearchPath); err != nil {
+		return fmt.Errorf("failed to regenerate embedding: %w", err)
+	}
+
+	if err := git.AddFilesInDir(ctx, worktreePath, researchPath); err != nil {
+		return err
+	}
+
+	commitMsg := fmt.Sprintf("tag: %s\n\nTags: %v", branch, metadata.Tags)
+	if err := git.CommitInDir(ctx, worktreePath, commitMsg); err != nil {
+		return fmt.Errorf("failed to commit tag update: %w", err)
+	}
+
+	if oldSHA != "" {
+		getMetadataCache().Invalidate(oldSHA)
+		getMetadataCache().Save()
+	}
+
+	return nil
+}
+
+// applyTagMutation computes the new tag set from the current flags: --set
+// replaces outright; otherwise --add and --remove are applied to the
+// existing tags, deduplicated and sorted for a stable meta.json diff.
+func applyTagMutation(existing []string) []string {
+	if len(tagSet) > 0 {
+		return dedupSortTags(tagSet)
+	}
+
+	tags := make(map[string]bool, len(existing))
+	for _, t := range existing {
+		tags[t] = true
+	}
+	for _, t := range tagAdd {
+		tags[t] = true
+	}
+	for _, t := range tagRemove {
+		delete(tags, t)
+	}
+
+	result := make([]string, 0, len(tags))
+	for t := range tags {
+		result = append(result, t)
+	}
+	return dedupSortTags(result)
+}
+
+func dedupSortTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	var result []string
+	for _, t := range tags {
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		result = append(result, t)
+	}
+	sort.Strings(result)
+	return result
+}
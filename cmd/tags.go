@@ -1,13 +1,19 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"path"
 	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/alpkeskin/gotoon"
 	"github.com/pders01/git-context/internal/git"
+	"github.com/pders01/git-context/internal/models"
 	"github.com/spf13/cobra"
 )
 
@@ -15,18 +21,49 @@ var (
 	tagsJSON   bool
 	tagsToon   bool
 	tagsRename string
+	tagsDryRun bool
+
+	tagsAdd    []string
+	tagsRemove []string
+	tagsSet    []string
+
+	tagsTopic     string
+	tagsOlderThan string
+	tagsNewerThan string
+	tagsSnapshot  []string
 )
 
+// tagWorkers bounds how many branches --rename or --add/--remove/--set
+// rewrite concurrently. Each worker only runs git plumbing commands (no
+// worktree, no shared mutable state besides the result counters guarded
+// by their own mutex), so there's no correctness reason to serialize them.
+const tagWorkers = 8
+
 var tagsCmd = &cobra.Command{
 	Use:   "tags [old-tag]",
 	Short: "List or manage tags",
 	Long: `List all tags used across snapshots with usage counts.
-Optionally rename tags across all snapshots.
+Optionally rename tags, or add/remove/set tags on a selection of snapshots.
+
+Renaming and bulk tagging both rewrite each affected snapshot branch
+directly via git plumbing (cat-file, hash-object, mktree, commit-tree,
+update-ref) rather than checking out a worktree per branch, and fan the
+rewrite out across snapshots concurrently. Pass --dry-run to see what
+would change without writing anything.
+
+--add/--remove/--set require a selector to avoid accidentally touching
+every snapshot: --topic <glob>, --older-than/--newer-than <duration>
+(e.g. 30d, 2w, 6m), or explicit --snapshot <timestamp>/<topic>
+(repeatable). Selectors combine with AND.
 
 Examples:
   context tags                    # List all tags
   context tags --rename new-name  # Rename tag (requires tag argument)
-  context tags security --rename important-security`,
+  context tags security --rename important-security
+  context tags security --rename important-security --dry-run
+  context tags --add urgent --topic 'incident-*'
+  context tags --remove wip --older-than 30d
+  context tags --set final --snapshot 2025-11-14T0930/security-audit`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runTags,
 }
@@ -36,7 +73,17 @@ func init() {
 
 	tagsCmd.Flags().BoolVar(&tagsJSON, "json", false, "Output as JSON")
 	tagsCmd.Flags().BoolVar(&tagsToon, "toon", false, "Output in LLM-friendly toon format")
+	tagsCmd.Flags().BoolVar(&tagsDryRun, "dry-run", false, "Show what --rename or --add/--remove/--set would affect without writing anything")
 	tagsCmd.Flags().StringVar(&tagsRename, "rename", "", "Rename tag to new value")
+
+	tagsCmd.Flags().StringSliceVar(&tagsAdd, "add", []string{}, "Add tag(s) to snapshots matching the selector (repeatable, comma-separated)")
+	tagsCmd.Flags().StringSliceVar(&tagsRemove, "remove", []string{}, "Remove tag(s) from snapshots matching the selector (repeatable, comma-separated)")
+	tagsCmd.Flags().StringSliceVar(&tagsSet, "set", []string{}, "Replace the tag set of snapshots matching the selector (repeatable, comma-separated)")
+
+	tagsCmd.Flags().StringVar(&tagsTopic, "topic", "", "Selector: only snapshots whose topic matches this glob")
+	tagsCmd.Flags().StringVar(&tagsOlderThan, "older-than", "", "Selector: only snapshots older than this duration (e.g. 30d)")
+	tagsCmd.Flags().StringVar(&tagsNewerThan, "newer-than", "", "Selector: only snapshots newer than this duration (e.g. 7d)")
+	tagsCmd.Flags().StringSliceVar(&tagsSnapshot, "snapshot", []string{}, "Selector: explicit snapshot <timestamp>/<topic> (repeatable)")
 }
 
 type tagInfo struct {
@@ -45,20 +92,40 @@ type tagInfo struct {
 }
 
 func runTags(cmd *cobra.Command, args []string) error {
-	if !git.IsGitRepo() {
+	ctx := cmd.Context()
+	if !git.IsGitRepo(ctx) {
 		return fmt.Errorf("not a git repository")
 	}
 
+	mutation := tagMutation{add: tagsAdd, remove: tagsRemove, set: tagsSet}
+	hasMutation := len(mutation.add) > 0 || len(mutation.remove) > 0 || len(mutation.set) > 0
+
+	if tagsRename != "" && hasMutation {
+		return fmt.Errorf("--rename cannot be combined with --add/--remove/--set")
+	}
+
 	// Handle rename mode
 	if tagsRename != "" {
 		if len(args) == 0 {
 			return fmt.Errorf("tag name required for --rename")
 		}
-		return renameTag(args[0], tagsRename)
+		return renameTag(ctx, args[0], tagsRename)
+	}
+
+	// Handle bulk add/remove/set mode
+	if hasMutation {
+		sel, err := buildTagSelector()
+		if err != nil {
+			return err
+		}
+		if sel.empty() {
+			return fmt.Errorf("--add/--remove/--set requires a selector: --topic, --older-than, --newer-than, or --snapshot")
+		}
+		return runMutateTags(ctx, mutation, sel)
 	}
 
 	// List mode
-	branches, err := git.ListBranches("snapshot/*")
+	branches, err := git.ListBranches(ctx, "snapshot/*")
 	if err != nil {
 		return fmt.Errorf("failed to list branches: %w", err)
 	}
@@ -76,7 +143,7 @@ func runTags(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			continue
 		}
-		info.LoadMetadata()
+		info.LoadMetadata(ctx)
 
 		if info.Metadata != nil {
 			for _, tag := range info.Metadata.Tags {
@@ -131,83 +198,448 @@ func runTags(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func renameTag(oldTag, newTag string) error {
-	branches, err := git.ListBranches("snapshot/*")
+// renameTag rewrites oldTag to newTag across every snapshot branch that
+// carries it, fanning the per-branch rewrite out across tagWorkers
+// goroutines. Each rewrite is pure git plumbing (see renameTagOnBranch) —
+// no worktree is ever created, so there's nothing to clean up if the
+// command is cancelled mid-run.
+func renameTag(ctx context.Context, oldTag, newTag string) error {
+	branches, err := git.ListBranches(ctx, "snapshot/*")
 	if err != nil {
 		return fmt.Errorf("failed to list branches: %w", err)
 	}
 
+	branchCh := make(chan string)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
 	updated := 0
+	var cancelled error
+
+	for i := 0; i < tagWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for branch := range branchCh {
+				if err := ctx.Err(); err != nil {
+					mu.Lock()
+					cancelled = err
+					mu.Unlock()
+					continue
+				}
+
+				ok, err := renameTagOnBranch(ctx, branch, oldTag, newTag, tagsDryRun)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+					continue
+				}
+				if ok {
+					mu.Lock()
+					updated++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
 
 	for _, branch := range branches {
-		info, err := parseSnapshotBranch(branch)
-		if err != nil {
-			continue
+		if ctx.Err() != nil {
+			break
 		}
-		info.LoadMetadata()
+		branchCh <- branch
+	}
+	close(branchCh)
+	wg.Wait()
 
-		if info.Metadata == nil {
-			continue
+	if cancelled != nil {
+		fmt.Printf("Cancelled: %v (renamed '%s' → '%s' in %d snapshot(s) so far)\n", cancelled, oldTag, newTag, updated)
+		return cancelled
+	}
+
+	verb := "Renamed"
+	if tagsDryRun {
+		verb = "Would rename"
+	}
+	fmt.Printf("%s tag '%s' → '%s' in %d snapshot(s)\n", verb, oldTag, newTag, updated)
+	return nil
+}
+
+// renameTagOnBranch rewrites oldTag to newTag in a single snapshot's
+// metadata using pure git plumbing (see rewriteSnapshotTags). It returns
+// false, nil for a snapshot that doesn't carry oldTag at all. With
+// dryRun set, it reports whether the branch would be renamed without
+// writing any new objects.
+func renameTagOnBranch(ctx context.Context, branch, oldTag, newTag string, dryRun bool) (bool, error) {
+	info, err := parseSnapshotBranch(branch)
+	if err != nil {
+		return false, nil
+	}
+	info.LoadMetadata(ctx)
+
+	if info.Metadata == nil {
+		return false, nil
+	}
+
+	newTags := append([]string(nil), info.Metadata.Tags...)
+	hasTag := false
+	for i, tag := range newTags {
+		if tag == oldTag {
+			newTags[i] = newTag
+			hasTag = true
 		}
+	}
+	if !hasTag {
+		return false, nil
+	}
 
-		// Check if this snapshot has the old tag
-		hasTag := false
-		for i, tag := range info.Metadata.Tags {
-			if tag == oldTag {
-				info.Metadata.Tags[i] = newTag
-				hasTag = true
-			}
+	if dryRun {
+		return true, nil
+	}
+
+	commitMsg := fmt.Sprintf("Rename tag: %s → %s", oldTag, newTag)
+	if err := rewriteSnapshotTags(ctx, branch, info, newTags, commitMsg); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// rewriteSnapshotTags replaces info's tag set with newTags and lands the
+// change as a new commit on branch via pure git plumbing — cat-file to
+// read the current blob (already done by LoadMetadata), hash-object to
+// write the edited one, ls-tree/mktree to rebuild every tree on the path
+// to it, commit-tree to commit, update-ref to land it — so many branches
+// can be rewritten concurrently with no worktree, no chdir, and no
+// filesystem side effects.
+func rewriteSnapshotTags(ctx context.Context, branch string, info snapshotInfo, newTags []string, commitMsg string) error {
+	info.Metadata.Tags = newTags
+
+	metaBytes, err := json.MarshalIndent(info.Metadata, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata for %s: %w", branch, err)
+	}
+
+	newBlob, err := git.WriteBlob(ctx, metaBytes)
+	if err != nil {
+		return fmt.Errorf("failed to write metadata blob for %s: %w", branch, err)
+	}
+
+	parent, err := git.RevParseBranch(ctx, branch)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", branch, err)
+	}
+
+	metaPath := models.MetadataPath(info.Timestamp, info.Topic)
+	newTree, err := git.ReplaceBlobInTree(ctx, branch, metaPath, newBlob)
+	if err != nil {
+		return fmt.Errorf("failed to rebuild tree for %s: %w", branch, err)
+	}
+
+	newCommit, err := git.CommitTreeOrphan(ctx, newTree, commitMsg, parent)
+	if err != nil {
+		return fmt.Errorf("failed to commit tree for %s: %w", branch, err)
+	}
+
+	if err := git.UpdateRef(ctx, "refs/heads/"+branch, newCommit); err != nil {
+		return fmt.Errorf("failed to update ref for %s: %w", branch, err)
+	}
+
+	return nil
+}
+
+// tagMutation describes a bulk --add/--remove/--set request. When set is
+// non-empty it wins outright and replaces a snapshot's tag set; otherwise
+// remove is applied before add, so "--add x --remove x" ends with x present.
+type tagMutation struct {
+	add    []string
+	remove []string
+	set    []string
+}
+
+func (m tagMutation) apply(tags []string) []string {
+	if len(m.set) > 0 {
+		return sortedUniqueTags(m.set)
+	}
+
+	out := append([]string(nil), tags...)
+	for _, t := range m.remove {
+		out = removeTag(out, t)
+	}
+	for _, t := range m.add {
+		if !containsTag(out, t) {
+			out = append(out, t)
 		}
+	}
+	return sortedUniqueTags(out)
+}
 
-		if !hasTag {
-			continue
+func containsTag(tags []string, t string) bool {
+	for _, x := range tags {
+		if x == t {
+			return true
 		}
+	}
+	return false
+}
 
-		// Update metadata file using git operations
-		metaPath := fmt.Sprintf("research/%s/%s/meta.json",
-			info.Timestamp.Format("2006-01-02T1504"), info.Topic)
+func removeTag(tags []string, t string) []string {
+	out := tags[:0:0]
+	for _, x := range tags {
+		if x != t {
+			out = append(out, x)
+		}
+	}
+	return out
+}
+
+func sortedUniqueTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	out := tags[:0:0]
+	for _, t := range tags {
+		if !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
 
-		// Marshal updated metadata
-		metaBytes, err := json.MarshalIndent(info.Metadata, "", "  ")
+func tagsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// tagSelector narrows which snapshot branches a bulk tag mutation applies
+// to. Every configured field must match (AND, not OR); an empty selector
+// matches nothing (see empty) so --add/--remove/--set can't silently
+// touch every snapshot.
+type tagSelector struct {
+	topic     string
+	olderThan time.Duration
+	newerThan time.Duration
+	snapshots map[string]bool
+}
+
+func (sel tagSelector) empty() bool {
+	return sel.topic == "" && sel.olderThan == 0 && sel.newerThan == 0 && len(sel.snapshots) == 0
+}
+
+func (sel tagSelector) matches(info snapshotInfo) bool {
+	if sel.topic != "" {
+		if ok, _ := path.Match(sel.topic, info.Topic); !ok {
+			return false
+		}
+	}
+
+	age := time.Since(info.Timestamp)
+	if sel.olderThan > 0 && age < sel.olderThan {
+		return false
+	}
+	if sel.newerThan > 0 && age > sel.newerThan {
+		return false
+	}
+
+	if len(sel.snapshots) > 0 && !sel.snapshots[info.Branch] {
+		return false
+	}
+
+	return true
+}
+
+func buildTagSelector() (tagSelector, error) {
+	sel := tagSelector{topic: tagsTopic}
+
+	if tagsOlderThan != "" {
+		d, err := parseRetentionDuration(tagsOlderThan)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to marshal metadata for %s: %v\n", branch, err)
-			continue
+			return tagSelector{}, fmt.Errorf("invalid --older-than duration: %w", err)
 		}
+		sel.olderThan = d
+	}
 
-		// Create temporary worktree
-		tmpDir := fmt.Sprintf("/tmp/context-tag-rename-%d", info.Timestamp.Unix())
-		if err := git.CreateWorktree(tmpDir, branch); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to create worktree for %s: %v\n", branch, err)
-			continue
+	if tagsNewerThan != "" {
+		d, err := parseRetentionDuration(tagsNewerThan)
+		if err != nil {
+			return tagSelector{}, fmt.Errorf("invalid --newer-than duration: %w", err)
 		}
+		sel.newerThan = d
+	}
 
-		// Update file
-		fullPath := fmt.Sprintf("%s/%s", tmpDir, metaPath)
-		if err := os.WriteFile(fullPath, metaBytes, 0644); err != nil {
-			git.RemoveWorktree(tmpDir)
-			fmt.Fprintf(os.Stderr, "Warning: failed to write metadata for %s: %v\n", branch, err)
-			continue
+	if len(tagsSnapshot) > 0 {
+		branches, err := parseSnapshotSelector(tagsSnapshot)
+		if err != nil {
+			return tagSelector{}, err
 		}
+		sel.snapshots = branches
+	}
 
-		// Commit change
-		if err := git.AddFilesInDir(tmpDir, metaPath); err != nil {
-			git.RemoveWorktree(tmpDir)
-			fmt.Fprintf(os.Stderr, "Warning: failed to add file for %s: %v\n", branch, err)
-			continue
+	return sel, nil
+}
+
+// parseSnapshotSelector turns repeatable --snapshot <timestamp>/<topic>
+// values into the branch-name set a tagSelector matches against.
+func parseSnapshotSelector(values []string) (map[string]bool, error) {
+	branches := make(map[string]bool, len(values))
+	for _, v := range values {
+		parts := strings.SplitN(v, "/", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid --snapshot %q (want <timestamp>/<topic>)", v)
 		}
+		ts, err := time.Parse("2006-01-02T1504", parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid --snapshot timestamp %q: %w", parts[0], err)
+		}
+		branches[models.BranchName(ts, parts[1])] = true
+	}
+	return branches, nil
+}
 
-		commitMsg := fmt.Sprintf("Rename tag: %s → %s", oldTag, newTag)
-		if err := git.CommitInDirNoVerify(tmpDir, commitMsg); err != nil {
-			git.RemoveWorktree(tmpDir)
-			fmt.Fprintf(os.Stderr, "Warning: failed to commit for %s: %v\n", branch, err)
-			continue
+// tagMutationResult summarizes what --add/--remove/--set did (or would
+// do, under --dry-run) to a single snapshot branch.
+type tagMutationResult struct {
+	Branch  string   `json:"branch"`
+	Before  []string `json:"before"`
+	After   []string `json:"after"`
+	Skipped bool     `json:"skipped"`
+}
+
+// runMutateTags applies mutation to every snapshot branch matching sel,
+// fanning the per-branch rewrite out across tagWorkers goroutines the
+// same way renameTag does, then prints a summary table of what changed.
+func runMutateTags(ctx context.Context, mutation tagMutation, sel tagSelector) error {
+	branches, err := git.ListBranches(ctx, "snapshot/*")
+	if err != nil {
+		return fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	branchCh := make(chan string)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var results []tagMutationResult
+
+	for i := 0; i < tagWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for branch := range branchCh {
+				if ctx.Err() != nil {
+					continue
+				}
+
+				result, err := mutateTagsOnBranch(ctx, branch, sel, mutation, tagsDryRun)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+					continue
+				}
+				if result == nil {
+					continue
+				}
+
+				mu.Lock()
+				results = append(results, *result)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, branch := range branches {
+		if ctx.Err() != nil {
+			break
 		}
+		branchCh <- branch
+	}
+	close(branchCh)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Branch < results[j].Branch })
+	return printTagMutationResults(results)
+}
+
+// mutateTagsOnBranch applies mutation to a single snapshot branch's tag
+// set if it matches sel, returning nil, nil for a branch that doesn't.
+// With dryRun set, it reports what would change without writing anything.
+func mutateTagsOnBranch(ctx context.Context, branch string, sel tagSelector, mutation tagMutation, dryRun bool) (*tagMutationResult, error) {
+	info, err := parseSnapshotBranch(branch)
+	if err != nil {
+		return nil, nil
+	}
+	if !sel.matches(info) {
+		return nil, nil
+	}
+	info.LoadMetadata(ctx)
+	if info.Metadata == nil {
+		return nil, nil
+	}
+
+	before := sortedUniqueTags(append([]string(nil), info.Metadata.Tags...))
+	after := mutation.apply(info.Metadata.Tags)
 
-		// Cleanup
-		git.RemoveWorktree(tmpDir)
-		updated++
+	if tagsEqual(before, after) {
+		return &tagMutationResult{Branch: branch, Before: before, After: after, Skipped: true}, nil
 	}
 
-	fmt.Printf("Renamed tag '%s' → '%s' in %d snapshot(s)\n", oldTag, newTag, updated)
+	if dryRun {
+		return &tagMutationResult{Branch: branch, Before: before, After: after}, nil
+	}
+
+	commitMsg := fmt.Sprintf("Update tags: %s", strings.Join(after, ", "))
+	if err := rewriteSnapshotTags(ctx, branch, info, after, commitMsg); err != nil {
+		return nil, err
+	}
+
+	return &tagMutationResult{Branch: branch, Before: before, After: after}, nil
+}
+
+func printTagMutationResults(results []tagMutationResult) error {
+	if len(results) == 0 {
+		fmt.Println("No snapshots matched the selector")
+		return nil
+	}
+
+	if tagsJSON {
+		output, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(output))
+		return nil
+	}
+
+	if tagsToon {
+		output, err := gotoon.Encode(results)
+		if err != nil {
+			return fmt.Errorf("failed to encode Toon: %w", err)
+		}
+		fmt.Println(output)
+		return nil
+	}
+
+	changed, skipped := 0, 0
+	for _, r := range results {
+		if r.Skipped {
+			skipped++
+			fmt.Printf("  skip: %-50s %v (already up to date)\n", r.Branch, r.After)
+			continue
+		}
+		changed++
+		fmt.Printf("  %-50s %v -> %v\n", r.Branch, r.Before, r.After)
+	}
+
+	verb := "Updated"
+	if tagsDryRun {
+		verb = "Would update"
+	}
+	fmt.Printf("\n%s %d snapshot(s), skipped %d already up to date\n", verb, changed, skipped)
 	return nil
 }
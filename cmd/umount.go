@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+
+	"github.com/spf13/cobra"
+)
+
+var umountCmd = &cobra.Command{
+	Use:   "umount <mountpoint>",
+	Short: "Unmount a filesystem mounted with `context mount`",
+	Long: `Unmount a context FUSE mount. This is a thin wrapper around the
+platform's unmount command (umount on macOS, fusermount elsewhere) so you
+don't need to remember which one applies.
+
+You can also just Ctrl-C the "context mount" process itself.
+
+Example:
+  context umount /mnt/context`,
+	Args: cobra.ExactArgs(1),
+	RunE: runUmount,
+}
+
+func init() {
+	rootCmd.AddCommand(umountCmd)
+}
+
+func runUmount(cmd *cobra.Command, args []string) error {
+	mountpoint := args[0]
+
+	var unmount *exec.Cmd
+	if runtime.GOOS == "darwin" {
+		unmount = exec.Command("umount", mountpoint)
+	} else {
+		unmount = exec.Command("fusermount", "-u", mountpoint)
+	}
+
+	if output, err := unmount.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to unmount %s: %w\n%s", mountpoint, err, output)
+	}
+
+	fmt.Printf("Unmounted %s\n", mountpoint)
+	return nil
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/pders01/git-context/internal/config"
+	"github.com/pders01/git-context/internal/git"
+	"github.com/pders01/git-context/internal/models"
+	"github.com/pders01/git-context/internal/signing"
+	"github.com/spf13/cobra"
+)
+
+var (
+	verifyKey    string
+	verifyFormat string
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify <timestamp> <topic>",
+	Short: "Verify a snapshot's signature",
+	Long: `Recompute a signed snapshot's manifest and check it, and the snapshot
+commit itself, against the signatures created by 'context save --sign'.
+
+Checks two independent signatures:
+  - the detached manifest (tree hash, main commit, embedding) against
+    research/<timestamp>/<topic>/manifest.sig
+  - the snapshot commit object itself, re-signed by save into a gpgsig
+    header the way 'git commit -S' would
+
+The signature format defaults to the snapshot's own metadata.sign_format,
+and can be overridden with --sign-format.
+
+Example:
+  context verify 2025-11-14T0930 security-audit --verify-key ~/.ssh/allowed_signers`,
+	Args: cobra.ExactArgs(2),
+	RunE: runVerify,
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+	verifyCmd.Flags().StringVar(&verifyKey, "verify-key", "", "Verification key path (overrides signing.key config)")
+	verifyCmd.Flags().StringVar(&verifyFormat, "sign-format", "", "Signature format: gpg|ssh|x509 (overrides metadata.sign_format)")
+}
+
+func runVerify(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if !git.IsGitRepo(ctx) {
+		return fmt.Errorf("not a git repository")
+	}
+
+	info, err := parseSnapshotBranch(fmt.Sprintf("snapshot/%s/%s", args[0], args[1]))
+	if err != nil {
+		return fmt.Errorf("invalid snapshot: %w", err)
+	}
+	branch := info.Branch
+
+	if !git.BranchExists(ctx, branch) {
+		return fmt.Errorf("snapshot branch does not exist: %s", branch)
+	}
+
+	metadataPtr, err := resolvedMetadata(ctx, info, "")
+	if err != nil {
+		return err
+	}
+	metadata := *metadataPtr
+
+	format := verifyFormat
+	if format == "" {
+		format = metadata.SignFormat
+	}
+	if format == "" {
+		format = config.GetSigningFormat()
+	}
+
+	key := verifyKey
+	if key == "" {
+		key = config.GetSigningKey()
+	}
+
+	verifier, err := signing.NewVerifier(signing.Format(format), key)
+	if err != nil {
+		return fmt.Errorf("failed to initialize verifier: %w", err)
+	}
+
+	researchPath := models.ResearchPath(info.Timestamp, info.Topic)
+
+	manifest := signing.Manifest{
+		TreeHash:   metadata.TreeHash,
+		MainCommit: metadata.MainCommit,
+		CreatedAt:  metadata.CreatedAt,
+	}
+	if metadata.Embedding != "" {
+		embeddingContent, err := gitShow(ctx, branch, researchPath+"/"+metadata.Embedding)
+		if err != nil {
+			return fmt.Errorf("failed to read embedding %s: %w", metadata.Embedding, err)
+		}
+		manifest.Embedding = []byte(embeddingContent)
+	}
+
+	sigContent, err := gitShow(ctx, branch, researchPath+"/manifest.sig")
+	if err != nil {
+		return fmt.Errorf("failed to read manifest.sig: %w", err)
+	}
+
+	manifestIdentity, err := signing.VerifyManifest(verifier, manifest, []byte(sigContent))
+	if err != nil {
+		return fmt.Errorf("manifest signature invalid: %w", err)
+	}
+	fmt.Printf("✓ Manifest signature valid (%s)\n", manifestIdentity)
+
+	raw, err := git.CatFileCommit(ctx, branch)
+	if err != nil {
+		return fmt.Errorf("failed to read commit %s: %w", branch, err)
+	}
+	stripped, sig, ok := signing.ExtractGPGSig(raw)
+	if !ok {
+		return fmt.Errorf("snapshot commit %s has no embedded signature", branch)
+	}
+	commitIdentity, err := verifier.Verify([]byte(stripped), sig)
+	if err != nil {
+		return fmt.Errorf("commit signature invalid: %w", err)
+	}
+	fmt.Printf("✓ Commit signature valid (%s)\n", commitIdentity)
+
+	return nil
+}
@@ -0,0 +1,298 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/alpkeskin/gotoon"
+	"github.com/pders01/git-context/internal/git"
+	"github.com/pders01/git-context/internal/models"
+	"github.com/pders01/git-context/internal/xref"
+	"github.com/spf13/cobra"
+)
+
+// xrefRef is the ref the cross-reference index is stored under. It lives
+// outside refs/heads entirely, so it never shows up as a checkout-able
+// branch and its history is independent of any snapshot.
+const xrefRef = "refs/context-xrefs/index"
+
+var (
+	xrefJSON    bool
+	xrefToon    bool
+	xrefGraph   string
+	xrefReindex bool
+)
+
+var xrefCmd = &cobra.Command{
+	Use:   "xref [timestamp] [topic]",
+	Short: "Show cross-references between snapshots",
+	Long: `List which snapshots a given snapshot mentions, and which snapshots
+mention it back, by scanning notes.md and research/ Markdown files for
+references to other snapshots: either a full branch name
+(snapshot/2025-11-14T0930/security-audit) or the shorthand
+#topic@timestamp syntax. A metadata.RelatedBranch pointing at another
+snapshot branch is picked up automatically as a related_branch edge.
+
+The index is stored outside any snapshot branch, at refs/context-xrefs/index,
+and is updated incrementally every time context save creates a snapshot.
+Use --reindex to rebuild it from scratch after editing notes by hand.
+--graph renders the result as a Graphviz DOT or Mermaid diagram instead
+of a list.
+
+Examples:
+  context xref 2025-11-14T0930 security-audit
+  context xref 2025-11-14T0930 security-audit --graph dot
+  context xref --reindex`,
+	Args: cobra.MaximumNArgs(2),
+	RunE: runXref,
+}
+
+func init() {
+	rootCmd.AddCommand(xrefCmd)
+
+	xrefCmd.Flags().BoolVar(&xrefJSON, "json", false, "Output as JSON")
+	xrefCmd.Flags().BoolVar(&xrefToon, "toon", false, "Output in LLM-friendly toon format")
+	xrefCmd.Flags().StringVar(&xrefGraph, "graph", "", "Render as a graph instead of a list: dot|mermaid")
+	xrefCmd.Flags().BoolVar(&xrefReindex, "reindex", false, "Rebuild the cross-reference index from scratch")
+}
+
+func runXref(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if !git.IsGitRepo(ctx) {
+		return fmt.Errorf("not a git repository")
+	}
+
+	if xrefReindex {
+		if len(args) != 0 {
+			return fmt.Errorf("--reindex rebuilds the whole index and takes no snapshot arguments")
+		}
+		idx, err := reindexXrefs(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to reindex: %w", err)
+		}
+		fmt.Printf("✓ Reindexed %d cross-reference edge(s)\n", len(idx.Edges))
+		return nil
+	}
+
+	if len(args) != 2 {
+		return fmt.Errorf("expected <timestamp> <topic> (or --reindex)")
+	}
+
+	branch := fmt.Sprintf("snapshot/%s/%s", args[0], args[1])
+	if !git.BranchExists(ctx, branch) {
+		return fmt.Errorf("snapshot branch does not exist: %s", branch)
+	}
+
+	idx, err := loadXrefIndex(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load xref index: %w", err)
+	}
+
+	mentions := idx.MentionsOf(branch)
+	mentionedBy := idx.MentionedBy(branch)
+
+	if xrefGraph != "" {
+		switch xrefGraph {
+		case "dot":
+			fmt.Print(xref.DOT(branch, mentions, mentionedBy))
+		case "mermaid":
+			fmt.Print(xref.Mermaid(branch, mentions, mentionedBy))
+		default:
+			return fmt.Errorf("unknown --graph format: %s (must be dot or mermaid)", xrefGraph)
+		}
+		return nil
+	}
+
+	type xrefOutput struct {
+		Branch      string      `json:"branch"`
+		Mentions    []xref.Edge `json:"mentions"`
+		MentionedBy []xref.Edge `json:"mentioned_by"`
+	}
+	output := xrefOutput{Branch: branch, Mentions: mentions, MentionedBy: mentionedBy}
+
+	if xrefJSON {
+		jsonBytes, err := json.MarshalIndent(output, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(jsonBytes))
+		return nil
+	}
+
+	if xrefToon {
+		toonOutput, err := gotoon.Encode(output)
+		if err != nil {
+			return fmt.Errorf("failed to encode Toon: %w", err)
+		}
+		fmt.Println(toonOutput)
+		return nil
+	}
+
+	if len(mentions) == 0 && len(mentionedBy) == 0 {
+		fmt.Printf("No cross-references found for %s\n", branch)
+		return nil
+	}
+
+	if len(mentions) > 0 {
+		fmt.Printf("Mentions (%d):\n", len(mentions))
+		for _, e := range mentions {
+			fmt.Printf("  -> %s [%s]\n", e.To, e.Kind)
+		}
+		fmt.Println()
+	}
+
+	if len(mentionedBy) > 0 {
+		fmt.Printf("Mentioned by (%d):\n", len(mentionedBy))
+		for _, e := range mentionedBy {
+			fmt.Printf("  <- %s [%s]\n", e.From, e.Kind)
+		}
+	}
+
+	return nil
+}
+
+// loadXrefIndex reads the cross-reference index from refs/context-xrefs/index,
+// returning an empty index if it hasn't been built yet.
+func loadXrefIndex(ctx context.Context) (xref.Index, error) {
+	if !git.RefExists(ctx, xrefRef) {
+		return xref.Index{}, nil
+	}
+
+	content, err := gitShow(ctx, xrefRef, "index.json")
+	if err != nil {
+		return xref.Index{}, fmt.Errorf("failed to read xref index: %w", err)
+	}
+
+	var idx xref.Index
+	if err := json.Unmarshal([]byte(content), &idx); err != nil {
+		return xref.Index{}, fmt.Errorf("failed to parse xref index: %w", err)
+	}
+	return idx, nil
+}
+
+// saveXrefIndex commits idx as the new root-less history of
+// refs/context-xrefs/index: a single index.json blob, written via plumbing
+// rather than a worktree since there's nothing here to check out.
+func saveXrefIndex(ctx context.Context, idx xref.Index, message string) error {
+	content, err := json.MarshalIndent(&idx, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal xref index: %w", err)
+	}
+
+	blobSHA, err := git.WriteBlob(ctx, content)
+	if err != nil {
+		return err
+	}
+
+	treeSHA, err := git.MakeTreeSingleFile(ctx, "index.json", blobSHA)
+	if err != nil {
+		return err
+	}
+
+	var parent string
+	if git.RefExists(ctx, xrefRef) {
+		parent, _ = git.RevParseBranch(ctx, xrefRef)
+	}
+
+	commitSHA, err := git.CommitTreeOrphan(ctx, treeSHA, message, parent)
+	if err != nil {
+		return err
+	}
+
+	return git.UpdateRef(ctx, xrefRef, commitSHA)
+}
+
+// scanSnapshotEdges scans a single snapshot's Markdown research files for
+// mentions of other existing snapshots, and checks whether its resolved
+// metadata.RelatedBranch points at another snapshot branch.
+func scanSnapshotEdges(ctx context.Context, branch string) ([]xref.Edge, error) {
+	info, err := parseSnapshotBranch(branch)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[xref.Edge]bool)
+	var edges []xref.Edge
+	addEdge := func(to string, kind xref.EdgeKind) {
+		if to == branch {
+			return
+		}
+		e := xref.Edge{From: branch, To: to, Kind: kind}
+		if !seen[e] {
+			seen[e] = true
+			edges = append(edges, e)
+		}
+	}
+
+	names, err := git.ListTreeRecursive(ctx, branch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tree: %w", err)
+	}
+
+	prefix := models.ResearchPath(info.Timestamp, info.Topic) + "/"
+	for _, name := range names {
+		if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ".md") {
+			continue
+		}
+		content, err := gitShow(ctx, branch, name)
+		if err != nil {
+			continue
+		}
+		for _, ref := range xref.ScanReferences(content) {
+			if git.BranchExists(ctx, ref) {
+				addEdge(ref, xref.KindMention)
+			}
+		}
+	}
+
+	if metadata, err := resolvedMetadata(ctx, info, ""); err == nil {
+		if strings.HasPrefix(metadata.RelatedBranch, "snapshot/") && git.BranchExists(ctx, metadata.RelatedBranch) {
+			addEdge(metadata.RelatedBranch, xref.KindRelatedBranch)
+		}
+	}
+
+	return edges, nil
+}
+
+// reindexXrefs rebuilds the whole cross-reference index from scratch by
+// scanning every snapshot branch, replacing whatever was there before.
+func reindexXrefs(ctx context.Context) (xref.Index, error) {
+	branches, err := git.ListBranches(ctx, "snapshot/*")
+	if err != nil {
+		return xref.Index{}, fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	var idx xref.Index
+	for _, branch := range branches {
+		edges, err := scanSnapshotEdges(ctx, branch)
+		if err != nil {
+			return xref.Index{}, fmt.Errorf("failed to scan %s: %w", branch, err)
+		}
+		idx = idx.Merge(edges)
+	}
+
+	if err := saveXrefIndex(ctx, idx, "xref: reindex"); err != nil {
+		return xref.Index{}, err
+	}
+	return idx, nil
+}
+
+// updateXrefIndexForSnapshot rescans a single snapshot (typically one
+// context save just created) and merges its edges into the existing
+// index, replacing any stale edges it previously contributed.
+func updateXrefIndexForSnapshot(ctx context.Context, branch string) error {
+	idx, err := loadXrefIndex(ctx)
+	if err != nil {
+		return err
+	}
+
+	edges, err := scanSnapshotEdges(ctx, branch)
+	if err != nil {
+		return err
+	}
+
+	idx = idx.WithoutSnapshot(branch).Merge(edges)
+	return saveXrefIndex(ctx, idx, fmt.Sprintf("xref: update for %s", branch))
+}
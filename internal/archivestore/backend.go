@@ -0,0 +1,64 @@
+// Package archivestore lets context archive and context restore stream a
+// snapshot archive to and from a pluggable remote, rather than always
+// going through the local filesystem first.
+package archivestore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// Backend is a single flat namespace an archive can be written to, listed,
+// read back from, or deleted from. Every scheme below keys its objects by
+// plain name, matching how local file archives have always been named.
+type Backend interface {
+	Write(ctx context.Context, name string, r io.Reader) error
+	List(ctx context.Context) ([]string, error)
+	Get(ctx context.Context, name string) (io.ReadCloser, error)
+	Delete(ctx context.Context, name string) error
+	Close() error
+}
+
+// Open parses rawURL into a Backend and the object name to use against it.
+// A bare local path (no scheme, e.g. "backup.tar.gz" or "/tmp/x.tar.gz")
+// and an explicit file:// URL both resolve to the file backend, rooted at
+// the path's directory - this is what keeps context archive's historical
+// --output behavior working unchanged.
+func Open(rawURL string) (Backend, string, error) {
+	if !strings.Contains(rawURL, "://") {
+		return newFileBackend(filepath.Dir(rawURL)), filepath.Base(rawURL), nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid archive URL %q: %w", rawURL, err)
+	}
+
+	switch u.Scheme {
+	case "file":
+		return newFileBackend(filepath.Dir(u.Path)), filepath.Base(u.Path), nil
+	case "s3":
+		return newS3Backend(u)
+	case "gcs":
+		return newGCSBackend(u)
+	case "ssh":
+		return newSSHBackend(u)
+	default:
+		return nil, "", fmt.Errorf("unsupported archive backend scheme %q (want file, s3, gcs, or ssh)", u.Scheme)
+	}
+}
+
+// splitObjectPath splits a URL path into its directory (used as a bucket
+// prefix or remote directory) and base name (the object key).
+func splitObjectPath(p string) (dir, name string) {
+	p = strings.TrimPrefix(p, "/")
+	idx := strings.LastIndex(p, "/")
+	if idx < 0 {
+		return "", p
+	}
+	return p[:idx], p[idx+1:]
+}
@@ -0,0 +1,67 @@
+package archivestore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// fileBackend stores archives as plain files under dir, the original (and
+// still default) context archive behavior.
+type fileBackend struct {
+	dir string
+}
+
+func newFileBackend(dir string) *fileBackend {
+	return &fileBackend{dir: dir}
+}
+
+func (b *fileBackend) Write(ctx context.Context, name string, r io.Reader) error {
+	if b.dir != "" && b.dir != "." {
+		if err := os.MkdirAll(b.dir, 0755); err != nil {
+			return fmt.Errorf("failed to create archive directory %s: %w", b.dir, err)
+		}
+	}
+	f, err := os.Create(filepath.Join(b.dir, name))
+	if err != nil {
+		return fmt.Errorf("failed to create archive file: %w", err)
+	}
+	defer f.Close()
+	if _, err := io.Copy(f, r); err != nil {
+		return fmt.Errorf("failed to write archive file: %w", err)
+	}
+	return nil
+}
+
+func (b *fileBackend) List(ctx context.Context) ([]string, error) {
+	entries, err := os.ReadDir(b.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archive directory %s: %w", b.dir, err)
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	return names, nil
+}
+
+func (b *fileBackend) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	f, err := os.Open(filepath.Join(b.dir, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive file: %w", err)
+	}
+	return f, nil
+}
+
+func (b *fileBackend) Delete(ctx context.Context, name string) error {
+	if err := os.Remove(filepath.Join(b.dir, name)); err != nil {
+		return fmt.Errorf("failed to delete archive file: %w", err)
+	}
+	return nil
+}
+
+func (b *fileBackend) Close() error { return nil }
@@ -0,0 +1,94 @@
+package archivestore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+)
+
+// gcsBackend stores archives as objects in a Google Cloud Storage bucket,
+// under an optional key prefix taken from the URL path
+// (gcs://bucket/prefix/name.tar.gz). Credentials come from
+// GOOGLE_APPLICATION_CREDENTIALS, the environment variable the Google
+// Cloud SDK already reads for a service account key file -
+// storage.NewClient picks it up with no further wiring needed here,
+// mirroring the env-var-only convention the s3 and ssh backends use.
+type gcsBackend struct {
+	client *storage.Client
+	bucket *storage.BucketHandle
+	prefix string
+}
+
+func newGCSBackend(u *url.URL) (Backend, string, error) {
+	bucket := u.Host
+	if bucket == "" {
+		return nil, "", fmt.Errorf("gcs archive URL %q is missing a bucket (want gcs://bucket/key)", u.String())
+	}
+	dir, name := splitObjectPath(u.Path)
+
+	client, err := storage.NewClient(context.Background())
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create GCS client: %w", err)
+	}
+
+	return &gcsBackend{client: client, bucket: client.Bucket(bucket), prefix: dir}, name, nil
+}
+
+func (b *gcsBackend) key(name string) string {
+	if b.prefix == "" {
+		return name
+	}
+	return b.prefix + "/" + name
+}
+
+func (b *gcsBackend) Write(ctx context.Context, name string, r io.Reader) error {
+	w := b.bucket.Object(b.key(name)).NewWriter(ctx)
+	if _, err := io.Copy(w, r); err != nil {
+		w.Close()
+		return fmt.Errorf("failed to upload object %s: %w", b.key(name), err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to finalize object %s: %w", b.key(name), err)
+	}
+	return nil
+}
+
+func (b *gcsBackend) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	r, err := b.bucket.Object(b.key(name)).NewReader(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open object %s: %w", b.key(name), err)
+	}
+	return r, nil
+}
+
+func (b *gcsBackend) Delete(ctx context.Context, name string) error {
+	if err := b.bucket.Object(b.key(name)).Delete(ctx); err != nil {
+		return fmt.Errorf("failed to delete object %s: %w", b.key(name), err)
+	}
+	return nil
+}
+
+func (b *gcsBackend) List(ctx context.Context) ([]string, error) {
+	var names []string
+	it := b.bucket.Objects(ctx, &storage.Query{Prefix: b.prefix})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects under %s: %w", b.prefix, err)
+		}
+		_, name := splitObjectPath("/" + attrs.Name)
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (b *gcsBackend) Close() error {
+	return b.client.Close()
+}
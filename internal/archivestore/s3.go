@@ -0,0 +1,126 @@
+package archivestore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// s3Backend stores archives as objects in an S3 bucket, under an optional
+// key prefix taken from the URL path (s3://bucket/prefix/name.tar.gz).
+// Credentials follow the standard AWS_ACCESS_KEY_ID / AWS_SECRET_ACCESS_KEY
+// / AWS_SESSION_TOKEN / AWS_REGION (or AWS_DEFAULT_REGION) environment
+// variables - the same convention srpmproc's blob package uses rather than
+// a config file or flag - falling back to the SDK's own default credential
+// chain (shared config, EC2/ECS role, etc.) when they're unset.
+type s3Backend struct {
+	client *s3.Client
+	bucket string
+	prefix string
+}
+
+func newS3Backend(u *url.URL) (Backend, string, error) {
+	bucket := u.Host
+	if bucket == "" {
+		return nil, "", fmt.Errorf("s3 archive URL %q is missing a bucket (want s3://bucket/key)", u.String())
+	}
+	dir, name := splitObjectPath(u.Path)
+
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+
+	var opts []func(*awsconfig.LoadOptions) error
+	if region != "" {
+		opts = append(opts, awsconfig.WithRegion(region))
+	}
+	if key, secret := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"); key != "" && secret != "" {
+		opts = append(opts, awsconfig.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(key, secret, os.Getenv("AWS_SESSION_TOKEN")),
+		))
+	}
+
+	cfg, err := awsconfig.LoadDefaultConfig(context.Background(), opts...)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	return &s3Backend{client: s3.NewFromConfig(cfg), bucket: bucket, prefix: dir}, name, nil
+}
+
+func (b *s3Backend) key(name string) string {
+	if b.prefix == "" {
+		return name
+	}
+	return b.prefix + "/" + name
+}
+
+func (b *s3Backend) Write(ctx context.Context, name string, r io.Reader) error {
+	uploader := manager.NewUploader(b.client)
+	_, err := uploader.Upload(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(b.key(name)),
+		Body:   r,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload s3://%s/%s: %w", b.bucket, b.key(name), err)
+	}
+	return nil
+}
+
+func (b *s3Backend) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	out, err := b.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(b.key(name)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to download s3://%s/%s: %w", b.bucket, b.key(name), err)
+	}
+	return out.Body, nil
+}
+
+func (b *s3Backend) Delete(ctx context.Context, name string) error {
+	_, err := b.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(b.key(name)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete s3://%s/%s: %w", b.bucket, b.key(name), err)
+	}
+	return nil
+}
+
+func (b *s3Backend) List(ctx context.Context) ([]string, error) {
+	var names []string
+	var token *string
+	for {
+		out, err := b.client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+			Bucket:            aws.String(b.bucket),
+			Prefix:            aws.String(b.prefix),
+			ContinuationToken: token,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list s3://%s/%s: %w", b.bucket, b.prefix, err)
+		}
+		for _, obj := range out.Contents {
+			_, name := splitObjectPath("/" + aws.ToString(obj.Key))
+			names = append(names, name)
+		}
+		if !aws.ToBool(out.IsTruncated) {
+			break
+		}
+		token = out.NextContinuationToken
+	}
+	return names, nil
+}
+
+func (b *s3Backend) Close() error { return nil }
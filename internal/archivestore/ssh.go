@@ -0,0 +1,170 @@
+package archivestore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// sshBackend stores archives as files on a remote host over SFTP, under an
+// optional directory taken from the URL path (ssh://host/dir/name.tar.gz).
+// Authentication is env-var driven, the same convention the s3 and gcs
+// backends use: CONTEXT_SSH_KEY names a private key file (default
+// ~/.ssh/id_rsa) and CONTEXT_SSH_USER overrides the username when the URL
+// doesn't carry one. There is no password or interactive auth path.
+type sshBackend struct {
+	conn   *ssh.Client
+	client *sftp.Client
+	dir    string
+}
+
+func newSSHBackend(u *url.URL) (Backend, string, error) {
+	if u.Host == "" {
+		return nil, "", fmt.Errorf("ssh archive URL %q is missing a host (want ssh://host/path)", u.String())
+	}
+
+	user := u.User.Username()
+	if user == "" {
+		user = os.Getenv("CONTEXT_SSH_USER")
+	}
+	if user == "" {
+		user = os.Getenv("USER")
+	}
+
+	keyPath := os.Getenv("CONTEXT_SSH_KEY")
+	if keyPath == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to resolve home directory for default SSH key: %w", err)
+		}
+		keyPath = filepath.Join(home, ".ssh", "id_rsa")
+	}
+	keyBytes, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read SSH key %s: %w", keyPath, err)
+	}
+	signer, err := ssh.ParsePrivateKey(keyBytes)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse SSH key %s: %w", keyPath, err)
+	}
+
+	hostKeyCallback, err := sshHostKeyCallback()
+	if err != nil {
+		return nil, "", err
+	}
+
+	host := u.Host
+	if u.Port() == "" {
+		host += ":22"
+	}
+
+	conn, err := ssh.Dial("tcp", host, &ssh.ClientConfig{
+		User:            user,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: hostKeyCallback,
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to connect to %s: %w", host, err)
+	}
+
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		conn.Close()
+		return nil, "", fmt.Errorf("failed to start SFTP session on %s: %w", host, err)
+	}
+
+	dir, name := splitObjectPath(u.Path)
+	return &sshBackend{conn: conn, client: client, dir: dir}, name, nil
+}
+
+// sshHostKeyCallback builds a host-key verification callback from
+// CONTEXT_SSH_KNOWN_HOSTS (default ~/.ssh/known_hosts). Verification is
+// mandatory: there's no insecure fallback, so a missing or empty
+// known_hosts file fails the connection rather than silently trusting it.
+func sshHostKeyCallback() (ssh.HostKeyCallback, error) {
+	path := os.Getenv("CONTEXT_SSH_KNOWN_HOSTS")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve home directory for default known_hosts: %w", err)
+		}
+		path = filepath.Join(home, ".ssh", "known_hosts")
+	}
+	cb, err := knownhosts.New(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load known_hosts %s: %w", path, err)
+	}
+	return cb, nil
+}
+
+func (b *sshBackend) path(name string) string {
+	if b.dir == "" || b.dir == "." {
+		return name
+	}
+	return b.dir + "/" + name
+}
+
+func (b *sshBackend) Write(ctx context.Context, name string, r io.Reader) error {
+	if b.dir != "" && b.dir != "." {
+		if err := b.client.MkdirAll(b.dir); err != nil {
+			return fmt.Errorf("failed to create remote directory %s: %w", b.dir, err)
+		}
+	}
+	f, err := b.client.Create(b.path(name))
+	if err != nil {
+		return fmt.Errorf("failed to create remote file %s: %w", b.path(name), err)
+	}
+	defer f.Close()
+	if _, err := io.Copy(f, r); err != nil {
+		return fmt.Errorf("failed to write remote file %s: %w", b.path(name), err)
+	}
+	return nil
+}
+
+func (b *sshBackend) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	f, err := b.client.Open(b.path(name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open remote file %s: %w", b.path(name), err)
+	}
+	return f, nil
+}
+
+func (b *sshBackend) Delete(ctx context.Context, name string) error {
+	if err := b.client.Remove(b.path(name)); err != nil {
+		return fmt.Errorf("failed to delete remote file %s: %w", b.path(name), err)
+	}
+	return nil
+}
+
+func (b *sshBackend) List(ctx context.Context) ([]string, error) {
+	dir := b.dir
+	if dir == "" {
+		dir = "."
+	}
+	entries, err := b.client.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remote directory %s: %w", dir, err)
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	return names, nil
+}
+
+func (b *sshBackend) Close() error {
+	sftpErr := b.client.Close()
+	if err := b.conn.Close(); err != nil {
+		return err
+	}
+	return sftpErr
+}
@@ -0,0 +1,179 @@
+// Package bm25 ranks snapshot metadata with Okapi BM25 instead of the
+// raw term-count-plus-bonus scoring `context search` used previously.
+// BM25 scores each field (topic, tags, notes, related branch)
+// independently — weighting rare terms higher via inverse document
+// frequency and discounting a field's own length — then callers combine
+// the per-field scores with configurable weights (see
+// internal/config.GetBM25FieldWeights).
+package bm25
+
+import "math"
+
+// Doc is one snapshot's searchable content, tokenized per field. Field
+// names are caller-defined (cmd/search.go uses "topic", "tags", "notes",
+// "related_branch") and must match the keys used when building Stats and
+// when scoring, or a field silently contributes zero.
+type Doc struct {
+	ID     string
+	Fields map[string][]string
+}
+
+// Params are the tunable BM25 knobs, conventionally k1 in [1.2, 2.0] and
+// b=0.75, plus a per-field weight applied after each field's BM25 score
+// is computed.
+type Params struct {
+	K1           float64
+	B            float64
+	FieldWeights map[string]float64
+}
+
+// DefaultParams returns Okapi BM25's textbook k1=1.2, b=0.75, with every
+// field weighted equally.
+func DefaultParams() Params {
+	return Params{K1: 1.2, B: 0.75, FieldWeights: map[string]float64{}}
+}
+
+// fieldWeight returns params.FieldWeights[field], defaulting to 1.0 for
+// a field the caller didn't configure.
+func (p Params) fieldWeight(field string) float64 {
+	if w, ok := p.FieldWeights[field]; ok {
+		return w
+	}
+	return 1.0
+}
+
+// Stats are the corpus-wide numbers BM25 needs: how many documents exist,
+// each field's average token length across the corpus, and each term's
+// document frequency per field. Stats are scoped to a fixed term set
+// (the query terms a search actually cares about) rather than a
+// document's full vocabulary — see BuildStats.
+type Stats struct {
+	DocCount    int                       `json:"doc_count"`
+	AvgFieldLen map[string]float64        `json:"avg_field_len"`
+	TermDF      map[string]map[string]int `json:"term_df"` // term -> field -> document frequency
+}
+
+// BuildStats computes corpus statistics for docs, restricted to terms
+// (the query's terms). Restricting to terms keeps the result small and
+// cheap to cache, since BM25 never needs the df of a term nobody
+// searched for.
+func BuildStats(docs []Doc, terms []string) *Stats {
+	stats := &Stats{
+		DocCount:    len(docs),
+		AvgFieldLen: make(map[string]float64),
+		TermDF:      make(map[string]map[string]int),
+	}
+
+	fieldLenTotal := make(map[string]int)
+	for _, doc := range docs {
+		for field, tokens := range doc.Fields {
+			fieldLenTotal[field] += len(tokens)
+		}
+	}
+	for field, total := range fieldLenTotal {
+		stats.AvgFieldLen[field] = float64(total) / float64(len(docs))
+	}
+
+	for _, term := range terms {
+		fieldDF := make(map[string]int)
+		for _, doc := range docs {
+			for field, tokens := range doc.Fields {
+				if containsToken(tokens, term) {
+					fieldDF[field]++
+				}
+			}
+		}
+		stats.TermDF[term] = fieldDF
+	}
+
+	return stats
+}
+
+func containsToken(tokens []string, term string) bool {
+	for _, t := range tokens {
+		if t == term {
+			return true
+		}
+	}
+	return false
+}
+
+// Score returns doc's combined BM25 score across every field in
+// doc.Fields, for the given terms, weighted by params.FieldWeights. A
+// term with no recorded document frequency in stats (e.g. because it
+// wasn't part of the term set Stats was built with) contributes nothing.
+func Score(doc Doc, terms []string, stats *Stats, params Params) float64 {
+	var total float64
+	for field, tokens := range doc.Fields {
+		fieldLen := float64(len(tokens))
+		avgLen := stats.AvgFieldLen[field]
+		if avgLen == 0 {
+			avgLen = 1
+		}
+
+		var fieldScore float64
+		for _, term := range terms {
+			df, ok := stats.TermDF[term][field]
+			if !ok || df == 0 {
+				continue
+			}
+			tf := float64(termFreq(tokens, term))
+			if tf == 0 {
+				continue
+			}
+
+			idfScore := idf(stats.DocCount, df)
+			denom := tf + params.K1*(1-params.B+params.B*fieldLen/avgLen)
+			fieldScore += idfScore * (tf * (params.K1 + 1)) / denom
+		}
+
+		total += params.fieldWeight(field) * fieldScore
+	}
+	return total
+}
+
+func termFreq(tokens []string, term string) int {
+	count := 0
+	for _, t := range tokens {
+		if t == term {
+			count++
+		}
+	}
+	return count
+}
+
+// idf is the Robertson-Sparck Jones inverse document frequency, offset
+// by 1 inside the log so a term present in every document (df == N)
+// still scores non-negative rather than going to zero or negative.
+func idf(docCount, df int) float64 {
+	return math.Log(1 + (float64(docCount)-float64(df)+0.5)/(float64(df)+0.5))
+}
+
+// Normalize min-max scales raw BM25 scores to [0, 100], matching the
+// [0, 100] scale semanticScore already uses so hybrid fusion can combine
+// them directly. A corpus where every score is equal (including the
+// single-document and all-zero cases) maps every score to 0.
+func Normalize(scores []float64) []float64 {
+	out := make([]float64, len(scores))
+	if len(scores) == 0 {
+		return out
+	}
+
+	min, max := scores[0], scores[0]
+	for _, s := range scores {
+		if s < min {
+			min = s
+		}
+		if s > max {
+			max = s
+		}
+	}
+
+	if max == min {
+		return out
+	}
+	for i, s := range scores {
+		out[i] = (s - min) / (max - min) * 100
+	}
+	return out
+}
@@ -0,0 +1,126 @@
+package bm25
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestScoreRanksRareTermHigher(t *testing.T) {
+	docs := []Doc{
+		{ID: "a", Fields: map[string][]string{
+			"topic": {"security"},
+			"notes": {"found", "a", "bug"},
+		}},
+		{ID: "b", Fields: map[string][]string{
+			"topic": {"performance"},
+			"notes": {"found", "a", "bug"},
+		}},
+	}
+	terms := []string{"security", "bug"}
+	stats := BuildStats(docs, terms)
+	params := DefaultParams()
+
+	scoreA := Score(docs[0], terms, stats, params)
+	scoreB := Score(docs[1], terms, stats, params)
+
+	if scoreA <= scoreB {
+		t.Errorf("expected doc with rare term %q in topic to outscore the other, got a=%v b=%v", "security", scoreA, scoreB)
+	}
+}
+
+func TestScoreZeroForNoMatchingTerms(t *testing.T) {
+	docs := []Doc{
+		{ID: "a", Fields: map[string][]string{"topic": {"performance"}}},
+	}
+	stats := BuildStats(docs, []string{"security"})
+
+	if got := Score(docs[0], []string{"security"}, stats, DefaultParams()); got != 0 {
+		t.Errorf("expected 0 for a doc with no matching terms, got %v", got)
+	}
+}
+
+func TestFieldWeightsScaleContribution(t *testing.T) {
+	docs := []Doc{
+		{ID: "a", Fields: map[string][]string{"topic": {"security"}, "notes": {"security"}}},
+		{ID: "b", Fields: map[string][]string{"topic": {"other"}, "notes": {"other"}}},
+	}
+	terms := []string{"security"}
+	stats := BuildStats(docs, terms)
+
+	equal := DefaultParams()
+	weighted := DefaultParams()
+	weighted.FieldWeights = map[string]float64{"topic": 10.0}
+
+	if Score(docs[0], terms, stats, weighted) <= Score(docs[0], terms, stats, equal) {
+		t.Error("expected a higher topic field weight to raise the combined score")
+	}
+}
+
+func TestNormalizeScalesToZeroToHundred(t *testing.T) {
+	got := Normalize([]float64{1, 2, 4})
+	want := []float64{0, 100.0 / 3, 100}
+	for i := range want {
+		diff := got[i] - want[i]
+		if diff < -0.001 || diff > 0.001 {
+			t.Errorf("index %d: expected %v, got %v", i, want[i], got[i])
+		}
+	}
+}
+
+func TestNormalizeFlatScoresAllZero(t *testing.T) {
+	got := Normalize([]float64{5, 5, 5})
+	for i, v := range got {
+		if v != 0 {
+			t.Errorf("index %d: expected 0 for a flat score set, got %v", i, v)
+		}
+	}
+}
+
+func TestGenerationStableUnderReordering(t *testing.T) {
+	a := Generation([]string{"snapshot/b", "snapshot/a"})
+	b := Generation([]string{"snapshot/a", "snapshot/b"})
+	if a != b {
+		t.Error("expected Generation to be order-independent")
+	}
+
+	c := Generation([]string{"snapshot/a", "snapshot/c"})
+	if a == c {
+		t.Error("expected a different branch set to produce a different generation")
+	}
+}
+
+func TestCacheSaveLoadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "df.json")
+
+	stats := &Stats{
+		DocCount:    2,
+		AvgFieldLen: map[string]float64{"topic": 1},
+		TermDF:      map[string]map[string]int{"security": {"topic": 1}},
+	}
+	c := &Cache{Generation: "abc123", Stats: stats}
+
+	if err := SaveCache(path, c); err != nil {
+		t.Fatalf("SaveCache failed: %v", err)
+	}
+
+	loaded, err := LoadCache(path)
+	if err != nil {
+		t.Fatalf("LoadCache failed: %v", err)
+	}
+	if loaded.Generation != c.Generation {
+		t.Errorf("expected generation %q, got %q", c.Generation, loaded.Generation)
+	}
+	if loaded.Stats.DocCount != stats.DocCount {
+		t.Errorf("expected doc count %d, got %d", stats.DocCount, loaded.Stats.DocCount)
+	}
+}
+
+func TestLoadCacheMissingFileReturnsNil(t *testing.T) {
+	c, err := LoadCache(filepath.Join(t.TempDir(), "does-not-exist.json"))
+	if err != nil {
+		t.Fatalf("expected no error for a missing cache file, got %v", err)
+	}
+	if c != nil {
+		t.Error("expected a nil Cache for a missing file")
+	}
+}
@@ -0,0 +1,84 @@
+package bm25
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Generation fingerprints the current set of snapshot branches so a
+// cached Stats can be invalidated the moment a snapshot is created,
+// forgotten, or pruned. Branch order doesn't matter to callers, so this
+// sorts before hashing.
+func Generation(branches []string) string {
+	sorted := append([]string(nil), branches...)
+	sort.Strings(sorted)
+
+	h := sha256.New()
+	for _, b := range sorted {
+		h.Write([]byte(b))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Cache is Stats plus the branch-list Generation it was computed for,
+// persisted so repeated searches against an unchanged set of snapshots
+// don't re-scan every branch just to recompute document frequency.
+type Cache struct {
+	Generation string `json:"generation"`
+	Stats      *Stats `json:"stats"`
+}
+
+// LoadCache reads a Cache previously written by SaveCache. A missing
+// file is not an error: it returns a nil Cache, which callers should
+// treat the same as a generation mismatch and recompute from scratch.
+func LoadCache(path string) (*Cache, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var c Cache
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// SaveCache writes c to path atomically (temp file plus rename in the
+// same directory), mirroring the write pattern embeddings.Index.Flush
+// uses for its own on-disk file.
+func SaveCache(path string, c *Cache) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".df-*.json")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, path)
+}
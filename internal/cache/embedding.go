@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+const embeddingCacheFile = "embeddings.json"
+
+// EmbeddingLRU is a byte-budgeted LRU cache of embedding vectors, keyed by
+// the snapshot branch's commit SHA. See MetadataLRU for the matching
+// metadata cache; both are warmed from and persisted to
+// $XDG_CACHE_HOME/context/<repo-id>/.
+type EmbeddingLRU struct {
+	lru *sizedLRU
+	dir string
+}
+
+// NewEmbeddingLRU creates an embedding cache with the given byte budget
+// and loads any warm cache found on disk for the current repository. A
+// maxBytes of 0 or less disables eviction.
+func NewEmbeddingLRU(maxBytes int64) *EmbeddingLRU {
+	dir, _ := repoCacheDir()
+	c := &EmbeddingLRU{lru: newSizedLRU(maxBytes), dir: dir}
+	c.loadFromDisk()
+	return c
+}
+
+// Get returns the cached embedding vector for a commit SHA, if present.
+func (c *EmbeddingLRU) Get(sha string) ([]float32, bool) {
+	v, ok := c.lru.get(sha)
+	if !ok {
+		return nil, false
+	}
+	return v.([]float32), true
+}
+
+// Add stores an embedding vector for a commit SHA.
+func (c *EmbeddingLRU) Add(sha string, vec []float32) {
+	c.lru.add(sha, vec, int64(len(vec)*4))
+}
+
+// Invalidate drops a cached entry, e.g. because its snapshot branch was
+// forgotten or pruned and no longer exists.
+func (c *EmbeddingLRU) Invalidate(sha string) {
+	c.lru.remove(sha)
+}
+
+// Keys returns every commit SHA currently cached, most-recently-used first.
+// Used by `context check` to find cache entries orphaned by deleted
+// snapshot branches.
+func (c *EmbeddingLRU) Keys() []string {
+	return c.lru.keys()
+}
+
+// Save persists the current cache contents to disk. It is a no-op if no
+// cache directory is available.
+func (c *EmbeddingLRU) Save() error {
+	if c.dir == "" {
+		return nil
+	}
+
+	out := make(map[string][]float32)
+	for _, sha := range c.lru.keys() {
+		if vec, ok := c.Get(sha); ok {
+			out[sha] = vec
+		}
+	}
+
+	data, err := json.Marshal(out)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(c.dir, embeddingCacheFile), data, 0o644)
+}
+
+func (c *EmbeddingLRU) loadFromDisk() {
+	if c.dir == "" {
+		return
+	}
+
+	data, err := os.ReadFile(filepath.Join(c.dir, embeddingCacheFile))
+	if err != nil {
+		return
+	}
+
+	var in map[string][]float32
+	if err := json.Unmarshal(data, &in); err != nil {
+		return
+	}
+
+	for sha, vec := range in {
+		c.Add(sha, vec)
+	}
+}
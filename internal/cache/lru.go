@@ -0,0 +1,114 @@
+// Package cache provides byte-budgeted LRU caches for snapshot metadata and
+// embedding vectors, modeled on go-git's plumbing/cache split: one cache per
+// kind of object, each with its own size budget and eviction policy.
+package cache
+
+import (
+	"container/list"
+	"sync"
+)
+
+// sizedLRU is a byte-budgeted, least-recently-used cache. It is the shared
+// implementation behind MetadataLRU and EmbeddingLRU; callers interact with
+// the typed wrappers, not this type directly.
+type sizedLRU struct {
+	mu       sync.Mutex
+	maxBytes int64
+	curBytes int64
+	ll       *list.List
+	items    map[string]*list.Element
+}
+
+type lruEntry struct {
+	key   string
+	value interface{}
+	bytes int64
+}
+
+func newSizedLRU(maxBytes int64) *sizedLRU {
+	return &sizedLRU{
+		maxBytes: maxBytes,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+func (c *sizedLRU) get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.ll.MoveToFront(el)
+	return el.Value.(*lruEntry).value, true
+}
+
+func (c *sizedLRU) add(key string, value interface{}, bytes int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		entry := el.Value.(*lruEntry)
+		c.curBytes += bytes - entry.bytes
+		entry.value = value
+		entry.bytes = bytes
+		c.ll.MoveToFront(el)
+	} else {
+		entry := &lruEntry{key: key, value: value, bytes: bytes}
+		el := c.ll.PushFront(entry)
+		c.items[key] = el
+		c.curBytes += bytes
+	}
+
+	c.evictToFit()
+}
+
+func (c *sizedLRU) evictToFit() {
+	if c.maxBytes <= 0 {
+		return
+	}
+	for c.curBytes > c.maxBytes {
+		el := c.ll.Back()
+		if el == nil {
+			return
+		}
+		c.removeElement(el)
+	}
+}
+
+func (c *sizedLRU) remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.removeElement(el)
+	}
+}
+
+// removeElement must be called with c.mu held.
+func (c *sizedLRU) removeElement(el *list.Element) {
+	entry := el.Value.(*lruEntry)
+	c.ll.Remove(el)
+	delete(c.items, entry.key)
+	c.curBytes -= entry.bytes
+}
+
+func (c *sizedLRU) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+// keys returns every key currently resident, most-recently-used first.
+func (c *sizedLRU) keys() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	keys := make([]string, 0, c.ll.Len())
+	for el := c.ll.Front(); el != nil; el = el.Next() {
+		keys = append(keys, el.Value.(*lruEntry).key)
+	}
+	return keys
+}
@@ -0,0 +1,74 @@
+package cache
+
+import "testing"
+
+func TestSizedLRUGetAdd(t *testing.T) {
+	c := newSizedLRU(1024)
+
+	if _, ok := c.get("a"); ok {
+		t.Fatal("expected miss on empty cache")
+	}
+
+	c.add("a", "value-a", 10)
+	v, ok := c.get("a")
+	if !ok || v.(string) != "value-a" {
+		t.Fatalf("expected hit with value-a, got %v, %v", v, ok)
+	}
+}
+
+func TestSizedLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newSizedLRU(20)
+
+	c.add("a", "1", 10)
+	c.add("b", "2", 10)
+
+	// Touch "a" so "b" becomes the least-recently-used entry.
+	c.get("a")
+
+	c.add("c", "3", 10)
+
+	if _, ok := c.get("b"); ok {
+		t.Fatal("expected b to be evicted")
+	}
+	if _, ok := c.get("a"); !ok {
+		t.Fatal("expected a to survive eviction")
+	}
+	if _, ok := c.get("c"); !ok {
+		t.Fatal("expected c to be present")
+	}
+}
+
+func TestSizedLRUUnboundedWhenMaxBytesNotPositive(t *testing.T) {
+	c := newSizedLRU(0)
+
+	for i := 0; i < 100; i++ {
+		c.add(string(rune('a'+i%26)), i, 1<<20)
+	}
+
+	if c.len() == 0 {
+		t.Fatal("expected entries to be retained when eviction is disabled")
+	}
+}
+
+func TestSizedLRURemove(t *testing.T) {
+	c := newSizedLRU(1024)
+	c.add("a", "1", 10)
+	c.remove("a")
+
+	if _, ok := c.get("a"); ok {
+		t.Fatal("expected a to be removed")
+	}
+}
+
+func TestSizedLRUKeysMostRecentFirst(t *testing.T) {
+	c := newSizedLRU(1024)
+	c.add("a", "1", 1)
+	c.add("b", "2", 1)
+	c.add("c", "3", 1)
+	c.get("a")
+
+	keys := c.keys()
+	if len(keys) != 3 || keys[0] != "a" {
+		t.Fatalf("expected a to be most-recently-used, got %v", keys)
+	}
+}
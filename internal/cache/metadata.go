@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/pders01/git-context/internal/models"
+)
+
+const metadataCacheFile = "metadata.json"
+
+// MetadataLRU is a byte-budgeted LRU cache of parsed snapshot metadata,
+// keyed by the snapshot branch's commit SHA. It is backed by a warm,
+// on-disk cache under $XDG_CACHE_HOME/context/<repo-id>/ so that a cold
+// `context related`/`search` invocation doesn't have to re-parse every
+// snapshot's meta.json from scratch.
+type MetadataLRU struct {
+	lru *sizedLRU
+	dir string
+}
+
+// NewMetadataLRU creates a metadata cache with the given byte budget and
+// loads any warm cache found on disk for the current repository. A
+// maxBytes of 0 or less disables eviction (the cache grows unbounded).
+func NewMetadataLRU(maxBytes int64) *MetadataLRU {
+	dir, _ := repoCacheDir()
+	c := &MetadataLRU{lru: newSizedLRU(maxBytes), dir: dir}
+	c.loadFromDisk()
+	return c
+}
+
+// Get returns the cached metadata for a commit SHA, if present.
+func (c *MetadataLRU) Get(sha string) (*models.Metadata, bool) {
+	v, ok := c.lru.get(sha)
+	if !ok {
+		return nil, false
+	}
+	return v.(*models.Metadata), true
+}
+
+// Add stores parsed metadata for a commit SHA.
+func (c *MetadataLRU) Add(sha string, meta *models.Metadata) {
+	c.lru.add(sha, meta, approxJSONSize(meta))
+}
+
+// Invalidate drops a cached entry, e.g. because its snapshot branch was
+// forgotten or pruned and no longer exists.
+func (c *MetadataLRU) Invalidate(sha string) {
+	c.lru.remove(sha)
+}
+
+// Keys returns every commit SHA currently cached, most-recently-used first.
+// Used by `context check` to find cache entries orphaned by deleted
+// snapshot branches.
+func (c *MetadataLRU) Keys() []string {
+	return c.lru.keys()
+}
+
+// Save persists the current cache contents to disk so the next cold
+// invocation can warm-start from them. It is a no-op if no cache
+// directory is available (e.g. $HOME could not be resolved).
+func (c *MetadataLRU) Save() error {
+	if c.dir == "" {
+		return nil
+	}
+
+	out := make(map[string]*models.Metadata)
+	for _, sha := range c.lru.keys() {
+		if meta, ok := c.Get(sha); ok {
+			out[sha] = meta
+		}
+	}
+
+	data, err := json.Marshal(out)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(c.dir, metadataCacheFile), data, 0o644)
+}
+
+func (c *MetadataLRU) loadFromDisk() {
+	if c.dir == "" {
+		return
+	}
+
+	data, err := os.ReadFile(filepath.Join(c.dir, metadataCacheFile))
+	if err != nil {
+		return
+	}
+
+	var in map[string]*models.Metadata
+	if err := json.Unmarshal(data, &in); err != nil {
+		return
+	}
+
+	for sha, meta := range in {
+		c.Add(sha, meta)
+	}
+}
+
+// approxJSONSize estimates the in-memory cost of a cached value as its
+// JSON-encoded byte length, which is cheap to compute and close enough
+// for a byte-budgeted eviction policy.
+func approxJSONSize(v interface{}) int64 {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return int64(len(data))
+}
@@ -0,0 +1,33 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/pders01/git-context/internal/git"
+)
+
+// repoCacheDir returns $XDG_CACHE_HOME/context/<repo-id>, creating it if
+// necessary. The repo-id is the repository's root commit SHA, which is
+// stable across clones and worktrees of the same history.
+func repoCacheDir() (string, error) {
+	repoID, err := git.RootCommit()
+	if err != nil {
+		return "", err
+	}
+
+	base := os.Getenv("XDG_CACHE_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		base = filepath.Join(home, ".cache")
+	}
+
+	dir := filepath.Join(base, "context", repoID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
@@ -1,7 +1,8 @@
 package config
 
 import (
-	"github.com/paulderscheid/git-context/internal/models"
+	"github.com/pders01/git-context/internal/models"
+	"github.com/pders01/git-context/internal/schedule"
 	"github.com/spf13/viper"
 )
 
@@ -39,6 +40,47 @@ func ShouldPreserve(tags []string) bool {
 	return false
 }
 
+// GetKeepLast returns the number of most recent snapshots to always keep
+func GetKeepLast() int {
+	return viper.GetInt("retention.keep_last")
+}
+
+// GetKeepHourly returns the number of hourly buckets to keep
+func GetKeepHourly() int {
+	return viper.GetInt("retention.keep_hourly")
+}
+
+// GetKeepDaily returns the number of daily buckets to keep
+func GetKeepDaily() int {
+	return viper.GetInt("retention.keep_daily")
+}
+
+// GetKeepWeekly returns the number of weekly buckets to keep
+func GetKeepWeekly() int {
+	return viper.GetInt("retention.keep_weekly")
+}
+
+// GetKeepMonthly returns the number of monthly buckets to keep
+func GetKeepMonthly() int {
+	return viper.GetInt("retention.keep_monthly")
+}
+
+// GetKeepYearly returns the number of yearly buckets to keep
+func GetKeepYearly() int {
+	return viper.GetInt("retention.keep_yearly")
+}
+
+// GetKeepTags returns tags that, when present, always keep a snapshot
+func GetKeepTags() []string {
+	return viper.GetStringSlice("retention.keep_tag")
+}
+
+// GetKeepWithin returns the retention duration string (e.g. "7d", "6m")
+// below which snapshots are always kept, or "" if unset
+func GetKeepWithin() string {
+	return viper.GetString("retention.keep_within")
+}
+
 // GetEmbeddingsEnabled returns whether embeddings are enabled
 func GetEmbeddingsEnabled() bool {
 	return viper.GetBool("embeddings.enabled")
@@ -62,6 +104,24 @@ func GetOllamaURL() string {
 	return url
 }
 
+// GetMetadataCacheBytes returns the byte budget for the parsed-metadata LRU
+func GetMetadataCacheBytes() int64 {
+	bytes := viper.GetInt64("cache.metadata_bytes")
+	if bytes == 0 {
+		return 8 * 1024 * 1024 // 8 MiB default
+	}
+	return bytes
+}
+
+// GetEmbeddingCacheBytes returns the byte budget for the embedding-vector LRU
+func GetEmbeddingCacheBytes() int64 {
+	bytes := viper.GetInt64("cache.embedding_bytes")
+	if bytes == 0 {
+		return 32 * 1024 * 1024 // 32 MiB default
+	}
+	return bytes
+}
+
 // GetKeywordWeight returns the weight for keyword scoring in hybrid search
 func GetKeywordWeight() float64 {
 	weight := viper.GetFloat64("search.keyword_weight")
@@ -79,3 +139,87 @@ func GetSemanticWeight() float64 {
 	}
 	return weight
 }
+
+// GetSigningEnabled returns whether snapshots are signed by default
+func GetSigningEnabled() bool {
+	return viper.GetBool("signing.enabled")
+}
+
+// GetSigningFormat returns the default signature format (gpg, ssh, x509)
+func GetSigningFormat() string {
+	format := viper.GetString("signing.format")
+	if format == "" {
+		return "gpg"
+	}
+	return format
+}
+
+// GetSigningKey returns the default signing key path
+func GetSigningKey() string {
+	return viper.GetString("signing.key")
+}
+
+// GetRequireSigned returns whether save should refuse to create an
+// unsigned snapshot, the policy a team shares when snapshots are mirrored
+// to untrusted remotes.
+func GetRequireSigned() bool {
+	return viper.GetBool("signing.requireSigned")
+}
+
+// GetBM25K1 returns the BM25 term-frequency saturation parameter.
+func GetBM25K1() float64 {
+	k1 := viper.GetFloat64("search.bm25.k1")
+	if k1 == 0 {
+		return 1.2 // Okapi BM25 default
+	}
+	return k1
+}
+
+// GetBM25B returns the BM25 field-length normalization parameter.
+func GetBM25B() float64 {
+	if !viper.IsSet("search.bm25.b") {
+		return 0.75 // Okapi BM25 default
+	}
+	return viper.GetFloat64("search.bm25.b")
+}
+
+// GetBM25FieldWeights returns the per-field weights BM25 scores are
+// combined with, e.g.:
+//
+//	[search.bm25.field_weights]
+//	topic = 3.0
+//	tags = 2.0
+//	notes = 1.0
+//	related_branch = 1.0
+//
+// A field missing from config falls back to the defaults below, which
+// favor a match in the topic or tags over one buried in free-form notes.
+func GetBM25FieldWeights() map[string]float64 {
+	weights := map[string]float64{
+		"topic":          3.0,
+		"tags":           2.0,
+		"notes":          1.0,
+		"related_branch": 1.0,
+	}
+	for field, weight := range viper.GetStringMap("search.bm25.field_weights") {
+		if f, ok := weight.(float64); ok {
+			weights[field] = f
+		}
+	}
+	return weights
+}
+
+// GetSchedules returns the configured context schedule jobs, e.g.:
+//
+//	[[schedule.jobs]]
+//	cron = "0 */4 * * *"
+//	topic = "auto"
+//	mode = "research-only"
+//	tags = ["auto"]
+func GetSchedules() ([]schedule.Job, error) {
+	var jobs []schedule.Job
+	if err := viper.UnmarshalKey("schedule.jobs", &jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
@@ -0,0 +1,240 @@
+// Package diff renders a unified text diff between two blobs, in the same
+// "--- a/path / +++ b/path / @@ -l,s +l,s @@" shape GNU diff and `git diff`
+// produce, so output can be piped to tools like `less -R` or `delta`.
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// DefaultContext is the number of unchanged lines shown around each change
+// when a caller doesn't specify its own.
+const DefaultContext = 3
+
+// IsBinary reports whether content looks like a binary blob, using the
+// same "contains a NUL byte" heuristic git itself uses.
+func IsBinary(content []byte) bool {
+	return bytes.IndexByte(content, 0) != -1
+}
+
+// FormatBlobs renders the unified diff between oldContent (at oldPath) and
+// newContent (at newPath), with context lines of context around each
+// change. If either blob looks binary, it returns a single summary line
+// instead of attempting a text diff. An empty string means the contents
+// are identical.
+func FormatBlobs(oldPath, newPath string, oldContent, newContent []byte, context int) string {
+	if bytes.Equal(oldContent, newContent) {
+		return ""
+	}
+
+	if IsBinary(oldContent) || IsBinary(newContent) {
+		return fmt.Sprintf("Binary files %s and %s differ (%d bytes → %d bytes)\n",
+			oldPath, newPath, len(oldContent), len(newContent))
+	}
+
+	if context <= 0 {
+		context = DefaultContext
+	}
+
+	oldLines := splitLines(oldContent)
+	newLines := splitLines(newContent)
+
+	hunks := buildHunks(runsOf(oldLines, newLines), context)
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- a/%s\n", oldPath)
+	fmt.Fprintf(&b, "+++ b/%s\n", newPath)
+	for _, h := range hunks {
+		writeHunk(&b, h, oldLines, newLines)
+	}
+	return b.String()
+}
+
+func splitLines(content []byte) []string {
+	if len(content) == 0 {
+		return nil
+	}
+	text := strings.TrimSuffix(string(content), "\n")
+	return strings.Split(text, "\n")
+}
+
+// runKind identifies whether a run of the edit script is unchanged,
+// deleted (present only in the old side), or inserted (only in the new
+// side).
+type runKind int
+
+const (
+	runEqual runKind = iota
+	runDelete
+	runInsert
+)
+
+// run is a maximal span of consecutive same-kind operations, expressed as
+// half-open index ranges into the old ([i1,i2)) and new ([j1,j2)) lines.
+type run struct {
+	kind   runKind
+	i1, i2 int
+	j1, j2 int
+}
+
+// runsOf computes the edit script turning a into b (via a line-level LCS)
+// and collapses it into maximal equal/delete/insert runs.
+func runsOf(a, b []string) []run {
+	n, m := len(a), len(b)
+
+	// lcsLen[i][j] = length of the LCS of a[i:] and b[j:]
+	lcsLen := make([][]int, n+1)
+	for i := range lcsLen {
+		lcsLen[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcsLen[i][j] = lcsLen[i+1][j+1] + 1
+			} else if lcsLen[i+1][j] >= lcsLen[i][j+1] {
+				lcsLen[i][j] = lcsLen[i+1][j]
+			} else {
+				lcsLen[i][j] = lcsLen[i][j+1]
+			}
+		}
+	}
+
+	var runs []run
+	push := func(kind runKind, i1, i2, j1, j2 int) {
+		if len(runs) > 0 && runs[len(runs)-1].kind == kind {
+			runs[len(runs)-1].i2 = i2
+			runs[len(runs)-1].j2 = j2
+			return
+		}
+		runs = append(runs, run{kind: kind, i1: i1, i2: i2, j1: j1, j2: j2})
+	}
+
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			push(runEqual, i, i+1, j, j+1)
+			i++
+			j++
+		case lcsLen[i+1][j] >= lcsLen[i][j+1]:
+			push(runDelete, i, i+1, j, j)
+			i++
+		default:
+			push(runInsert, i, i, j, j+1)
+			j++
+		}
+	}
+	for i < n {
+		push(runDelete, i, i+1, j, j)
+		i++
+	}
+	for j < m {
+		push(runInsert, i, i, j, j+1)
+		j++
+	}
+
+	return runs
+}
+
+// hunk is a group of runs to render as one "@@ ... @@" block, already
+// trimmed to at most `context` lines of leading/trailing unchanged text.
+type hunk struct {
+	runs []run
+}
+
+// buildHunks groups runs into hunks the way GNU diff / Python's
+// difflib.get_grouped_opcodes does: equal runs longer than 2*context are
+// split into a hunk boundary, contributing only `context` lines of
+// surrounding text to each neighboring hunk.
+func buildHunks(runs []run, context int) []hunk {
+	if len(runs) == 0 {
+		return nil
+	}
+
+	// Trim excess context from the very first and very last equal runs.
+	if runs[0].kind == runEqual {
+		r := runs[0]
+		i1 := max(r.i1, r.i2-context)
+		j1 := max(r.j1, r.j2-context)
+		runs[0] = run{kind: runEqual, i1: i1, i2: r.i2, j1: j1, j2: r.j2}
+	}
+	if last := len(runs) - 1; runs[last].kind == runEqual {
+		r := runs[last]
+		i2 := min(r.i2, r.i1+context)
+		j2 := min(r.j2, r.j1+context)
+		runs[last] = run{kind: runEqual, i1: r.i1, i2: i2, j1: r.j1, j2: j2}
+	}
+
+	var hunks []hunk
+	var group []run
+
+	for _, r := range runs {
+		if r.kind == runEqual && r.i2-r.i1 > 2*context {
+			group = append(group, run{kind: runEqual, i1: r.i1, i2: min(r.i2, r.i1+context), j1: r.j1, j2: min(r.j2, r.j1+context)})
+			if !(len(group) == 1 && group[0].kind == runEqual) {
+				hunks = append(hunks, hunk{runs: group})
+			}
+			group = nil
+			r = run{kind: runEqual, i1: max(r.i1, r.i2-context), i2: r.i2, j1: max(r.j1, r.j2-context), j2: r.j2}
+		}
+		group = append(group, r)
+	}
+	if !(len(group) == 1 && group[0].kind == runEqual) {
+		hunks = append(hunks, hunk{runs: group})
+	}
+
+	return hunks
+}
+
+func writeHunk(b *strings.Builder, h hunk, oldLines, newLines []string) {
+	first, last := h.runs[0], h.runs[len(h.runs)-1]
+	origLines := last.i2 - first.i1
+	newCount := last.j2 - first.j1
+
+	origStart := first.i1 + 1
+	if origLines == 0 {
+		origStart = first.i1
+	}
+	newStart := first.j1 + 1
+	if newCount == 0 {
+		newStart = first.j1
+	}
+
+	fmt.Fprintf(b, "@@ -%d,%d +%d,%d @@\n", origStart, origLines, newStart, newCount)
+
+	for _, r := range h.runs {
+		switch r.kind {
+		case runEqual:
+			for k := r.i1; k < r.i2; k++ {
+				fmt.Fprintf(b, " %s\n", oldLines[k])
+			}
+		case runDelete:
+			for k := r.i1; k < r.i2; k++ {
+				fmt.Fprintf(b, "-%s\n", oldLines[k])
+			}
+		case runInsert:
+			for k := r.j1; k < r.j2; k++ {
+				fmt.Fprintf(b, "+%s\n", newLines[k])
+			}
+		}
+	}
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,69 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatBlobsIdentical(t *testing.T) {
+	content := []byte("a\nb\nc\n")
+	if got := FormatBlobs("f", "f", content, content, 3); got != "" {
+		t.Errorf("expected empty diff for identical content, got %q", got)
+	}
+}
+
+func TestFormatBlobsSimpleChange(t *testing.T) {
+	oldContent := []byte("one\ntwo\nthree\n")
+	newContent := []byte("one\ntwo-updated\nthree\n")
+
+	got := FormatBlobs("notes.md", "notes.md", oldContent, newContent, 3)
+
+	if !strings.Contains(got, "--- a/notes.md\n") || !strings.Contains(got, "+++ b/notes.md\n") {
+		t.Fatalf("expected file headers, got:\n%s", got)
+	}
+	if !strings.Contains(got, "-two\n") || !strings.Contains(got, "+two-updated\n") {
+		t.Errorf("expected the changed line to show as -/+, got:\n%s", got)
+	}
+	if !strings.Contains(got, " one\n") || !strings.Contains(got, " three\n") {
+		t.Errorf("expected unchanged lines as context, got:\n%s", got)
+	}
+}
+
+func TestFormatBlobsBinary(t *testing.T) {
+	oldContent := []byte{0x00, 0x01, 0x02}
+	newContent := []byte{0x00, 0x01, 0x02, 0x03}
+
+	got := FormatBlobs("embedding.bin", "embedding.bin", oldContent, newContent, 3)
+
+	if !strings.Contains(got, "Binary files embedding.bin and embedding.bin differ (3 bytes → 4 bytes)") {
+		t.Errorf("expected binary summary line, got: %q", got)
+	}
+}
+
+func TestFormatBlobsAddedFile(t *testing.T) {
+	got := FormatBlobs("new.md", "new.md", nil, []byte("hello\n"), 3)
+	if !strings.Contains(got, "+hello\n") {
+		t.Errorf("expected inserted line, got:\n%s", got)
+	}
+	if !strings.Contains(got, "@@ -0,0 +1,1 @@") {
+		t.Errorf("expected a pure-addition hunk header, got:\n%s", got)
+	}
+}
+
+func TestFormatBlobsManyLinesSplitsHunks(t *testing.T) {
+	var oldLines, newLines []string
+	for i := 0; i < 50; i++ {
+		oldLines = append(oldLines, "line")
+		newLines = append(newLines, "line")
+	}
+	oldLines[5] = "changed-a"
+	newLines[5] = "changed-a-new"
+	oldLines[40] = "changed-b"
+	newLines[40] = "changed-b-new"
+
+	got := FormatBlobs("f", "f", []byte(strings.Join(oldLines, "\n")+"\n"), []byte(strings.Join(newLines, "\n")+"\n"), 3)
+
+	if strings.Count(got, "@@ ") != 2 {
+		t.Errorf("expected two separate hunks for distant changes, got:\n%s", got)
+	}
+}
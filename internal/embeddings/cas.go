@@ -0,0 +1,141 @@
+package embeddings
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Pointer is the small file written in place of a snapshot's embedding.bin
+// once its vector has been deduplicated into the content-addressed store:
+// {"sha256":"...","dim":768}. It is valid JSON, which is how Resolve tells
+// it apart from a raw CTXE-framed embedding file.
+type Pointer struct {
+	SHA256 string `json:"sha256"`
+	Dim    int    `json:"dim"`
+}
+
+// IsPointer reports whether raw is a Pointer rather than a raw embedding
+// file. A CTXE-framed embedding file never starts with '{'; a legacy
+// headerless file can (its first byte is the low byte of a float64), but
+// its raw float bytes are not valid JSON, so checking both tells them apart.
+func IsPointer(raw []byte) bool {
+	for _, b := range raw {
+		switch b {
+		case ' ', '\t', '\n', '\r':
+			continue
+		default:
+			return b == '{' && json.Valid(raw)
+		}
+	}
+	return false
+}
+
+// ParsePointer decodes a Pointer file's contents.
+func ParsePointer(raw []byte) (Pointer, error) {
+	var ptr Pointer
+	if err := json.Unmarshal(raw, &ptr); err != nil {
+		return Pointer{}, fmt.Errorf("failed to parse embedding pointer: %w", err)
+	}
+	if ptr.SHA256 == "" {
+		return Pointer{}, fmt.Errorf("embedding pointer is missing sha256")
+	}
+	return ptr, nil
+}
+
+// shardedPath mirrors git's own loose object layout: the first two hex
+// characters of the digest name a subdirectory, keeping any one directory
+// from accumulating too many entries.
+func shardedPath(objectsDir, sha256Hex string) string {
+	return filepath.Join(objectsDir, sha256Hex[:2], sha256Hex[2:])
+}
+
+// Put stores vec in the content-addressed store rooted at objectsDir,
+// keyed by the SHA-256 of text (the source the embedding was generated
+// from), and returns the resulting Pointer. If an object already exists
+// for that key, it is left untouched and Put just returns its Pointer, so
+// identical notes across snapshots collide into a single stored vector.
+func Put(objectsDir, text string, vec []float64) (Pointer, error) {
+	sum := sha256.Sum256([]byte(text))
+	key := hex.EncodeToString(sum[:])
+	ptr := Pointer{SHA256: key, Dim: len(vec)}
+
+	path := shardedPath(objectsDir, key)
+	if _, err := os.Stat(path); err == nil {
+		return ptr, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return Pointer{}, fmt.Errorf("failed to create object directory: %w", err)
+	}
+	if err := WriteEmbedding(path, vec); err != nil {
+		return Pointer{}, fmt.Errorf("failed to write object %s: %w", key, err)
+	}
+	return ptr, nil
+}
+
+// Get reads the embedding stored at sha256Hex under objectsDir.
+func Get(objectsDir, sha256Hex string) ([]float64, error) {
+	path := shardedPath(objectsDir, sha256Hex)
+	vec, err := ReadEmbedding(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object %s: %w", sha256Hex, err)
+	}
+	return vec, nil
+}
+
+// Resolve decodes raw as a snapshot's embedding payload: if it's a
+// Pointer, the vector is fetched from the content-addressed store rooted
+// at objectsDir; otherwise raw is treated as a (pre-dedup) raw embedding
+// file, for backward compatibility with snapshots saved before Put/Get
+// existed.
+func Resolve(objectsDir string, raw []byte) ([]float64, error) {
+	if IsPointer(raw) {
+		ptr, err := ParsePointer(raw)
+		if err != nil {
+			return nil, err
+		}
+		return Get(objectsDir, ptr.SHA256)
+	}
+	return DecodeEmbeddingBytes(raw)
+}
+
+// Prune deletes every object under objectsDir whose SHA-256 key is not in
+// reachable, returning the number of objects removed. It's the
+// counterpart `git-context gc` runs after walking every snapshot/* ref to
+// build the reachable set.
+func Prune(objectsDir string, reachable map[string]bool) (int, error) {
+	removed := 0
+	shards, err := os.ReadDir(objectsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to list object store: %w", err)
+	}
+
+	for _, shard := range shards {
+		if !shard.IsDir() {
+			continue
+		}
+		shardDir := filepath.Join(objectsDir, shard.Name())
+		entries, err := os.ReadDir(shardDir)
+		if err != nil {
+			return removed, fmt.Errorf("failed to list shard %s: %w", shard.Name(), err)
+		}
+		for _, entry := range entries {
+			key := shard.Name() + entry.Name()
+			if reachable[key] {
+				continue
+			}
+			if err := os.Remove(filepath.Join(shardDir, entry.Name())); err != nil {
+				return removed, fmt.Errorf("failed to remove object %s: %w", key, err)
+			}
+			removed++
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,142 @@
+package embeddings
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPutGetRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	vec := generateTestEmbedding(8)
+
+	ptr, err := Put(dir, "some notes", vec)
+	if err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if ptr.Dim != len(vec) {
+		t.Errorf("expected Dim %d, got %d", len(vec), ptr.Dim)
+	}
+
+	got, err := Get(dir, ptr.SHA256)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	for i := range vec {
+		if got[i] != vec[i] {
+			t.Errorf("mismatch at %d: expected %v, got %v", i, vec[i], got[i])
+		}
+	}
+}
+
+func TestPutDeduplicatesIdenticalText(t *testing.T) {
+	dir := t.TempDir()
+	vec1 := generateTestEmbedding(4)
+	vec2 := []float64{9, 9, 9, 9}
+
+	ptr1, err := Put(dir, "same text", vec1)
+	if err != nil {
+		t.Fatalf("first Put failed: %v", err)
+	}
+	ptr2, err := Put(dir, "same text", vec2)
+	if err != nil {
+		t.Fatalf("second Put failed: %v", err)
+	}
+	if ptr1.SHA256 != ptr2.SHA256 {
+		t.Errorf("expected identical text to collide on the same key, got %s and %s", ptr1.SHA256, ptr2.SHA256)
+	}
+
+	// The second Put must not have overwritten the first object's vector.
+	got, err := Get(dir, ptr1.SHA256)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	for i := range vec1 {
+		if got[i] != vec1[i] {
+			t.Errorf("expected the first-written vector to be preserved, got %v want %v", got, vec1)
+			break
+		}
+	}
+}
+
+func TestIsPointer(t *testing.T) {
+	if !IsPointer([]byte(`{"sha256":"abc","dim":3}`)) {
+		t.Error("expected JSON pointer to be recognized")
+	}
+	if IsPointer([]byte("CTXE\x01\x00\x00\x00")) {
+		t.Error("expected a CTXE-framed file not to be recognized as a pointer")
+	}
+}
+
+func TestResolvePointerAndRaw(t *testing.T) {
+	dir := t.TempDir()
+	vec := generateTestEmbedding(5)
+
+	ptr, err := Put(dir, "resolve me", vec)
+	if err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	ptrJSON, err := json.Marshal(ptr)
+	if err != nil {
+		t.Fatalf("failed to marshal pointer: %v", err)
+	}
+
+	got, err := Resolve(dir, ptrJSON)
+	if err != nil {
+		t.Fatalf("Resolve(pointer) failed: %v", err)
+	}
+	for i := range vec {
+		if got[i] != vec[i] {
+			t.Errorf("mismatch at %d resolving pointer: expected %v, got %v", i, vec[i], got[i])
+		}
+	}
+
+	// A pre-dedup raw embedding file must still resolve directly.
+	rawPath := filepath.Join(dir, "raw.bin")
+	if err := WriteEmbedding(rawPath, vec); err != nil {
+		t.Fatalf("failed to write raw embedding: %v", err)
+	}
+	raw, err := os.ReadFile(rawPath)
+	if err != nil {
+		t.Fatalf("failed to read raw embedding: %v", err)
+	}
+	got, err = Resolve(dir, raw)
+	if err != nil {
+		t.Fatalf("Resolve(raw) failed: %v", err)
+	}
+	for i := range vec {
+		if got[i] != vec[i] {
+			t.Errorf("mismatch at %d resolving raw embedding: expected %v, got %v", i, vec[i], got[i])
+		}
+	}
+}
+
+func TestPrune(t *testing.T) {
+	dir := t.TempDir()
+	vec := generateTestEmbedding(3)
+
+	keep, err := Put(dir, "keep me", vec)
+	if err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	drop, err := Put(dir, "drop me", vec)
+	if err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	removed, err := Prune(dir, map[string]bool{keep.SHA256: true})
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("expected 1 object removed, got %d", removed)
+	}
+
+	if _, err := Get(dir, keep.SHA256); err != nil {
+		t.Errorf("expected kept object to survive prune: %v", err)
+	}
+	if _, err := Get(dir, drop.SHA256); err == nil {
+		t.Error("expected dropped object to be removed by prune")
+	}
+}
@@ -0,0 +1,116 @@
+package embeddings
+
+import "strings"
+
+const (
+	// ChunkWindow is the target size, in characters, of a single chunk.
+	ChunkWindow = 1500
+	// ChunkOverlap is how many characters of a chunk are repeated at the
+	// start of the next one, so a match near a window boundary still
+	// appears whole in at least one chunk.
+	ChunkOverlap = 200
+)
+
+// Chunk is a contiguous window of a source file selected for embedding,
+// mirroring one row of chunks.jsonl.
+type Chunk struct {
+	Path    string `json:"path"`
+	Start   int    `json:"start"`
+	End     int    `json:"end"`
+	Heading string `json:"heading,omitempty"`
+}
+
+// sectionBreak is a natural split point within a file: a byte offset and
+// the Markdown heading or Go top-level declaration line found there.
+type sectionBreak struct {
+	offset int
+	text   string
+}
+
+// ChunkText splits content into overlapping windows of up to ChunkWindow
+// characters with ChunkOverlap characters shared between consecutive
+// chunks. A window's end is pulled back to the nearest Markdown heading
+// (#, ##, ...) or Go top-level declaration (func/type/const/var) that
+// falls in its second half, so chunks align with natural section breaks
+// rather than splitting mid-paragraph or mid-declaration. Each chunk's
+// Heading is the most recent such line at or before its start.
+func ChunkText(path, content string) []Chunk {
+	if content == "" {
+		return nil
+	}
+
+	breaks := sectionBreaks(content)
+
+	var chunks []Chunk
+	start := 0
+	for start < len(content) {
+		end := start + ChunkWindow
+		if end >= len(content) {
+			end = len(content)
+		} else if b := lastBreakInRange(breaks, start+ChunkWindow/2, end); b > start {
+			end = b
+		}
+
+		chunks = append(chunks, Chunk{
+			Path:    path,
+			Start:   start,
+			End:     end,
+			Heading: headingAt(breaks, start),
+		})
+
+		if end >= len(content) {
+			break
+		}
+		next := end - ChunkOverlap
+		if next <= start {
+			next = end
+		}
+		start = next
+	}
+
+	return chunks
+}
+
+// sectionBreaks locates every Markdown heading and Go top-level
+// declaration line in content, in order of appearance.
+func sectionBreaks(content string) []sectionBreak {
+	var breaks []sectionBreak
+	offset := 0
+	for _, line := range strings.SplitAfter(content, "\n") {
+		stripped := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(stripped, "#"):
+			breaks = append(breaks, sectionBreak{offset: offset, text: stripped})
+		case strings.HasPrefix(stripped, "func "), strings.HasPrefix(stripped, "type "),
+			strings.HasPrefix(stripped, "const "), strings.HasPrefix(stripped, "var "):
+			breaks = append(breaks, sectionBreak{offset: offset, text: stripped})
+		}
+		offset += len(line)
+	}
+	return breaks
+}
+
+// lastBreakInRange returns the offset of the last break in (from, to], or
+// 0 if none falls in range.
+func lastBreakInRange(breaks []sectionBreak, from, to int) int {
+	found := 0
+	for _, b := range breaks {
+		if b.offset > from && b.offset <= to {
+			found = b.offset
+		}
+	}
+	return found
+}
+
+// headingAt returns the text of the most recent section break at or
+// before offset, or "" if there is none.
+func headingAt(breaks []sectionBreak, offset int) string {
+	heading := ""
+	for _, b := range breaks {
+		if b.offset > offset {
+			break
+		}
+		heading = b.text
+	}
+	return heading
+}
@@ -0,0 +1,90 @@
+package embeddings
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChunkTextEmpty(t *testing.T) {
+	if chunks := ChunkText("notes.md", ""); chunks != nil {
+		t.Errorf("expected nil chunks for empty content, got %v", chunks)
+	}
+}
+
+func TestChunkTextSingleChunk(t *testing.T) {
+	content := "short content that fits in one window"
+	chunks := ChunkText("notes.md", content)
+
+	if len(chunks) != 1 {
+		t.Fatalf("expected 1 chunk, got %d", len(chunks))
+	}
+	if chunks[0].Start != 0 || chunks[0].End != len(content) {
+		t.Errorf("expected chunk to span the whole content, got [%d:%d]", chunks[0].Start, chunks[0].End)
+	}
+	if chunks[0].Path != "notes.md" {
+		t.Errorf("expected path %q, got %q", "notes.md", chunks[0].Path)
+	}
+}
+
+func TestChunkTextOverlapsAndCoversContent(t *testing.T) {
+	content := strings.Repeat("word ", 1000) // 5000 chars, well over one window
+	chunks := ChunkText("notes.md", content)
+
+	if len(chunks) < 2 {
+		t.Fatalf("expected multiple chunks for long content, got %d", len(chunks))
+	}
+
+	if chunks[0].Start != 0 {
+		t.Errorf("expected first chunk to start at 0, got %d", chunks[0].Start)
+	}
+	if last := chunks[len(chunks)-1]; last.End != len(content) {
+		t.Errorf("expected last chunk to reach end of content, got %d (want %d)", last.End, len(content))
+	}
+
+	for i := 1; i < len(chunks); i++ {
+		if chunks[i].Start >= chunks[i-1].End {
+			t.Errorf("chunk %d starts at %d, expected overlap with previous chunk ending at %d", i, chunks[i].Start, chunks[i-1].End)
+		}
+	}
+}
+
+func TestChunkTextTracksHeadings(t *testing.T) {
+	content := "# Intro\n" + strings.Repeat("intro text ", 200) +
+		"\n## Details\n" + strings.Repeat("details text ", 200)
+
+	chunks := ChunkText("notes.md", content)
+	if len(chunks) < 2 {
+		t.Fatalf("expected multiple chunks, got %d", len(chunks))
+	}
+
+	if chunks[0].Heading != "# Intro" {
+		t.Errorf("expected first chunk heading %q, got %q", "# Intro", chunks[0].Heading)
+	}
+
+	foundDetails := false
+	for _, c := range chunks {
+		if c.Heading == "## Details" {
+			foundDetails = true
+		}
+	}
+	if !foundDetails {
+		t.Error("expected a later chunk to carry the '## Details' heading")
+	}
+}
+
+func TestChunkTextGoDeclarations(t *testing.T) {
+	content := strings.Repeat("// filler\n", 150) +
+		"func Example() {\n" + strings.Repeat("\tdoSomething()\n", 150) + "}\n"
+
+	chunks := ChunkText("example.go", content)
+
+	foundFunc := false
+	for _, c := range chunks {
+		if c.Heading == "func Example() {" {
+			foundFunc = true
+		}
+	}
+	if !foundFunc {
+		t.Error("expected a chunk to carry the 'func Example() {' heading")
+	}
+}
@@ -0,0 +1,151 @@
+package embeddings
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// EncodeDelta computes a quantized delta of target against base, the
+// format used to store a snapshot's embedding once a near-duplicate prior
+// embedding has been selected as its base (see SelectBase). Each
+// dimension's difference is quantized to an int16 using a single
+// per-vector scale factor, and runs of zero-valued quantized diffs
+// (common when the embedding model produced nearly identical output) are
+// varint run-length encoded rather than stored individually.
+//
+// base and target must have the same length. The returned scale
+// reconstructs float diffs as quantized * scale; DecodeDelta reverses the
+// encoding given the same base and scale.
+func EncodeDelta(base, target []float64) (delta []byte, scale float64, err error) {
+	if len(base) != len(target) {
+		return nil, 0, fmt.Errorf("base and target must have the same length: %d vs %d", len(base), len(target))
+	}
+	if len(base) == 0 {
+		return nil, 0, fmt.Errorf("vectors cannot be empty")
+	}
+
+	diffs := make([]float64, len(base))
+	maxAbs := 0.0
+	for i := range base {
+		diffs[i] = target[i] - base[i]
+		if a := math.Abs(diffs[i]); a > maxAbs {
+			maxAbs = a
+		}
+	}
+
+	if maxAbs == 0 {
+		// target == base exactly: an empty delta with scale 1 decodes to a
+		// plain copy of base.
+		return nil, 1, nil
+	}
+	scale = maxAbs / math.MaxInt16
+
+	quantized := make([]int16, len(diffs))
+	for i, d := range diffs {
+		quantized[i] = int16(math.Round(d / scale))
+	}
+
+	i := 0
+	for i < len(quantized) {
+		if quantized[i] == 0 {
+			run := 0
+			for i < len(quantized) && quantized[i] == 0 {
+				run++
+				i++
+			}
+			delta = append(delta, 0, 0) // zero marker, unambiguous: a literal non-zero int16 never serializes as 0x00 0x00
+			delta = appendVarint(delta, uint64(run))
+			continue
+		}
+		var tmp [2]byte
+		binary.LittleEndian.PutUint16(tmp[:], uint16(quantized[i]))
+		delta = append(delta, tmp[:]...)
+		i++
+	}
+
+	return delta, scale, nil
+}
+
+// DecodeDelta reconstructs a target vector from base and a delta produced
+// by EncodeDelta against the same base and scale.
+func DecodeDelta(base []float64, delta []byte, scale float64) ([]float64, error) {
+	result := make([]float64, len(base))
+
+	if len(delta) == 0 {
+		copy(result, base)
+		return result, nil
+	}
+
+	pos := 0
+	out := 0
+	for pos < len(delta) {
+		if pos+2 > len(delta) {
+			return nil, fmt.Errorf("truncated delta at byte %d", pos)
+		}
+		raw := binary.LittleEndian.Uint16(delta[pos : pos+2])
+		pos += 2
+
+		if raw == 0 {
+			run, n := binary.Uvarint(delta[pos:])
+			if n <= 0 {
+				return nil, fmt.Errorf("invalid zero-run varint at byte %d", pos)
+			}
+			pos += n
+			if out+int(run) > len(result) {
+				return nil, fmt.Errorf("delta decodes past base length %d", len(base))
+			}
+			for j := uint64(0); j < run; j++ {
+				result[out] = base[out]
+				out++
+			}
+			continue
+		}
+
+		if out >= len(result) {
+			return nil, fmt.Errorf("delta decodes past base length %d", len(base))
+		}
+		result[out] = base[out] + float64(int16(raw))*scale
+		out++
+	}
+
+	if out != len(result) {
+		return nil, fmt.Errorf("delta covers %d dimension(s), expected %d", out, len(result))
+	}
+
+	return result, nil
+}
+
+func appendVarint(buf []byte, v uint64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	return append(buf, tmp[:n]...)
+}
+
+// BaseCandidate is a prior embedding eligible to serve as another
+// snapshot's delta base.
+type BaseCandidate struct {
+	Ref    string
+	Vector []float64
+}
+
+// SelectBase picks the most cosine-similar candidate to target, mirroring
+// go-git's delta-selector approach: rather than diffing against every
+// prior object, the caller pre-windows candidates (by recency, topic, or
+// size) and SelectBase scores only that window. Returns ok=false if
+// candidates is empty or none are comparable (mismatched dimensionality).
+func SelectBase(target []float64, candidates []BaseCandidate) (best BaseCandidate, similarity float64, ok bool) {
+	bestSim := -2.0 // below the valid [-1, 1] range of CosineSimilarity
+	for _, c := range candidates {
+		sim, err := CosineSimilarity(target, c.Vector)
+		if err != nil {
+			continue
+		}
+		if sim > bestSim {
+			bestSim = sim
+			best = c
+			ok = true
+		}
+	}
+	return best, bestSim, ok
+}
@@ -0,0 +1,114 @@
+package embeddings
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEncodeDecodeDeltaRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		base   []float64
+		target []float64
+	}{
+		{
+			name:   "identical vectors",
+			base:   []float64{1.0, 2.0, 3.0},
+			target: []float64{1.0, 2.0, 3.0},
+		},
+		{
+			name:   "small drift",
+			base:   []float64{0.1, 0.2, 0.3, 0.4},
+			target: []float64{0.1, 0.2, 0.35, 0.4},
+		},
+		{
+			name:   "many zero-diff dimensions",
+			base:   make([]float64, 32),
+			target: func() []float64 { v := make([]float64, 32); v[10] = 0.01; v[20] = -0.02; return v }(),
+		},
+		{
+			name:   "large drift",
+			base:   []float64{-1.0, 0.5, 2.0},
+			target: []float64{3.0, -4.0, 0.25},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delta, scale, err := EncodeDelta(tt.base, tt.target)
+			if err != nil {
+				t.Fatalf("EncodeDelta failed: %v", err)
+			}
+
+			result, err := DecodeDelta(tt.base, delta, scale)
+			if err != nil {
+				t.Fatalf("DecodeDelta failed: %v", err)
+			}
+
+			if len(result) != len(tt.target) {
+				t.Fatalf("expected length %d, got %d", len(tt.target), len(result))
+			}
+
+			maxAbs := 0.0
+			for _, v := range tt.target {
+				if a := math.Abs(v); a > maxAbs {
+					maxAbs = a
+				}
+			}
+			tolerance := maxAbs/math.MaxInt16 + 1e-9
+			for i := range result {
+				if math.Abs(result[i]-tt.target[i]) > tolerance {
+					t.Errorf("dimension %d: expected %v, got %v (tolerance %v)", i, tt.target[i], result[i], tolerance)
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeDeltaMismatchedLength(t *testing.T) {
+	_, _, err := EncodeDelta([]float64{1.0, 2.0}, []float64{1.0, 2.0, 3.0})
+	if err == nil {
+		t.Error("expected error for mismatched vector lengths")
+	}
+}
+
+func TestEncodeDeltaEmpty(t *testing.T) {
+	_, _, err := EncodeDelta([]float64{}, []float64{})
+	if err == nil {
+		t.Error("expected error for empty vectors")
+	}
+}
+
+func TestDecodeDeltaTruncated(t *testing.T) {
+	_, err := DecodeDelta([]float64{1.0, 2.0, 3.0}, []byte{0x01}, 1.0)
+	if err == nil {
+		t.Error("expected error for truncated delta")
+	}
+}
+
+func TestSelectBase(t *testing.T) {
+	target := []float64{1.0, 2.0, 3.0}
+	candidates := []BaseCandidate{
+		{Ref: "far", Vector: []float64{-1.0, -2.0, -3.0}},
+		{Ref: "close", Vector: []float64{1.0, 2.0, 3.01}},
+		{Ref: "mismatched-dim", Vector: []float64{1.0, 2.0}},
+	}
+
+	best, sim, ok := SelectBase(target, candidates)
+	if !ok {
+		t.Fatal("expected a base to be selected")
+	}
+	if best.Ref != "close" {
+		t.Errorf("expected closest candidate %q, got %q", "close", best.Ref)
+	}
+	if sim < 0.99 {
+		t.Errorf("expected high similarity for closest candidate, got %f", sim)
+	}
+}
+
+func TestSelectBaseNoCandidates(t *testing.T) {
+	_, _, ok := SelectBase([]float64{1.0, 2.0}, nil)
+	if ok {
+		t.Error("expected ok=false with no candidates")
+	}
+}
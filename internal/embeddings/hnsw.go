@@ -0,0 +1,479 @@
+package embeddings
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Default HNSW construction parameters (Malkov & Yashunin, "Efficient and
+// robust approximate nearest neighbor search using Hierarchical Navigable
+// Small World graphs"). M is the target number of bidirectional links per
+// node per layer; efConstruction is the beam width used while inserting.
+const (
+	DefaultM              = 16
+	DefaultEfConstruction = 200
+)
+
+// indexMagic identifies an on-disk HNSW index file ("CTHN" = context HNSW).
+var indexMagic = [4]byte{'C', 'T', 'H', 'N'}
+
+const (
+	indexHeaderSize    = 32
+	indexFormatVersion = 1
+)
+
+// Result is one match returned by Index.Search, ordered nearest-first by
+// cosine distance (1 - cosine similarity, so 0 is identical).
+type Result struct {
+	ID       uint32
+	Distance float64
+}
+
+// indexNode is one inserted vector plus its neighbor lists, one list per
+// layer from 0 (the dense base layer every node belongs to) up to the
+// node's assigned max layer.
+type indexNode struct {
+	id     uint32
+	vec    []float64
+	layers [][]uint32
+}
+
+// Index is an in-memory HNSW graph over embedding vectors, built
+// incrementally via Add and queried via Search. Flush persists it to Path;
+// OpenIndex reads it back. The zero value is not usable — construct with
+// NewIndex or OpenIndex.
+type Index struct {
+	Path           string
+	Dim            int
+	M              int
+	EfConstruction int
+
+	nodes      map[uint32]*indexNode
+	entryPoint uint32
+	hasEntry   bool
+	topLayer   int
+
+	rng   *rand.Rand
+	dirty bool
+}
+
+// NewIndex creates an empty HNSW index over dim-dimensional vectors at
+// path, using the default M and efConstruction. Callers add vectors with
+// Add and persist with Flush.
+func NewIndex(path string, dim int) *Index {
+	return &Index{
+		Path:           path,
+		Dim:            dim,
+		M:              DefaultM,
+		EfConstruction: DefaultEfConstruction,
+		nodes:          make(map[uint32]*indexNode),
+		topLayer:       -1,
+		rng:            rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// mL is the layer-assignment normalization factor 1/ln(M), the standard
+// HNSW choice that keeps the expected number of layers logarithmic in the
+// number of inserted nodes.
+func (idx *Index) mL() float64 {
+	return 1 / math.Log(float64(idx.M))
+}
+
+// randomLayer draws a node's max layer l = floor(-ln(rand()) * mL).
+func (idx *Index) randomLayer() int {
+	r := idx.rng.Float64()
+	for r == 0 {
+		r = idx.rng.Float64()
+	}
+	return int(math.Floor(-math.Log(r) * idx.mL()))
+}
+
+// Add inserts vec under id into the graph. Re-adding an existing id
+// replaces its vector and links it back into the graph as if it were new;
+// callers that only ever insert fresh ids (the common case) never hit
+// this path.
+func (idx *Index) Add(id uint32, vec []float64) error {
+	if len(vec) != idx.Dim {
+		return fmt.Errorf("vector has dimension %d, index expects %d", len(vec), idx.Dim)
+	}
+
+	idx.dirty = true
+	layer := idx.randomLayer()
+	node := &indexNode{id: id, vec: vec, layers: make([][]uint32, layer+1)}
+	idx.nodes[id] = node
+
+	if !idx.hasEntry {
+		idx.entryPoint = id
+		idx.hasEntry = true
+		idx.topLayer = layer
+		return nil
+	}
+
+	entry := idx.entryPoint
+	entryDist := idx.distance(vec, idx.nodes[entry].vec)
+
+	// Greedy-descend from the top layer down to layer+1, keeping only the
+	// single closest node found at each layer as the entry point one layer
+	// down.
+	for l := idx.topLayer; l > layer; l-- {
+		entry, entryDist = idx.greedyClosest(vec, entry, entryDist, l)
+	}
+
+	// From min(layer, topLayer) down to 0, beam-search for efConstruction
+	// candidates, prune to M neighbors, and link bidirectionally.
+	for l := min(layer, idx.topLayer); l >= 0; l-- {
+		candidates := idx.searchLayer(vec, entry, idx.EfConstruction, l)
+		neighbors := idx.selectNeighbors(vec, candidates, idx.M)
+
+		node.layers[l] = neighbors
+		for _, nid := range neighbors {
+			idx.link(nid, id, l)
+		}
+
+		if len(candidates) > 0 {
+			entry = candidates[0].ID
+		}
+	}
+
+	if layer > idx.topLayer {
+		idx.topLayer = layer
+		idx.entryPoint = id
+	}
+
+	return nil
+}
+
+// link adds a bidirectional edge from->to at layer, re-pruning from's
+// neighbor list with the same heuristic as insertion if it now exceeds M.
+func (idx *Index) link(from, to uint32, layer int) {
+	n, ok := idx.nodes[from]
+	if !ok || layer >= len(n.layers) {
+		return
+	}
+	for _, existing := range n.layers[layer] {
+		if existing == to {
+			return
+		}
+	}
+	n.layers[layer] = append(n.layers[layer], to)
+
+	if len(n.layers[layer]) <= idx.M {
+		return
+	}
+
+	candidates := make([]Result, 0, len(n.layers[layer]))
+	for _, nid := range n.layers[layer] {
+		candidates = append(candidates, Result{ID: nid, Distance: idx.distance(n.vec, idx.nodes[nid].vec)})
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Distance < candidates[j].Distance })
+	n.layers[layer] = idx.selectNeighbors(n.vec, candidates, idx.M)
+}
+
+// greedyClosest returns the neighbor of entry (at layer) closest to vec,
+// repeating until no neighbor improves on the current best — i.e. a
+// single-candidate beam search, the standard HNSW descent used above the
+// insertion/query layer.
+func (idx *Index) greedyClosest(vec []float64, entry uint32, entryDist float64, layer int) (uint32, float64) {
+	best, bestDist := entry, entryDist
+	for {
+		improved := false
+		n := idx.nodes[best]
+		if layer >= len(n.layers) {
+			break
+		}
+		for _, nid := range n.layers[layer] {
+			d := idx.distance(vec, idx.nodes[nid].vec)
+			if d < bestDist {
+				best, bestDist = nid, d
+				improved = true
+			}
+		}
+		if !improved {
+			break
+		}
+	}
+	return best, bestDist
+}
+
+// searchLayer runs a beam search of width ef at layer starting from entry,
+// returning up to ef candidates sorted nearest-first.
+func (idx *Index) searchLayer(vec []float64, entry uint32, ef, layer int) []Result {
+	visited := map[uint32]bool{entry: true}
+	entryDist := idx.distance(vec, idx.nodes[entry].vec)
+
+	candidates := []Result{{ID: entry, Distance: entryDist}}
+	found := []Result{{ID: entry, Distance: entryDist}}
+
+	for len(candidates) > 0 {
+		sort.Slice(candidates, func(i, j int) bool { return candidates[i].Distance < candidates[j].Distance })
+		c := candidates[0]
+		candidates = candidates[1:]
+
+		worstFound := found[len(found)-1].Distance
+		if len(found) >= ef && c.Distance > worstFound {
+			break
+		}
+
+		n := idx.nodes[c.ID]
+		if layer >= len(n.layers) {
+			continue
+		}
+		for _, nid := range n.layers[layer] {
+			if visited[nid] {
+				continue
+			}
+			visited[nid] = true
+			d := idx.distance(vec, idx.nodes[nid].vec)
+
+			if len(found) < ef || d < found[len(found)-1].Distance {
+				candidates = append(candidates, Result{ID: nid, Distance: d})
+				found = append(found, Result{ID: nid, Distance: d})
+				sort.Slice(found, func(i, j int) bool { return found[i].Distance < found[j].Distance })
+				if len(found) > ef {
+					found = found[:ef]
+				}
+			}
+		}
+	}
+
+	return found
+}
+
+// selectNeighbors prunes candidates down to at most m neighbor ids using
+// the HNSW heuristic: walk candidates nearest-first, and accept one only
+// if it is closer to vec than to every neighbor already selected. This
+// favors neighbors spread across diverse directions over a cluster of
+// near-duplicates, which is what keeps the graph navigable.
+func (idx *Index) selectNeighbors(vec []float64, candidates []Result, m int) []uint32 {
+	sorted := append([]Result(nil), candidates...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Distance < sorted[j].Distance })
+
+	selected := make([]uint32, 0, m)
+	for _, c := range sorted {
+		if len(selected) >= m {
+			break
+		}
+		cVec := idx.nodes[c.ID].vec
+		diverse := true
+		for _, sid := range selected {
+			if idx.distance(cVec, idx.nodes[sid].vec) < c.Distance {
+				diverse = false
+				break
+			}
+		}
+		if diverse {
+			selected = append(selected, c.ID)
+		}
+	}
+	return selected
+}
+
+// Search returns the k nearest neighbors of vec, beam-searching layer 0
+// with width ef (ef should be >= k; larger ef trades speed for recall).
+func (idx *Index) Search(vec []float64, k, ef int) []Result {
+	if !idx.hasEntry {
+		return nil
+	}
+	if ef < k {
+		ef = k
+	}
+
+	entry := idx.entryPoint
+	entryDist := idx.distance(vec, idx.nodes[entry].vec)
+	for l := idx.topLayer; l > 0; l-- {
+		entry, entryDist = idx.greedyClosest(vec, entry, entryDist, l)
+	}
+
+	candidates := idx.searchLayer(vec, entry, ef, 0)
+	if len(candidates) > k {
+		candidates = candidates[:k]
+	}
+	return candidates
+}
+
+func (idx *Index) distance(a, b []float64) float64 {
+	sim, err := CosineSimilarity(a, b)
+	if err != nil {
+		return math.Inf(1)
+	}
+	return 1 - sim
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// Flush persists the index to Path via a temp-file-and-rename so readers
+// never observe a partially-written file.
+func (idx *Index) Flush() error {
+	if err := os.MkdirAll(filepath.Dir(idx.Path), 0755); err != nil {
+		return fmt.Errorf("failed to create index directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(idx.Path), ".hnsw-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temp index file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if err := idx.encode(tmp); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temp index file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, idx.Path); err != nil {
+		return fmt.Errorf("failed to finalize index file: %w", err)
+	}
+	idx.dirty = false
+	return nil
+}
+
+func (idx *Index) encode(w io.Writer) error {
+	header := make([]byte, indexHeaderSize)
+	copy(header[0:4], indexMagic[:])
+	binary.LittleEndian.PutUint16(header[4:6], indexFormatVersion)
+	binary.LittleEndian.PutUint32(header[6:10], uint32(idx.Dim))
+	binary.LittleEndian.PutUint16(header[10:12], uint16(idx.M))
+	binary.LittleEndian.PutUint16(header[12:14], uint16(idx.EfConstruction))
+	binary.LittleEndian.PutUint32(header[14:18], idx.entryPoint)
+	binary.LittleEndian.PutUint16(header[18:20], uint16(idx.topLayer+1))
+	binary.LittleEndian.PutUint32(header[20:24], uint32(len(idx.nodes)))
+	if idx.hasEntry {
+		header[24] = 1
+	}
+	// header[25:32] is reserved and left zeroed
+
+	bw := bufio.NewWriter(w)
+	if _, err := bw.Write(header); err != nil {
+		return err
+	}
+
+	varint := make([]byte, binary.MaxVarintLen64)
+	for _, n := range idx.nodes {
+		if err := binary.Write(bw, binary.LittleEndian, n.id); err != nil {
+			return err
+		}
+		for _, v := range n.vec {
+			if err := binary.Write(bw, binary.LittleEndian, v); err != nil {
+				return err
+			}
+		}
+
+		ln := binary.PutUvarint(varint, uint64(len(n.layers)))
+		if _, err := bw.Write(varint[:ln]); err != nil {
+			return err
+		}
+		for _, neighbors := range n.layers {
+			ln := binary.PutUvarint(varint, uint64(len(neighbors)))
+			if _, err := bw.Write(varint[:ln]); err != nil {
+				return err
+			}
+			for _, nid := range neighbors {
+				ln := binary.PutUvarint(varint, uint64(nid))
+				if _, err := bw.Write(varint[:ln]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return bw.Flush()
+}
+
+// OpenIndex reads the HNSW index at path. A missing file is not an error —
+// it returns a fresh empty index over dim-dimensional vectors, ready for
+// Add, so a first-time caller doesn't need a separate "does it exist yet"
+// branch. A file that exists but fails header or structural validation is
+// reported as an error so the caller can fall back to brute-force search
+// instead of operating on a corrupt graph.
+func OpenIndex(path string, dim int) (*Index, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return NewIndex(path, dim), nil
+		}
+		return nil, fmt.Errorf("failed to open index file: %w", err)
+	}
+
+	idx, err := decodeIndex(path, raw)
+	if err != nil {
+		return nil, fmt.Errorf("index file is corrupt: %w", err)
+	}
+	return idx, nil
+}
+
+func decodeIndex(path string, raw []byte) (*Index, error) {
+	if len(raw) < indexHeaderSize || string(raw[0:4]) != string(indexMagic[:]) {
+		return nil, fmt.Errorf("missing or invalid index header")
+	}
+
+	dim := binary.LittleEndian.Uint32(raw[6:10])
+	idx := &Index{
+		Path:           path,
+		Dim:            int(dim),
+		M:              int(binary.LittleEndian.Uint16(raw[10:12])),
+		EfConstruction: int(binary.LittleEndian.Uint16(raw[12:14])),
+		entryPoint:     binary.LittleEndian.Uint32(raw[14:18]),
+		topLayer:       int(binary.LittleEndian.Uint16(raw[18:20])) - 1,
+		hasEntry:       raw[24] == 1,
+		nodes:          make(map[uint32]*indexNode),
+		rng:            rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	count := int(binary.LittleEndian.Uint32(raw[20:24]))
+
+	r := bufio.NewReader(bytes.NewReader(raw[indexHeaderSize:]))
+	for i := 0; i < count; i++ {
+		var id uint32
+		if err := binary.Read(r, binary.LittleEndian, &id); err != nil {
+			return nil, fmt.Errorf("failed to read node %d id: %w", i, err)
+		}
+
+		vec := make([]float64, idx.Dim)
+		for j := range vec {
+			if err := binary.Read(r, binary.LittleEndian, &vec[j]); err != nil {
+				return nil, fmt.Errorf("failed to read node %d vector: %w", id, err)
+			}
+		}
+
+		layerCount, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read node %d layer count: %w", id, err)
+		}
+		layers := make([][]uint32, layerCount)
+		for l := range layers {
+			neighborCount, err := binary.ReadUvarint(r)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read node %d layer %d neighbor count: %w", id, l, err)
+			}
+			neighbors := make([]uint32, neighborCount)
+			for k := range neighbors {
+				nid, err := binary.ReadUvarint(r)
+				if err != nil {
+					return nil, fmt.Errorf("failed to read node %d layer %d neighbor %d: %w", id, l, k, err)
+				}
+				neighbors[k] = uint32(nid)
+			}
+			layers[l] = neighbors
+		}
+
+		idx.nodes[id] = &indexNode{id: id, vec: vec, layers: layers}
+	}
+
+	return idx, nil
+}
@@ -0,0 +1,116 @@
+package embeddings
+
+import (
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func randomUnitVector(dim int, r *rand.Rand) []float64 {
+	vec := make([]float64, dim)
+	for i := range vec {
+		vec[i] = r.Float64()*2 - 1
+	}
+	norm, err := Normalize(vec)
+	if err != nil {
+		return vec
+	}
+	return norm
+}
+
+func TestIndexAddAndSearchFindsNearestNeighbor(t *testing.T) {
+	idx := NewIndex(filepath.Join(t.TempDir(), "index.bin"), 4)
+
+	target := []float64{1, 0, 0, 0}
+	if err := idx.Add(0, target); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	r := rand.New(rand.NewSource(42))
+	for i := uint32(1); i <= 50; i++ {
+		if err := idx.Add(i, randomUnitVector(4, r)); err != nil {
+			t.Fatalf("Add(%d) failed: %v", i, err)
+		}
+	}
+
+	results := idx.Search(target, 1, 50)
+	if len(results) == 0 {
+		t.Fatal("expected at least one result")
+	}
+	if results[0].ID != 0 {
+		t.Errorf("expected nearest neighbor to be id 0, got %d (distance %v)", results[0].ID, results[0].Distance)
+	}
+}
+
+func TestIndexAddRejectsWrongDimension(t *testing.T) {
+	idx := NewIndex(filepath.Join(t.TempDir(), "index.bin"), 4)
+	if err := idx.Add(0, []float64{1, 2, 3}); err == nil {
+		t.Error("expected an error for a vector with the wrong dimension")
+	}
+}
+
+func TestIndexSearchOnEmptyIndex(t *testing.T) {
+	idx := NewIndex(filepath.Join(t.TempDir(), "index.bin"), 4)
+	if results := idx.Search([]float64{1, 0, 0, 0}, 5, 10); results != nil {
+		t.Errorf("expected nil results on an empty index, got %v", results)
+	}
+}
+
+func TestIndexFlushAndOpenRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.bin")
+	idx := NewIndex(path, 3)
+
+	r := rand.New(rand.NewSource(7))
+	for i := uint32(0); i < 20; i++ {
+		if err := idx.Add(i, randomUnitVector(3, r)); err != nil {
+			t.Fatalf("Add(%d) failed: %v", i, err)
+		}
+	}
+
+	if err := idx.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	reopened, err := OpenIndex(path, 3)
+	if err != nil {
+		t.Fatalf("OpenIndex failed: %v", err)
+	}
+	if len(reopened.nodes) != len(idx.nodes) {
+		t.Errorf("expected %d nodes after reopening, got %d", len(idx.nodes), len(reopened.nodes))
+	}
+
+	query := []float64{1, 0, 0}
+	before := idx.Search(query, 3, 20)
+	after := reopened.Search(query, 3, 20)
+	if len(before) != len(after) {
+		t.Fatalf("expected %d results after reopening, got %d", len(before), len(after))
+	}
+	for i := range before {
+		if before[i].ID != after[i].ID {
+			t.Errorf("result %d: expected id %d after reopening, got %d", i, before[i].ID, after[i].ID)
+		}
+	}
+}
+
+func TestOpenIndexMissingFileReturnsEmptyIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "does-not-exist.bin")
+	idx, err := OpenIndex(path, 8)
+	if err != nil {
+		t.Fatalf("expected no error for a missing index file, got %v", err)
+	}
+	if idx.Search([]float64{1}, 1, 1) != nil {
+		t.Error("expected a fresh index to have no results")
+	}
+}
+
+func TestOpenIndexCorruptFileReturnsError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "corrupt.bin")
+	if err := os.WriteFile(path, []byte("not an index file"), 0644); err != nil {
+		t.Fatalf("failed to write corrupt file: %v", err)
+	}
+
+	if _, err := OpenIndex(path, 8); err == nil {
+		t.Error("expected an error for a corrupt index file")
+	}
+}
@@ -0,0 +1,32 @@
+//go:build !unix
+
+package embeddings
+
+import "fmt"
+
+// MmapEmbedding mirrors the unix implementation's shape on platforms
+// without mmap support, falling back to a plain heap-allocated vector.
+type MmapEmbedding struct {
+	Vector []float32
+}
+
+// Close is a no-op on platforms without mmap support.
+func (m *MmapEmbedding) Close() error {
+	return nil
+}
+
+// ReadEmbeddingMmap falls back to a regular heap-allocated read on
+// platforms without mmap support.
+func ReadEmbeddingMmap(path string) (*MmapEmbedding, error) {
+	vec, err := ReadEmbedding(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedding: %w", err)
+	}
+
+	vec32 := make([]float32, len(vec))
+	for i, v := range vec {
+		vec32[i] = float32(v)
+	}
+
+	return &MmapEmbedding{Vector: vec32}, nil
+}
@@ -0,0 +1,85 @@
+//go:build unix
+
+package embeddings
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// MmapEmbedding is a header-framed embedding file whose payload is backed
+// by an mmap'd region rather than a heap allocation, for hybrid-search
+// scans over hundreds of snapshots.
+type MmapEmbedding struct {
+	data   []byte
+	Vector []float32
+}
+
+// Close unmaps the underlying memory region. The returned Vector must not
+// be used after Close.
+func (m *MmapEmbedding) Close() error {
+	if m.data == nil {
+		return nil
+	}
+	err := syscall.Munmap(m.data)
+	m.data = nil
+	m.Vector = nil
+	return err
+}
+
+// ReadEmbeddingMmap opens a header-framed embedding file and returns a
+// []float32 view over its mmap'd payload, avoiding a full heap copy of the
+// vector. Only the float32 dtype can be served without conversion; other
+// dtypes fall back to an in-memory decode.
+func ReadEmbeddingMmap(path string) (*MmapEmbedding, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open embedding file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat embedding file: %w", err)
+	}
+	size := int(info.Size())
+	if size <= headerSize {
+		return nil, fmt.Errorf("embedding file too small to contain a header: %d bytes", size)
+	}
+
+	data, err := syscall.Mmap(int(file.Fd()), 0, size, syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mmap embedding file: %w", err)
+	}
+
+	h, ok := readHeader(data)
+	if !ok {
+		syscall.Munmap(data)
+		return nil, fmt.Errorf("embedding file is missing the CTXE header (run MigrateEmbeddingFile first)")
+	}
+	if h.DType != dtypeFloat32 {
+		defer syscall.Munmap(data)
+		vec, err := decodeEmbeddingPayload(h, data[headerSize:])
+		if err != nil {
+			return nil, err
+		}
+		vec32 := make([]float32, len(vec))
+		for i, v := range vec {
+			vec32[i] = float32(v)
+		}
+		return &MmapEmbedding{Vector: vec32}, nil
+	}
+
+	payload := data[headerSize:]
+	if len(payload) < int(h.Dim)*4 {
+		syscall.Munmap(data)
+		return nil, fmt.Errorf("embedding payload too small for dim %d", h.Dim)
+	}
+
+	// Reinterpret the mmap'd byte slice as []float32 in place, avoiding a copy.
+	vec := unsafe.Slice((*float32)(unsafe.Pointer(&payload[0])), h.Dim)
+
+	return &MmapEmbedding{data: data, Vector: vec}, nil
+}
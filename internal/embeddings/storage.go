@@ -3,77 +3,323 @@ package embeddings
 import (
 	"encoding/binary"
 	"fmt"
+	"hash/crc32"
 	"io"
+	"math"
 	"os"
 )
 
-// WriteEmbedding writes an embedding vector to a binary file
-// Format: LittleEndian float64 array
+// magic identifies a header-framed embedding file ("CTXE" = context embedding)
+var magic = [4]byte{'C', 'T', 'X', 'E'}
+
+const (
+	// headerSize is the fixed on-disk size of header in bytes
+	headerSize = 32
+
+	formatVersion = 1
+)
+
+// dtype identifies the element type of the payload following the header
+type dtype uint16
+
+const (
+	dtypeFloat64 dtype = 0
+	dtypeFloat32 dtype = 1
+	dtypeInt8    dtype = 2
+)
+
+// header is the fixed 32-byte prefix of a versioned embedding file:
+//
+//	magic[4]="CTXE"  version uint16  dtype uint16  dim uint32
+//	count uint32     flags uint32    crc32 uint32  reserved[8]
+//
+// dim is the vector length, count is the number of vectors in the file
+// (1 from WriteEmbedding, one per chunk from WriteChunkedEmbeddings), and crc32 is the
+// CRC-32 (IEEE) checksum of the little-endian payload bytes.
+type header struct {
+	Version uint16
+	DType   dtype
+	Dim     uint32
+	Count   uint32
+	Flags   uint32
+	CRC32   uint32
+}
+
+func writeHeader(w io.Writer, h header) error {
+	buf := make([]byte, headerSize)
+	copy(buf[0:4], magic[:])
+	binary.LittleEndian.PutUint16(buf[4:6], h.Version)
+	binary.LittleEndian.PutUint16(buf[6:8], uint16(h.DType))
+	binary.LittleEndian.PutUint32(buf[8:12], h.Dim)
+	binary.LittleEndian.PutUint32(buf[12:16], h.Count)
+	binary.LittleEndian.PutUint32(buf[16:20], h.Flags)
+	binary.LittleEndian.PutUint32(buf[20:24], h.CRC32)
+	// buf[24:32] is reserved and left zeroed
+	_, err := w.Write(buf)
+	return err
+}
+
+func readHeader(buf []byte) (header, bool) {
+	if len(buf) < headerSize || string(buf[0:4]) != string(magic[:]) {
+		return header{}, false
+	}
+	return header{
+		Version: binary.LittleEndian.Uint16(buf[4:6]),
+		DType:   dtype(binary.LittleEndian.Uint16(buf[6:8])),
+		Dim:     binary.LittleEndian.Uint32(buf[8:12]),
+		Count:   binary.LittleEndian.Uint32(buf[12:16]),
+		Flags:   binary.LittleEndian.Uint32(buf[16:20]),
+		CRC32:   binary.LittleEndian.Uint32(buf[20:24]),
+	}, true
+}
+
+// WriteEmbedding writes an embedding vector to a versioned, header-framed
+// binary file: a 32-byte header (magic, version, dtype, dim, count, flags,
+// crc32) followed by the little-endian float64 payload.
 func WriteEmbedding(path string, vec []float64) error {
 	if len(vec) == 0 {
 		return fmt.Errorf("embedding vector cannot be empty")
 	}
 
+	payload := make([]byte, len(vec)*8)
+	for i, val := range vec {
+		binary.LittleEndian.PutUint64(payload[i*8:], math.Float64bits(val))
+	}
+
 	file, err := os.Create(path)
 	if err != nil {
 		return fmt.Errorf("failed to create embedding file: %w", err)
 	}
 	defer file.Close()
 
-	// Write each float64 value
-	for _, val := range vec {
-		if err := binary.Write(file, binary.LittleEndian, val); err != nil {
-			return fmt.Errorf("failed to write embedding value: %w", err)
-		}
+	h := header{
+		Version: formatVersion,
+		DType:   dtypeFloat64,
+		Dim:     uint32(len(vec)),
+		Count:   1,
+		CRC32:   crc32.ChecksumIEEE(payload),
+	}
+
+	if err := writeHeader(file, h); err != nil {
+		return fmt.Errorf("failed to write embedding header: %w", err)
+	}
+	if _, err := file.Write(payload); err != nil {
+		return fmt.Errorf("failed to write embedding payload: %w", err)
 	}
 
 	return nil
 }
 
-// ReadEmbedding reads an embedding vector from a binary file
+// ReadEmbedding reads an embedding vector from a binary file. Files with
+// the "CTXE" header are verified against their stored CRC-32 and decoded
+// according to their dtype; files without the header are treated as
+// pre-header raw little-endian float64 streams for backward compatibility
+// (see MigrateEmbeddingFile to upgrade them in place).
 func ReadEmbedding(path string) ([]float64, error) {
-	file, err := os.Open(path)
+	raw, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open embedding file: %w", err)
 	}
-	defer file.Close()
 
-	// Get file size to calculate vector length
-	stat, err := file.Stat()
-	if err != nil {
-		return nil, fmt.Errorf("failed to stat embedding file: %w", err)
+	return DecodeEmbeddingBytes(raw)
+}
+
+// DecodeEmbeddingBytes decodes an embedding already held in memory (for
+// example, read from a git blob) rather than a file on disk. It accepts
+// the same header-framed and legacy headerless formats as ReadEmbedding.
+func DecodeEmbeddingBytes(raw []byte) ([]float64, error) {
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("embedding data is empty")
+	}
+
+	if h, ok := readHeader(raw); ok {
+		return decodeEmbeddingPayload(h, raw[headerSize:])
+	}
+
+	return readLegacyEmbedding(raw)
+}
+
+func decodeEmbeddingPayload(h header, payload []byte) ([]float64, error) {
+	if crc32.ChecksumIEEE(payload) != h.CRC32 {
+		return nil, fmt.Errorf("embedding file failed CRC-32 verification (corrupted)")
 	}
 
-	size := stat.Size()
-	if size == 0 {
-		return nil, fmt.Errorf("embedding file is empty")
+	switch h.DType {
+	case dtypeFloat64:
+		if len(payload) != int(h.Dim)*8 {
+			return nil, fmt.Errorf("invalid embedding payload size: %d (expected %d for dim %d)", len(payload), int(h.Dim)*8, h.Dim)
+		}
+		vec := make([]float64, h.Dim)
+		for i := range vec {
+			vec[i] = math.Float64frombits(binary.LittleEndian.Uint64(payload[i*8:]))
+		}
+		return vec, nil
+	case dtypeFloat32:
+		if len(payload) != int(h.Dim)*4 {
+			return nil, fmt.Errorf("invalid embedding payload size: %d (expected %d for dim %d)", len(payload), int(h.Dim)*4, h.Dim)
+		}
+		vec := make([]float64, h.Dim)
+		for i := range vec {
+			vec[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(payload[i*4:])))
+		}
+		return vec, nil
+	default:
+		return nil, fmt.Errorf("unsupported embedding dtype: %d", h.DType)
 	}
+}
 
-	// Each float64 is 8 bytes
+// readLegacyEmbedding reads a pre-header raw float64 stream, the format
+// WriteEmbedding produced before the "CTXE" header was introduced.
+func readLegacyEmbedding(raw []byte) ([]float64, error) {
+	size := len(raw)
 	if size%8 != 0 {
 		return nil, fmt.Errorf("invalid embedding file size: %d (not a multiple of 8)", size)
 	}
 
-	vectorLen := size / 8
-	vec := make([]float64, vectorLen)
-
-	// Read each float64 value
+	vec := make([]float64, size/8)
 	for i := range vec {
-		if err := binary.Read(file, binary.LittleEndian, &vec[i]); err != nil {
-			if err == io.EOF {
-				return nil, fmt.Errorf("unexpected EOF at element %d", i)
-			}
-			return nil, fmt.Errorf("failed to read embedding value at %d: %w", i, err)
+		vec[i] = math.Float64frombits(binary.LittleEndian.Uint64(raw[i*8:]))
+	}
+	return vec, nil
+}
+
+// WriteChunkedEmbeddings writes multiple same-dimension vectors to a single
+// header-framed binary file, one per chunk of a snapshot's research/code
+// text (see ChunkText), using the header's existing Count field to record
+// how many vectors follow. ReadChunkedEmbeddings reads it back.
+func WriteChunkedEmbeddings(path string, vecs [][]float64) error {
+	if len(vecs) == 0 {
+		return fmt.Errorf("chunked embeddings cannot be empty")
+	}
+
+	dim := len(vecs[0])
+	if dim == 0 {
+		return fmt.Errorf("embedding vector cannot be empty")
+	}
+
+	payload := make([]byte, len(vecs)*dim*8)
+	for i, vec := range vecs {
+		if len(vec) != dim {
+			return fmt.Errorf("chunk %d has dimension %d, want %d", i, len(vec), dim)
+		}
+		for j, val := range vec {
+			binary.LittleEndian.PutUint64(payload[(i*dim+j)*8:], math.Float64bits(val))
 		}
 	}
 
-	return vec, nil
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create embedding file: %w", err)
+	}
+	defer file.Close()
+
+	h := header{
+		Version: formatVersion,
+		DType:   dtypeFloat64,
+		Dim:     uint32(dim),
+		Count:   uint32(len(vecs)),
+		CRC32:   crc32.ChecksumIEEE(payload),
+	}
+
+	if err := writeHeader(file, h); err != nil {
+		return fmt.Errorf("failed to write embedding header: %w", err)
+	}
+	if _, err := file.Write(payload); err != nil {
+		return fmt.Errorf("failed to write embedding payload: %w", err)
+	}
+
+	return nil
+}
+
+// ReadChunkedEmbeddings reads a header-framed file containing one or more
+// per-chunk vectors written by WriteChunkedEmbeddings, returning each
+// vector in the order it was written.
+func ReadChunkedEmbeddings(path string) ([][]float64, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open embedding file: %w", err)
+	}
+
+	h, ok := readHeader(raw)
+	if !ok {
+		return nil, fmt.Errorf("embedding file has no CTXE header")
+	}
+	if h.DType != dtypeFloat64 {
+		return nil, fmt.Errorf("unsupported embedding dtype: %d", h.DType)
+	}
+
+	payload := raw[headerSize:]
+	if crc32.ChecksumIEEE(payload) != h.CRC32 {
+		return nil, fmt.Errorf("embedding file failed CRC-32 verification (corrupted)")
+	}
+
+	expected := int(h.Dim) * int(h.Count) * 8
+	if len(payload) != expected {
+		return nil, fmt.Errorf("invalid embedding payload size: %d (expected %d for dim %d, count %d)", len(payload), expected, h.Dim, h.Count)
+	}
+
+	vecs := make([][]float64, h.Count)
+	for i := range vecs {
+		vec := make([]float64, h.Dim)
+		base := i * int(h.Dim)
+		for j := range vec {
+			vec[j] = math.Float64frombits(binary.LittleEndian.Uint64(payload[(base+j)*8:]))
+		}
+		vecs[i] = vec
+	}
+
+	return vecs, nil
+}
+
+// MigrateEmbeddingFile upgrades a pre-header embedding file in place by
+// treating its full contents as a legacy float64 payload and rewriting it
+// with a versioned "CTXE" header. It is a no-op if the file is already
+// header-framed.
+func MigrateEmbeddingFile(path string) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to open embedding file: %w", err)
+	}
+
+	if _, ok := readHeader(raw); ok {
+		return nil
+	}
+
+	vec, err := readLegacyEmbedding(raw)
+	if err != nil {
+		return fmt.Errorf("failed to parse legacy embedding file: %w", err)
+	}
+
+	return WriteEmbedding(path, vec)
+}
+
+// ReadEmbeddingHeader reads the dimensionality of an embedding file without
+// decoding or CRC-checking its payload. It is the fast, metadata-only path
+// for integrity checks that don't need the full vector; ReadEmbedding
+// remains the authoritative, CRC-validating reader.
+func ReadEmbeddingHeader(path string) (dim int, err error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open embedding file: %w", err)
+	}
+	if len(raw) == 0 {
+		return 0, fmt.Errorf("embedding file is empty")
+	}
+
+	if h, ok := readHeader(raw); ok {
+		return int(h.Dim), nil
+	}
+
+	if len(raw)%8 != 0 {
+		return 0, fmt.Errorf("invalid embedding file size: %d (not a multiple of 8)", len(raw))
+	}
+	return len(raw) / 8, nil
 }
 
-// EmbeddingSize returns the size in bytes of an embedding file
+// EmbeddingSize returns the on-disk size in bytes of a header-framed
+// embedding file holding a float64 vector of the given dimensionality.
 func EmbeddingSize(dimensions int) int64 {
-	// Each float64 is 8 bytes
-	return int64(dimensions * 8)
+	return headerSize + int64(dimensions*8)
 }
 
 // ValidateEmbedding checks if an embedding vector is valid
@@ -1,6 +1,8 @@
 package embeddings
 
 import (
+	"encoding/binary"
+	"math"
 	"os"
 	"path/filepath"
 	"testing"
@@ -152,7 +154,7 @@ func TestEmbeddingFileSize(t *testing.T) {
 	}
 	defer os.RemoveAll(tmpDir)
 
-	// Test that file size is correct (8 bytes per float64)
+	// Test that file size is header + 8 bytes per float64
 	embedding := []float64{1.0, 2.0, 3.0, 4.0, 5.0}
 	path := filepath.Join(tmpDir, "test.bin")
 
@@ -165,12 +167,115 @@ func TestEmbeddingFileSize(t *testing.T) {
 		t.Fatalf("failed to stat file: %v", err)
 	}
 
-	expectedSize := int64(len(embedding) * 8)
+	expectedSize := EmbeddingSize(len(embedding))
 	if info.Size() != expectedSize {
 		t.Errorf("expected file size %d bytes, got %d bytes", expectedSize, info.Size())
 	}
 }
 
+func TestReadLegacyEmbeddingWithoutHeader(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "embedding-legacy-test-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Hand-write a pre-header raw float64 stream, the format WriteEmbedding
+	// produced before the CTXE header was introduced.
+	legacy := []float64{1.0, 2.0, 3.0}
+	path := filepath.Join(tmpDir, "legacy.bin")
+	raw := make([]byte, len(legacy)*8)
+	for i, v := range legacy {
+		binary.LittleEndian.PutUint64(raw[i*8:], math.Float64bits(v))
+	}
+	if err := os.WriteFile(path, raw, 0644); err != nil {
+		t.Fatalf("failed to write legacy file: %v", err)
+	}
+
+	result, err := ReadEmbedding(path)
+	if err != nil {
+		t.Fatalf("failed to read legacy embedding: %v", err)
+	}
+
+	for i := range legacy {
+		if result[i] != legacy[i] {
+			t.Errorf("mismatch at index %d: expected %f, got %f", i, legacy[i], result[i])
+		}
+	}
+}
+
+func TestMigrateEmbeddingFile(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "embedding-migrate-test-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	legacy := []float64{4.0, 5.0, 6.0}
+	path := filepath.Join(tmpDir, "legacy.bin")
+	raw := make([]byte, len(legacy)*8)
+	for i, v := range legacy {
+		binary.LittleEndian.PutUint64(raw[i*8:], math.Float64bits(v))
+	}
+	if err := os.WriteFile(path, raw, 0644); err != nil {
+		t.Fatalf("failed to write legacy file: %v", err)
+	}
+
+	if err := MigrateEmbeddingFile(path); err != nil {
+		t.Fatalf("failed to migrate embedding file: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("failed to stat migrated file: %v", err)
+	}
+	if info.Size() != EmbeddingSize(len(legacy)) {
+		t.Errorf("expected migrated file size %d, got %d", EmbeddingSize(len(legacy)), info.Size())
+	}
+
+	result, err := ReadEmbedding(path)
+	if err != nil {
+		t.Fatalf("failed to read migrated embedding: %v", err)
+	}
+	for i := range legacy {
+		if result[i] != legacy[i] {
+			t.Errorf("mismatch at index %d: expected %f, got %f", i, legacy[i], result[i])
+		}
+	}
+
+	// Migrating an already-migrated file must be a no-op.
+	if err := MigrateEmbeddingFile(path); err != nil {
+		t.Fatalf("second migration failed: %v", err)
+	}
+}
+
+func TestReadEmbeddingRejectsCorruptedCRC(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "embedding-crc-test-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, "tampered.bin")
+	if err := WriteEmbedding(path, []float64{1.0, 2.0, 3.0}); err != nil {
+		t.Fatalf("failed to write embedding: %v", err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	// Flip a byte in the payload without updating the stored CRC-32.
+	raw[len(raw)-1] ^= 0xFF
+	if err := os.WriteFile(path, raw, 0644); err != nil {
+		t.Fatalf("failed to write tampered file: %v", err)
+	}
+
+	if _, err := ReadEmbedding(path); err == nil {
+		t.Error("expected error when reading a file with a mismatched CRC-32")
+	}
+}
+
 func TestReadCorruptedFile(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "embedding-corrupt-test-*")
 	if err != nil {
@@ -201,6 +306,58 @@ func generateTestEmbedding(size int) []float64 {
 	return vec
 }
 
+func TestWriteAndReadChunkedEmbeddings(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "chunked-embedding-test-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	vecs := [][]float64{
+		generateTestEmbedding(8),
+		generateTestEmbedding(8),
+		generateTestEmbedding(8),
+	}
+	path := filepath.Join(tmpDir, "embeddings.bin")
+
+	if err := WriteChunkedEmbeddings(path, vecs); err != nil {
+		t.Fatalf("WriteChunkedEmbeddings failed: %v", err)
+	}
+
+	got, err := ReadChunkedEmbeddings(path)
+	if err != nil {
+		t.Fatalf("ReadChunkedEmbeddings failed: %v", err)
+	}
+
+	if len(got) != len(vecs) {
+		t.Fatalf("expected %d vectors, got %d", len(vecs), len(got))
+	}
+	for i, vec := range vecs {
+		for j, val := range vec {
+			if got[i][j] != val {
+				t.Errorf("vector %d element %d: expected %v, got %v", i, j, val, got[i][j])
+			}
+		}
+	}
+}
+
+func TestWriteChunkedEmbeddingsDimensionMismatch(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "chunked-embedding-test-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	vecs := [][]float64{
+		{1.0, 2.0, 3.0},
+		{1.0, 2.0},
+	}
+	err = WriteChunkedEmbeddings(filepath.Join(tmpDir, "embeddings.bin"), vecs)
+	if err == nil {
+		t.Error("expected an error for mismatched chunk dimensions")
+	}
+}
+
 func BenchmarkWriteEmbedding(b *testing.B) {
 	tmpDir, err := os.MkdirTemp("", "embedding-bench-*")
 	if err != nil {
@@ -0,0 +1,20 @@
+package git
+
+import "os"
+
+// gitBackendEnvVar selects which implementation backs this package's git
+// operations: "exec" (the default, and every function's original
+// implementation) shells out to the git binary; "gogit" drives
+// github.com/go-git/go-git/v5 directly instead, avoiding a process spawn
+// per call. Migration is incremental — only the functions listed in
+// gogit.go honor this switch so far (repo detection, current branch,
+// commit and tree hash, branch creation, existence and listing, worktree
+// creation and removal, staging, committing and clearing the index in a
+// worktree directory, and reading a blob as of a ref); everything else
+// still shells out to git regardless of this setting.
+const gitBackendEnvVar = "GITCONTEXT_GIT_BACKEND"
+
+// useGoGit reports whether GITCONTEXT_GIT_BACKEND=gogit is set.
+func useGoGit() bool {
+	return os.Getenv(gitBackendEnvVar) == "gogit"
+}
@@ -0,0 +1,108 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+)
+
+// SafeArg is a hard-coded git subcommand or option literal (e.g. "branch",
+// "-D", "--force"). Only pass string constants as SafeArg — never a
+// variable built from user- or computed-input, which belongs in
+// AddDynamicArguments instead.
+type SafeArg string
+
+// dynamicArgPattern matches embedded NUL or newline bytes. Together with
+// AddDynamicArguments' own leading '-' check (a leading '-' turns a
+// positional ref/path into what looks like an option), it rejects the two
+// things that let a computed string be misread by git. Refs,
+// topics, tags, and worktree paths built from timestamps and slugs never
+// need either.
+var dynamicArgPattern = regexp.MustCompile("[\x00\n]")
+
+// InvalidArgumentError reports that a dynamic argument failed validation
+// before ever reaching exec.Command, so callers can surface it as a
+// validation failure rather than a git error (or, worse, a misinterpreted
+// flag).
+type InvalidArgumentError struct {
+	Value string
+}
+
+func (e *InvalidArgumentError) Error() string {
+	return fmt.Sprintf("invalid git argument: %q", e.Value)
+}
+
+// Command incrementally builds a `git` argv, keeping hard-coded literals
+// (AddArguments), trailing pathspecs (AddDashesAndList), and
+// user/computed-derived values (AddDynamicArguments) in separate methods
+// so a future edit can't accidentally splice an untrusted string in as a
+// bare positional argument.
+type Command struct {
+	args []string
+	err  error
+}
+
+// NewCommand starts a Command with one or more hard-coded literals, e.g.
+// NewCommand("branch", "-D").
+func NewCommand(literals ...SafeArg) *Command {
+	return (&Command{}).AddArguments(literals...)
+}
+
+// AddArguments appends hard-coded subcommand/option literals. Never pass
+// a variable holding user- or computed-input here.
+func (c *Command) AddArguments(literals ...SafeArg) *Command {
+	for _, l := range literals {
+		c.args = append(c.args, string(l))
+	}
+	return c
+}
+
+// AddDynamicArguments validates and appends refs, tags, topics, or paths
+// computed at runtime, rejecting anything with a leading '-' or an
+// embedded NUL/newline. The first rejected value is recorded and
+// returned by Err (and Exec); later values are still appended so callers
+// see the offending one in context, but the command must not be run.
+func (c *Command) AddDynamicArguments(values ...string) *Command {
+	for _, v := range values {
+		if v == "" || v[0] == '-' || dynamicArgPattern.MatchString(v) {
+			if c.err == nil {
+				c.err = &InvalidArgumentError{Value: v}
+			}
+		}
+		c.args = append(c.args, v)
+	}
+	return c
+}
+
+// AddDashesAndList appends a literal "--" followed by paths, the
+// conventional way to tell git "everything after this is a pathspec, not
+// an option" — so a path that happens to start with '-' can never be
+// misread as a flag.
+func (c *Command) AddDashesAndList(paths ...string) *Command {
+	c.args = append(c.args, "--")
+	c.args = append(c.args, paths...)
+	return c
+}
+
+// AddFlagValue appends value as the argument to the flag that was just
+// added via AddArguments (e.g. "-m" for commit messages). Unlike
+// AddDynamicArguments, it does not reject a leading '-': git's flag
+// parser consumes the argv slot immediately following "-m" unconditionally,
+// so there's no position where value could be misread as a separate
+// option the way a bare positional ref or path could.
+func (c *Command) AddFlagValue(value string) *Command {
+	c.args = append(c.args, value)
+	return c
+}
+
+// Err returns the first validation error recorded by AddDynamicArguments, if any.
+func (c *Command) Err() error {
+	return c.err
+}
+
+// Exec builds the underlying *exec.Cmd. Callers must check Err before
+// running it.
+func (c *Command) Exec(ctx context.Context) *exec.Cmd {
+	return exec.CommandContext(ctx, "git", c.args...)
+}
@@ -1,20 +1,41 @@
 package git
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"os/exec"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 // IsGitRepo checks if current directory is a git repository
-func IsGitRepo() bool {
-	cmd := exec.Command("git", "rev-parse", "--git-dir")
+func IsGitRepo(ctx context.Context) bool {
+	if useGoGit() {
+		return isGitRepoGoGit(ctx)
+	}
+	return isGitRepoExec(ctx)
+}
+
+func isGitRepoExec(ctx context.Context) bool {
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--git-dir")
 	return cmd.Run() == nil
 }
 
 // GetCurrentBranch returns the current branch name
-func GetCurrentBranch() (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
+func GetCurrentBranch(ctx context.Context) (string, error) {
+	if useGoGit() {
+		return getCurrentBranchGoGit(ctx)
+	}
+	return getCurrentBranchExec(ctx)
+}
+
+func getCurrentBranchExec(ctx context.Context) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--abbrev-ref", "HEAD")
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("failed to get current branch: %w", err)
@@ -23,8 +44,15 @@ func GetCurrentBranch() (string, error) {
 }
 
 // GetCurrentCommit returns the current commit hash
-func GetCurrentCommit() (string, error) {
-	cmd := exec.Command("git", "rev-parse", "HEAD")
+func GetCurrentCommit(ctx context.Context) (string, error) {
+	if useGoGit() {
+		return getCurrentCommitGoGit(ctx)
+	}
+	return getCurrentCommitExec(ctx)
+}
+
+func getCurrentCommitExec(ctx context.Context) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "HEAD")
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("failed to get current commit: %w", err)
@@ -32,9 +60,35 @@ func GetCurrentCommit() (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
+// RootCommit returns the SHA of the repository's first commit, used as a
+// stable identifier for the repo across clones (unlike the working
+// directory path, which differs per checkout)
+func RootCommit(ctx context.Context) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "rev-list", "--max-parents=0", "HEAD")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to find root commit: %w", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) == 0 || lines[0] == "" {
+		return "", fmt.Errorf("no root commit found")
+	}
+	// A repo can have multiple roots (e.g. after an orphan branch or
+	// history merge); the oldest entry is the most stable identifier.
+	return lines[len(lines)-1], nil
+}
+
 // GetTreeHash returns the tree hash of current HEAD
-func GetTreeHash() (string, error) {
-	cmd := exec.Command("git", "rev-parse", "HEAD^{tree}")
+func GetTreeHash(ctx context.Context) (string, error) {
+	if useGoGit() {
+		return getTreeHashGoGit(ctx)
+	}
+	return getTreeHashExec(ctx)
+}
+
+func getTreeHashExec(ctx context.Context) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "HEAD^{tree}")
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("failed to get tree hash: %w", err)
@@ -43,61 +97,92 @@ func GetTreeHash() (string, error) {
 }
 
 // BranchExists checks if a branch exists
-func BranchExists(branch string) bool {
-	cmd := exec.Command("git", "rev-parse", "--verify", branch)
+func BranchExists(ctx context.Context, branch string) bool {
+	if useGoGit() {
+		return branchExistsGoGit(branch)
+	}
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--verify", branch)
+	return cmd.Run() == nil
+}
+
+// TreeExists checks that a branch's commit and top-level tree object both
+// exist and parse cleanly
+func TreeExists(ctx context.Context, branch string) bool {
+	cmd := exec.CommandContext(ctx, "git", "cat-file", "-e", branch+"^{tree}")
 	return cmd.Run() == nil
 }
 
 // CreateBranch creates a new branch
-func CreateBranch(branch string) error {
-	cmd := exec.Command("git", "branch", branch)
-	if err := cmd.Run(); err != nil {
+func CreateBranch(ctx context.Context, branch string) error {
+	if useGoGit() {
+		return createBranchGoGit(ctx, branch)
+	}
+	return createBranchExec(ctx, branch)
+}
+
+func createBranchExec(ctx context.Context, branch string) error {
+	c := NewCommand("branch").AddDynamicArguments(branch)
+	if err := c.Err(); err != nil {
+		return err
+	}
+	if err := c.Exec(ctx).Run(); err != nil {
 		return fmt.Errorf("failed to create branch %s: %w", branch, err)
 	}
 	return nil
 }
 
 // CheckoutBranch checks out a branch
-func CheckoutBranch(branch string) error {
-	cmd := exec.Command("git", "checkout", branch)
-	if err := cmd.Run(); err != nil {
+func CheckoutBranch(ctx context.Context, branch string) error {
+	c := NewCommand("checkout").AddDynamicArguments(branch)
+	if err := c.Err(); err != nil {
+		return err
+	}
+	if err := c.Exec(ctx).Run(); err != nil {
 		return fmt.Errorf("failed to checkout branch %s: %w", branch, err)
 	}
 	return nil
 }
 
 // CheckoutBranchForce checks out a branch with force flag
-func CheckoutBranchForce(branch string) error {
-	cmd := exec.Command("git", "checkout", "-f", branch)
-	if err := cmd.Run(); err != nil {
+func CheckoutBranchForce(ctx context.Context, branch string) error {
+	c := NewCommand("checkout", "-f").AddDynamicArguments(branch)
+	if err := c.Err(); err != nil {
+		return err
+	}
+	if err := c.Exec(ctx).Run(); err != nil {
 		return fmt.Errorf("failed to force checkout branch %s: %w", branch, err)
 	}
 	return nil
 }
 
 // AddFiles stages files for commit
-func AddFiles(files ...string) error {
-	args := append([]string{"add"}, files...)
-	cmd := exec.Command("git", args...)
-	if err := cmd.Run(); err != nil {
+func AddFiles(ctx context.Context, files ...string) error {
+	c := NewCommand("add").AddDashesAndList(files...)
+	if err := c.Exec(ctx).Run(); err != nil {
 		return fmt.Errorf("failed to add files: %w", err)
 	}
 	return nil
 }
 
 // Commit creates a commit with the given message
-func Commit(message string) error {
-	cmd := exec.Command("git", "commit", "-m", message)
-	if err := cmd.Run(); err != nil {
+func Commit(ctx context.Context, message string) error {
+	c := NewCommand("commit", "-m").AddFlagValue(message)
+	if err := c.Exec(ctx).Run(); err != nil {
 		return fmt.Errorf("failed to commit: %w", err)
 	}
 	return nil
 }
 
 // ListBranches returns all branches matching a pattern
-func ListBranches(pattern string) ([]string, error) {
-	cmd := exec.Command("git", "branch", "--list", pattern)
-	output, err := cmd.Output()
+func ListBranches(ctx context.Context, pattern string) ([]string, error) {
+	if useGoGit() {
+		return listBranchesGoGit(pattern)
+	}
+	c := NewCommand("branch", "--list").AddDynamicArguments(pattern)
+	if err := c.Err(); err != nil {
+		return nil, err
+	}
+	output, err := c.Exec(ctx).Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to list branches: %w", err)
 	}
@@ -116,22 +201,37 @@ func ListBranches(pattern string) ([]string, error) {
 }
 
 // DeleteBranch deletes a branch
-func DeleteBranch(branch string, force bool) error {
-	flag := "-d"
+func DeleteBranch(ctx context.Context, branch string, force bool) error {
+	flag := SafeArg("-d")
 	if force {
 		flag = "-D"
 	}
-	cmd := exec.Command("git", "branch", flag, branch)
-	if err := cmd.Run(); err != nil {
+	c := NewCommand("branch", flag).AddDynamicArguments(branch)
+	if err := c.Err(); err != nil {
+		return err
+	}
+	if err := c.Exec(ctx).Run(); err != nil {
 		return fmt.Errorf("failed to delete branch %s: %w", branch, err)
 	}
 	return nil
 }
 
-// CreateWorktree creates a git worktree
-func CreateWorktree(path, branch string) error {
-	cmd := exec.Command("git", "worktree", "add", path, branch)
-	output, err := cmd.CombinedOutput()
+// CreateWorktree creates a git worktree. Under the gogit backend, go-git
+// has no linked-worktree equivalent, so path is populated with a local
+// clone checked out to branch instead — see gogit.go.
+func CreateWorktree(ctx context.Context, path, branch string) error {
+	if useGoGit() {
+		return createWorktreeGoGit(ctx, path, branch)
+	}
+	return createWorktreeExec(ctx, path, branch)
+}
+
+func createWorktreeExec(ctx context.Context, path, branch string) error {
+	c := NewCommand("worktree", "add").AddDynamicArguments(path, branch)
+	if err := c.Err(); err != nil {
+		return err
+	}
+	output, err := c.Exec(ctx).CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("failed to create worktree: %s: %w", string(output), err)
 	}
@@ -139,9 +239,19 @@ func CreateWorktree(path, branch string) error {
 }
 
 // RemoveWorktree removes a git worktree (with force to handle untracked files)
-func RemoveWorktree(path string) error {
-	cmd := exec.Command("git", "worktree", "remove", "--force", path)
-	output, err := cmd.CombinedOutput()
+func RemoveWorktree(ctx context.Context, path string) error {
+	if useGoGit() {
+		return removeWorktreeGoGit(ctx, path)
+	}
+	return removeWorktreeExec(ctx, path)
+}
+
+func removeWorktreeExec(ctx context.Context, path string) error {
+	c := NewCommand("worktree", "remove", "--force").AddDynamicArguments(path)
+	if err := c.Err(); err != nil {
+		return err
+	}
+	output, err := c.Exec(ctx).CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("failed to remove worktree: %s: %w", string(output), err)
 	}
@@ -149,8 +259,16 @@ func RemoveWorktree(path string) error {
 }
 
 // GetDiff returns the diff between current state and a commit
-func GetDiff(commit string) (string, error) {
-	cmd := exec.Command("git", "diff", commit)
+func GetDiff(ctx context.Context, commit string) (string, error) {
+	// go-git has no convenient API for diffing the live worktree against
+	// an arbitrary commit (only commit-to-commit tree diffs); fall back to
+	// the exec backend here rather than reimplementing index/worktree
+	// diffing on top of go-git's lower-level plumbing.
+	return getDiffExec(ctx, commit)
+}
+
+func getDiffExec(ctx context.Context, commit string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "diff", commit)
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("failed to get diff: %w", err)
@@ -159,8 +277,8 @@ func GetDiff(commit string) (string, error) {
 }
 
 // HasUncommittedChanges checks if there are uncommitted changes
-func HasUncommittedChanges() (bool, error) {
-	cmd := exec.Command("git", "status", "--porcelain")
+func HasUncommittedChanges(ctx context.Context) (bool, error) {
+	cmd := exec.CommandContext(ctx, "git", "status", "--porcelain")
 	output, err := cmd.Output()
 	if err != nil {
 		return false, fmt.Errorf("failed to check git status: %w", err)
@@ -169,8 +287,8 @@ func HasUncommittedChanges() (bool, error) {
 }
 
 // RemoveAllFilesFromIndex removes all files from the git index (staging area)
-func RemoveAllFilesFromIndex() error {
-	cmd := exec.Command("git", "rm", "-r", "--cached", ".")
+func RemoveAllFilesFromIndex(ctx context.Context) error {
+	cmd := exec.CommandContext(ctx, "git", "rm", "-r", "--cached", ".")
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to remove files from index: %w", err)
 	}
@@ -178,8 +296,8 @@ func RemoveAllFilesFromIndex() error {
 }
 
 // RemoveUntrackedFiles removes all untracked files and directories
-func RemoveUntrackedFiles() error {
-	cmd := exec.Command("git", "clean", "-fd")
+func RemoveUntrackedFiles(ctx context.Context) error {
+	cmd := exec.CommandContext(ctx, "git", "clean", "-fd")
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to remove untracked files: %w", err)
 	}
@@ -187,9 +305,16 @@ func RemoveUntrackedFiles() error {
 }
 
 // AddFilesInDir stages files for commit in a specific directory
-func AddFilesInDir(dir string, files ...string) error {
-	args := append([]string{"add"}, files...)
-	cmd := exec.Command("git", args...)
+func AddFilesInDir(ctx context.Context, dir string, files ...string) error {
+	if useGoGit() {
+		return addFilesInDirGoGit(ctx, dir, files...)
+	}
+	return addFilesInDirExec(ctx, dir, files...)
+}
+
+func addFilesInDirExec(ctx context.Context, dir string, files ...string) error {
+	c := NewCommand("add").AddDashesAndList(files...)
+	cmd := c.Exec(ctx)
 	cmd.Dir = dir
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to add files: %w", err)
@@ -198,8 +323,16 @@ func AddFilesInDir(dir string, files ...string) error {
 }
 
 // CommitInDir creates a commit with the given message in a specific directory
-func CommitInDir(dir, message string) error {
-	cmd := exec.Command("git", "commit", "-m", message)
+func CommitInDir(ctx context.Context, dir, message string) error {
+	if useGoGit() {
+		return commitInDirGoGit(ctx, dir, message)
+	}
+	return commitInDirExec(ctx, dir, message)
+}
+
+func commitInDirExec(ctx context.Context, dir, message string) error {
+	c := NewCommand("commit", "-m").AddFlagValue(message)
+	cmd := c.Exec(ctx)
 	cmd.Dir = dir
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -209,8 +342,9 @@ func CommitInDir(dir, message string) error {
 }
 
 // CommitInDirNoVerify creates a commit bypassing hooks (used for snapshot creation)
-func CommitInDirNoVerify(dir, message string) error {
-	cmd := exec.Command("git", "commit", "--no-verify", "-m", message)
+func CommitInDirNoVerify(ctx context.Context, dir, message string) error {
+	c := NewCommand("commit", "--no-verify", "-m").AddFlagValue(message)
+	cmd := c.Exec(ctx)
 	cmd.Dir = dir
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -220,11 +354,555 @@ func CommitInDirNoVerify(dir, message string) error {
 }
 
 // RemoveAllFilesFromIndexInDir removes all files from the git index in a specific directory
-func RemoveAllFilesFromIndexInDir(dir string) error {
-	cmd := exec.Command("git", "rm", "-r", "--cached", ".")
+func RemoveAllFilesFromIndexInDir(ctx context.Context, dir string) error {
+	if useGoGit() {
+		return removeAllFilesFromIndexInDirGoGit(ctx, dir)
+	}
+	return removeAllFilesFromIndexInDirExec(ctx, dir)
+}
+
+func removeAllFilesFromIndexInDirExec(ctx context.Context, dir string) error {
+	cmd := exec.CommandContext(ctx, "git", "rm", "-r", "--cached", ".")
 	cmd.Dir = dir
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to remove files from index: %w", err)
 	}
 	return nil
 }
+
+// DiffBranches returns the full unified diff between two branches
+func DiffBranches(ctx context.Context, branch1, branch2 string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "diff", branch1, branch2)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to diff branches: %w", err)
+	}
+	return string(output), nil
+}
+
+// DiffStatBranches returns the --stat summary of the diff between two branches
+func DiffStatBranches(ctx context.Context, branch1, branch2 string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "diff", "--stat", branch1, branch2)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to diff branches: %w", err)
+	}
+	return string(output), nil
+}
+
+// DiffNameStatusBranches returns the name-status diff (added/deleted/modified files) between two branches
+func DiffNameStatusBranches(ctx context.Context, branch1, branch2 string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "diff", "--name-status", branch1, branch2)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to diff branches: %w", err)
+	}
+	return string(output), nil
+}
+
+// DiffNameStatusBranchesPath is DiffNameStatusBranches restricted to paths
+// under pathspec, with rename detection enabled so renamed files report as
+// a single "Rnnn old new" entry instead of a delete+add pair.
+func DiffNameStatusBranchesPath(ctx context.Context, branch1, branch2, pathspec string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "diff", "--name-status", "-M", branch1, branch2, "--", pathspec)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to diff branches under %s: %w", pathspec, err)
+	}
+	return string(output), nil
+}
+
+// ListTreeInBranch returns the immediate entry names (files and
+// directories, non-recursive) under path within a branch's tree.
+func ListTreeInBranch(ctx context.Context, branch, path string) ([]string, error) {
+	ref := branch + ":" + path
+	cmd := exec.CommandContext(ctx, "git", "ls-tree", "--name-only", ref)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tree %s: %w", ref, err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	var names []string
+	for _, line := range lines {
+		if line != "" {
+			names = append(names, line)
+		}
+	}
+	return names, nil
+}
+
+// ListTreeRecursive returns every file path (blobs only, no directories)
+// in a branch's tree, relative to the tree root.
+func ListTreeRecursive(ctx context.Context, branch string) ([]string, error) {
+	cmd := exec.CommandContext(ctx, "git", "ls-tree", "-r", "--name-only", branch)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tree for %s: %w", branch, err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	var names []string
+	for _, line := range lines {
+		if line != "" {
+			names = append(names, line)
+		}
+	}
+	return names, nil
+}
+
+// PathIsTreeInBranch reports whether path is a directory (tree object)
+// rather than a file (blob object) at the given branch.
+func PathIsTreeInBranch(ctx context.Context, branch, path string) (bool, error) {
+	cmd := exec.CommandContext(ctx, "git", "cat-file", "-t", branch+":"+path)
+	output, err := cmd.Output()
+	if err != nil {
+		return false, fmt.Errorf("failed to stat %s:%s: %w", branch, path, err)
+	}
+	return strings.TrimSpace(string(output)) == "tree", nil
+}
+
+// Show reads path's content as of ref (a branch, tag, or commit-ish),
+// equivalent to `git show ref:path`. Callers reading many blobs from the
+// same process should prefer BatchCatFile instead of repeated calls here.
+func Show(ctx context.Context, ref, path string) (string, error) {
+	if useGoGit() {
+		return showGoGit(ref, path)
+	}
+	cmd := exec.CommandContext(ctx, "git", "show", fmt.Sprintf("%s:%s", ref, path))
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+// BatchCatFile is a long-lived `git cat-file --batch` subprocess for
+// streaming many blob reads without paying a process-spawn cost per read.
+// Read holds an internal mutex for each request/response round trip, so
+// concurrent calls from multiple goroutines are serialized.
+type BatchCatFile struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+	mu     sync.Mutex
+}
+
+// NewBatchCatFile starts a `git cat-file --batch` subprocess. Callers must
+// call Close when done to release the process.
+func NewBatchCatFile(ctx context.Context) (*BatchCatFile, error) {
+	cmd := exec.CommandContext(ctx, "git", "cat-file", "--batch")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cat-file stdin: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cat-file stdout: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start cat-file --batch: %w", err)
+	}
+	return &BatchCatFile{cmd: cmd, stdin: stdin, stdout: bufio.NewReader(stdout)}, nil
+}
+
+// Read returns the contents of the blob at ref (e.g. "branch:path/to/file").
+func (b *BatchCatFile) Read(ref string) ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, err := fmt.Fprintf(b.stdin, "%s\n", ref); err != nil {
+		return nil, fmt.Errorf("failed to write cat-file request for %s: %w", ref, err)
+	}
+
+	header, err := b.stdout.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cat-file header for %s: %w", ref, err)
+	}
+	fields := strings.Fields(strings.TrimSpace(header))
+	if len(fields) == 2 && fields[1] == "missing" {
+		return nil, fmt.Errorf("object not found: %s", ref)
+	}
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("unexpected cat-file --batch header for %s: %q", ref, header)
+	}
+	size, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid cat-file --batch size for %s: %w", ref, err)
+	}
+
+	content := make([]byte, size)
+	if _, err := io.ReadFull(b.stdout, content); err != nil {
+		return nil, fmt.Errorf("failed to read cat-file content for %s: %w", ref, err)
+	}
+	if _, err := b.stdout.Discard(1); err != nil {
+		return nil, fmt.Errorf("failed to consume cat-file trailing newline for %s: %w", ref, err)
+	}
+
+	return content, nil
+}
+
+// Close terminates the cat-file subprocess.
+func (b *BatchCatFile) Close() error {
+	if err := b.stdin.Close(); err != nil {
+		return err
+	}
+	return b.cmd.Wait()
+}
+
+// RevParseBranch returns the commit SHA a local branch points at
+func RevParseBranch(ctx context.Context, branch string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", branch)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve branch %s: %w", branch, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// BlobSHA returns the blob SHA for a path in a branch's tree, without
+// reading its content.
+func BlobSHA(ctx context.Context, branch, path string) (string, error) {
+	ref := branch + ":" + path
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", ref)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve blob %s: %w", ref, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// HashObject returns the git blob SHA that a local file would have if
+// added to the repository, without actually staging it.
+func HashObject(ctx context.Context, path string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "hash-object", path)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// RemoteBranchCommit returns the commit SHA a branch points at on a remote
+// or bare-clone path, without fetching it locally
+func RemoteBranchCommit(ctx context.Context, remote, branch string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "ls-remote", remote, "refs/heads/"+branch)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to query remote %s: %w", remote, err)
+	}
+
+	line := strings.TrimSpace(string(output))
+	if line == "" {
+		return "", fmt.Errorf("branch %s not found on remote %s", branch, remote)
+	}
+
+	fields := strings.Fields(line)
+	return fields[0], nil
+}
+
+// ListRemoteBranches lists branches matching a glob pattern on a remote
+// or bare-clone path, e.g. ListRemoteBranches(remote, "snapshot/*")
+func ListRemoteBranches(ctx context.Context, remote, pattern string) ([]string, error) {
+	cmd := exec.CommandContext(ctx, "git", "ls-remote", "--heads", remote, pattern)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remote branches on %s: %w", remote, err)
+	}
+
+	var branches []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		branches = append(branches, strings.TrimPrefix(fields[1], "refs/heads/"))
+	}
+	return branches, nil
+}
+
+// PushBranch pushes a local branch to a remote or bare-clone path
+func PushBranch(ctx context.Context, remote, branch string) error {
+	cmd := exec.CommandContext(ctx, "git", "push", remote, fmt.Sprintf("%s:%s", branch, branch))
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to push %s to %s: %s: %w", branch, remote, string(output), err)
+	}
+	return nil
+}
+
+// FetchBranch fetches a branch from a remote or bare-clone path into the
+// same-named local branch
+func FetchBranch(ctx context.Context, remote, branch string) error {
+	cmd := exec.CommandContext(ctx, "git", "fetch", remote, fmt.Sprintf("%s:%s", branch, branch))
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s from %s: %s: %w", branch, remote, string(output), err)
+	}
+	return nil
+}
+
+// RefExists reports whether ref resolves to a valid object, the same way
+// BranchExists does for branches; it works for any ref-ish, including
+// custom namespaces like refs/context-xrefs/index.
+func RefExists(ctx context.Context, ref string) bool {
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--verify", ref)
+	return cmd.Run() == nil
+}
+
+// UpdateRef points ref at commit, creating it if it doesn't exist yet.
+func UpdateRef(ctx context.Context, ref, commit string) error {
+	cmd := exec.CommandContext(ctx, "git", "update-ref", ref, commit)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to update ref %s: %w", ref, err)
+	}
+	return nil
+}
+
+// WriteBlob hashes and stores content as a git blob object, returning its SHA.
+func WriteBlob(ctx context.Context, content []byte) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "hash-object", "-w", "--stdin")
+	cmd.Stdin = bytes.NewReader(content)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to write blob: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// MakeTreeSingleFile builds a tree object containing a single blob entry
+// named name, returning the tree's SHA.
+func MakeTreeSingleFile(ctx context.Context, name, blobSHA string) (string, error) {
+	entry := fmt.Sprintf("100644 blob %s\t%s\n", blobSHA, name)
+	cmd := exec.CommandContext(ctx, "git", "mktree")
+	cmd.Stdin = strings.NewReader(entry)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to make tree: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// CommitTreeOrphan creates a commit object for tree with the given
+// message and optional parent (empty to create a root commit with no
+// parent), returning the new commit's SHA. It does not move any ref.
+func CommitTreeOrphan(ctx context.Context, tree, message, parent string) (string, error) {
+	args := []string{"commit-tree", tree, "-m", message}
+	if parent != "" {
+		args = append(args, "-p", parent)
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to commit tree: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// CatFileCommit returns the raw, unparsed contents of a commit object
+// (tree/parent/author/committer headers plus message), the counterpart to
+// WriteCommitObject.
+func CatFileCommit(ctx context.Context, ref string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "cat-file", "commit", ref)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read commit object %s: %w", ref, err)
+	}
+	return string(output), nil
+}
+
+// WriteCommitObject creates a raw commit object from content (a fully
+// formed git commit object body, e.g. one with an embedded gpgsig header),
+// returning its SHA. Used to attach a signature commit-tree has no flag
+// for; the caller repoints the branch ref at the returned SHA afterward.
+func WriteCommitObject(ctx context.Context, content []byte) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "hash-object", "-w", "-t", "commit", "--stdin")
+	cmd.Stdin = bytes.NewReader(content)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to write commit object: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// MergeBase returns the best common ancestor commit between commit1 and
+// commit2.
+func MergeBase(ctx context.Context, commit1, commit2 string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "merge-base", commit1, commit2)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to find merge base of %s and %s: %w", commit1, commit2, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// IsAncestor reports whether ancestor is an ancestor of (or equal to) commit.
+func IsAncestor(ctx context.Context, ancestor, commit string) bool {
+	cmd := exec.CommandContext(ctx, "git", "merge-base", "--is-ancestor", ancestor, commit)
+	return cmd.Run() == nil
+}
+
+// CommitDate returns a commit's committer date.
+func CommitDate(ctx context.Context, commit string) (time.Time, error) {
+	cmd := exec.CommandContext(ctx, "git", "show", "-s", "--format=%cI", commit)
+	output, err := cmd.Output()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read commit date for %s: %w", commit, err)
+	}
+	date, err := time.Parse(time.RFC3339, strings.TrimSpace(string(output)))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse commit date for %s: %w", commit, err)
+	}
+	return date, nil
+}
+
+// CommitLogEntry is a single commit returned by CommitsBetween.
+type CommitLogEntry struct {
+	SHA     string
+	Subject string
+	Date    time.Time
+}
+
+// CommitsBetween returns the commits reachable from to but not from from
+// (i.e. `git log from..to`), oldest first. It's used to list the commits
+// main advanced by between two snapshots that share an ancestor.
+func CommitsBetween(ctx context.Context, from, to string) ([]CommitLogEntry, error) {
+	cmd := exec.CommandContext(ctx, "git", "log", "--reverse", "--format=%H%x1f%cI%x1f%s", from+".."+to)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to log commits between %s and %s: %w", from, to, err)
+	}
+
+	trimmed := strings.TrimSpace(string(output))
+	if trimmed == "" {
+		return nil, nil
+	}
+
+	var entries []CommitLogEntry
+	for _, line := range strings.Split(trimmed, "\n") {
+		fields := strings.SplitN(line, "\x1f", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		date, err := time.Parse(time.RFC3339, fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse commit date %q: %w", fields[1], err)
+		}
+		entries = append(entries, CommitLogEntry{SHA: fields[0], Date: date, Subject: fields[2]})
+	}
+	return entries, nil
+}
+
+// CatFileBlob returns the raw contents of the blob at ref (e.g.
+// "branch:path/to/file"), the single-shot counterpart to BatchCatFile for
+// call sites that only need one read.
+func CatFileBlob(ctx context.Context, ref string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", "cat-file", "-p", ref)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob %s: %w", ref, err)
+	}
+	return output, nil
+}
+
+// treeEntry is one line of `git ls-tree` output: a blob or subtree
+// immediately under a tree.
+type treeEntry struct {
+	Mode string
+	Type string
+	SHA  string
+	Name string
+}
+
+// lsTreeEntries returns the immediate entries (mode, type, SHA, name) of
+// a tree-ish ref, the richer counterpart to ListTreeInBranch (which only
+// returns names) needed to rebuild a tree with one entry swapped out.
+func lsTreeEntries(ctx context.Context, treeRef string) ([]treeEntry, error) {
+	cmd := exec.CommandContext(ctx, "git", "ls-tree", treeRef)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tree %s: %w", treeRef, err)
+	}
+
+	var entries []treeEntry
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line == "" {
+			continue
+		}
+		// "<mode> <type> <sha>\t<name>"
+		tab := strings.IndexByte(line, '\t')
+		if tab < 0 {
+			continue
+		}
+		fields := strings.Fields(line[:tab])
+		if len(fields) != 3 {
+			continue
+		}
+		entries = append(entries, treeEntry{Mode: fields[0], Type: fields[1], SHA: fields[2], Name: line[tab+1:]})
+	}
+	return entries, nil
+}
+
+// makeTree writes entries as a tree object via `git mktree`, returning its SHA.
+func makeTree(ctx context.Context, entries []treeEntry) (string, error) {
+	var input strings.Builder
+	for _, e := range entries {
+		fmt.Fprintf(&input, "%s %s %s\t%s\n", e.Mode, e.Type, e.SHA, e.Name)
+	}
+	cmd := exec.CommandContext(ctx, "git", "mktree")
+	cmd.Stdin = strings.NewReader(input.String())
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to make tree: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// ReplaceBlobInTree rewrites the blob at path (slash-separated, relative
+// to root) within commit's tree to mode 100644 pointing at newBlobSHA,
+// rebuilding every intermediate tree from the leaf up via `git mktree`,
+// and returns the new root tree's SHA. commit's existing tree and blobs
+// are left untouched; only new tree objects are created. This is what
+// lets a metadata edit land as a single commit-tree + update-ref, with
+// no worktree or working-directory writes.
+func ReplaceBlobInTree(ctx context.Context, commit, path, newBlobSHA string) (string, error) {
+	parts := strings.Split(path, "/")
+	return replaceBlobInTree(ctx, commit+"^{tree}", parts, newBlobSHA)
+}
+
+func replaceBlobInTree(ctx context.Context, treeRef string, parts []string, newBlobSHA string) (string, error) {
+	entries, err := lsTreeEntries(ctx, treeRef)
+	if err != nil {
+		return "", err
+	}
+
+	name := parts[0]
+	found := false
+	for i, e := range entries {
+		if e.Name != name {
+			continue
+		}
+		found = true
+		if len(parts) == 1 {
+			entries[i] = treeEntry{Mode: "100644", Type: "blob", SHA: newBlobSHA, Name: name}
+			break
+		}
+		if e.Type != "tree" {
+			return "", fmt.Errorf("%s is a %s, not a tree", name, e.Type)
+		}
+		newSubtree, err := replaceBlobInTree(ctx, e.SHA, parts[1:], newBlobSHA)
+		if err != nil {
+			return "", err
+		}
+		entries[i] = treeEntry{Mode: "040000", Type: "tree", SHA: newSubtree, Name: name}
+		break
+	}
+	if !found {
+		return "", fmt.Errorf("path component %q not found in tree %s", name, treeRef)
+	}
+
+	return makeTree(ctx, entries)
+}
@@ -0,0 +1,272 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// openGoGitRepo opens the repository rooted at (or above) the process's
+// current directory, the same scope `git rev-parse --git-dir` discovers
+// for the exec backend.
+func openGoGitRepo() (*git.Repository, error) {
+	repo, err := git.PlainOpenWithOptions(".", &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+	return repo, nil
+}
+
+func isGitRepoGoGit(ctx context.Context) bool {
+	_, err := git.PlainOpenWithOptions(".", &git.PlainOpenOptions{DetectDotGit: true})
+	return err == nil
+}
+
+func getCurrentBranchGoGit(ctx context.Context) (string, error) {
+	repo, err := openGoGitRepo()
+	if err != nil {
+		return "", err
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current branch: %w", err)
+	}
+	return head.Name().Short(), nil
+}
+
+func getCurrentCommitGoGit(ctx context.Context) (string, error) {
+	repo, err := openGoGitRepo()
+	if err != nil {
+		return "", err
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current commit: %w", err)
+	}
+	return head.Hash().String(), nil
+}
+
+func getTreeHashGoGit(ctx context.Context) (string, error) {
+	repo, err := openGoGitRepo()
+	if err != nil {
+		return "", err
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to get tree hash: %w", err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return "", fmt.Errorf("failed to get tree hash: %w", err)
+	}
+	return commit.TreeHash.String(), nil
+}
+
+func createBranchGoGit(ctx context.Context, branch string) error {
+	repo, err := openGoGitRepo()
+	if err != nil {
+		return err
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return fmt.Errorf("failed to create branch %s: %w", branch, err)
+	}
+	ref := plumbing.NewHashReference(plumbing.NewBranchReferenceName(branch), head.Hash())
+	if err := repo.Storer.SetReference(ref); err != nil {
+		return fmt.Errorf("failed to create branch %s: %w", branch, err)
+	}
+	return nil
+}
+
+// createWorktreeGoGit stands in for `git worktree add`, which go-git has
+// no equivalent of: it clones the repository's current directory locally
+// into path and checks out branch, giving the caller an isolated
+// directory to populate and commit in, without touching the main
+// repository's working tree, index, or HEAD.
+func createWorktreeGoGit(ctx context.Context, path, branch string) error {
+	repoRoot, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to create worktree: %w", err)
+	}
+
+	_, err = git.PlainCloneContext(ctx, path, false, &git.CloneOptions{
+		URL:           repoRoot,
+		ReferenceName: plumbing.NewBranchReferenceName(branch),
+		SingleBranch:  true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create worktree: %w", err)
+	}
+	return nil
+}
+
+// removeWorktreeGoGit discards the local clone createWorktreeGoGit made.
+// Unlike `git worktree remove`, there is no admin state in the main
+// repository's .git directory to clean up, since the clone was never
+// registered as a linked worktree.
+func removeWorktreeGoGit(ctx context.Context, path string) error {
+	if err := os.RemoveAll(path); err != nil {
+		return fmt.Errorf("failed to remove worktree: %w", err)
+	}
+	return nil
+}
+
+// branchExistsGoGit reports whether branch resolves to a reference,
+// mirroring `git rev-parse --verify`'s success/failure signal.
+func branchExistsGoGit(branch string) bool {
+	repo, err := openGoGitRepo()
+	if err != nil {
+		return false
+	}
+	_, err = repo.Reference(plumbing.NewBranchReferenceName(branch), true)
+	return err == nil
+}
+
+// listBranchesGoGit lists local branches whose short name matches pattern,
+// using the same glob semantics as `git branch --list`: "*" and "?" cross
+// "/" freely, unlike path.Match's path-aware globbing (which would make
+// "snapshot/*" fail to match "snapshot/<ts>/<topic>").
+func listBranchesGoGit(pattern string) ([]string, error) {
+	repo, err := openGoGitRepo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	refs, err := repo.Branches()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	defer refs.Close()
+
+	matcher, err := globToRegexp(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+
+	var branches []string
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name().Short()
+		if matcher.MatchString(name) {
+			branches = append(branches, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	return branches, nil
+}
+
+// globToRegexp translates a shell-style glob (as `git branch --list`
+// interprets it) into an anchored regexp: "*" matches any run of
+// characters including "/", "?" matches any single character, and
+// everything else is matched literally.
+func globToRegexp(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// showGoGit reads filePath's content as of ref (a branch, tag, or
+// commit-ish), the go-git equivalent of `git show ref:filePath`.
+func showGoGit(ref, filePath string) (string, error) {
+	repo, err := openGoGitRepo()
+	if err != nil {
+		return "", err
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", ref, err)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve commit %s: %w", ref, err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve tree for %s: %w", ref, err)
+	}
+	file, err := tree.File(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to find %s in %s: %w", filePath, ref, err)
+	}
+	contents, err := file.Contents()
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s:%s: %w", ref, filePath, err)
+	}
+	return contents, nil
+}
+
+func addFilesInDirGoGit(ctx context.Context, dir string, files ...string) error {
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		return fmt.Errorf("failed to add files: %w", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to add files: %w", err)
+	}
+	for _, f := range files {
+		if _, err := wt.Add(f); err != nil {
+			return fmt.Errorf("failed to add files: %w", err)
+		}
+	}
+	return nil
+}
+
+// removeAllFilesFromIndexInDirGoGit clears the index without touching the
+// worktree files, the equivalent of `git rm -r --cached .`. go-git's
+// Worktree.Remove deletes from disk as well as the index, so this edits
+// the index directly instead.
+func removeAllFilesFromIndexInDirGoGit(ctx context.Context, dir string) error {
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		return fmt.Errorf("failed to remove files from index: %w", err)
+	}
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return fmt.Errorf("failed to remove files from index: %w", err)
+	}
+	idx.Entries = nil
+	if err := repo.Storer.SetIndex(idx); err != nil {
+		return fmt.Errorf("failed to remove files from index: %w", err)
+	}
+	return nil
+}
+
+func commitInDirGoGit(ctx context.Context, dir, message string) error {
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+	sig := &object.Signature{
+		Name:  "git-context",
+		Email: "git-context@localhost",
+		When:  time.Now(),
+	}
+	if _, err := wt.Commit(message, &git.CommitOptions{Author: sig}); err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+	return nil
+}
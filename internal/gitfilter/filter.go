@@ -0,0 +1,132 @@
+// Package gitfilter implements a small subset of git's partial-clone
+// filter grammar (see `git help rev-list` --filter=<filter-spec>), used by
+// `context save` to keep large binary assets out of full-tree snapshots.
+package gitfilter
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Kind identifies which filter grammar a Spec was parsed from.
+type Kind int
+
+const (
+	KindNone Kind = iota
+	KindBlobNone
+	KindBlobLimit
+	KindTreeDepth
+	KindSparseOID
+)
+
+// Spec is a parsed partial-clone-style filter.
+type Spec struct {
+	Raw   string
+	Kind  Kind
+	Limit int64  // blob:limit=<size>, in bytes
+	Depth int    // tree:<depth>
+	OID   string // sparse:oid=<blob>
+}
+
+// Parse parses a filter spec string in git's partial-clone grammar:
+// blob:none, blob:limit=<size>, tree:<depth>, sparse:oid=<blob>. Sizes
+// accept git's k/m/g suffixes (e.g. "1m" for 1 MiB).
+func Parse(raw string) (Spec, error) {
+	switch {
+	case raw == "":
+		return Spec{Raw: raw, Kind: KindNone}, nil
+	case raw == "blob:none":
+		return Spec{Raw: raw, Kind: KindBlobNone}, nil
+	case strings.HasPrefix(raw, "blob:limit="):
+		size, err := parseSize(strings.TrimPrefix(raw, "blob:limit="))
+		if err != nil {
+			return Spec{}, fmt.Errorf("invalid blob:limit filter %q: %w", raw, err)
+		}
+		return Spec{Raw: raw, Kind: KindBlobLimit, Limit: size}, nil
+	case strings.HasPrefix(raw, "tree:"):
+		depth, err := strconv.Atoi(strings.TrimPrefix(raw, "tree:"))
+		if err != nil {
+			return Spec{}, fmt.Errorf("invalid tree filter %q: %w", raw, err)
+		}
+		return Spec{Raw: raw, Kind: KindTreeDepth, Depth: depth}, nil
+	case strings.HasPrefix(raw, "sparse:oid="):
+		return Spec{Raw: raw, Kind: KindSparseOID, OID: strings.TrimPrefix(raw, "sparse:oid=")}, nil
+	default:
+		return Spec{}, fmt.Errorf("unsupported filter spec %q (want blob:none, blob:limit=<size>, tree:<depth>, or sparse:oid=<blob>)", raw)
+	}
+}
+
+// parseSize parses a git-style size, accepting a trailing k/m/g suffix
+// (case-insensitive) for KiB/MiB/GiB, or a bare byte count.
+func parseSize(s string) (int64, error) {
+	if s == "" {
+		return 0, fmt.Errorf("empty size")
+	}
+	mult := int64(1)
+	switch suffix := s[len(s)-1]; suffix {
+	case 'k', 'K':
+		mult = 1024
+		s = s[:len(s)-1]
+	case 'm', 'M':
+		mult = 1024 * 1024
+		s = s[:len(s)-1]
+	case 'g', 'G':
+		mult = 1024 * 1024 * 1024
+		s = s[:len(s)-1]
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return n * mult, nil
+}
+
+// ExcludesBlob reports whether the blob at path (size bytes, slash-separated
+// relative to the tree root) should be omitted under this filter. sparse:oid
+// filters always return false: resolving a sparse-checkout spec blob
+// requires walking the object graph of the related branch, which is out of
+// scope for this package's pure, repo-free filtering.
+func (s Spec) ExcludesBlob(path string, size int64) bool {
+	switch s.Kind {
+	case KindBlobNone:
+		return true
+	case KindBlobLimit:
+		return size > s.Limit
+	case KindTreeDepth:
+		return strings.Count(path, "/") > s.Depth
+	default:
+		return false
+	}
+}
+
+// Omission records a blob that was excluded from a snapshot, so a later
+// `context restore` can rehydrate it on demand from the parent branch.
+type Omission struct {
+	Path   string `json:"path"`
+	SHA    string `json:"sha"`
+	Size   int64  `json:"size"`
+	Reason string `json:"reason"`
+}
+
+// BlobSHA returns the git blob object ID for content, computed the same
+// way `git hash-object` does (sha1 of "blob <size>\0<content>"), without
+// needing a repository to ask.
+func BlobSHA(content []byte) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "blob %d\x00", len(content))
+	h.Write(content)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Placeholder returns the content written in place of an excluded blob: a
+// short, human-readable stub pointing at the original blob's SHA on
+// relatedBranch so the file can be rehydrated on demand.
+func Placeholder(path, sha string, size int64, relatedBranch, reason string) string {
+	return fmt.Sprintf(
+		"# omitted by git-context --filter=%s\n# path: %s\n# sha: %s (%d bytes)\n# restore with: git show %s:%s\n",
+		reason, path, sha, size, relatedBranch, path,
+	)
+}
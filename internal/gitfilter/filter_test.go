@@ -0,0 +1,119 @@
+package gitfilter
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		raw     string
+		kind    Kind
+		wantErr bool
+	}{
+		{"", KindNone, false},
+		{"blob:none", KindBlobNone, false},
+		{"blob:limit=1048576", KindBlobLimit, false},
+		{"blob:limit=1m", KindBlobLimit, false},
+		{"tree:0", KindTreeDepth, false},
+		{"sparse:oid=abc123", KindSparseOID, false},
+		{"nonsense", KindNone, true},
+		{"blob:limit=notanumber", KindNone, true},
+		{"tree:notanumber", KindNone, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			spec, err := Parse(tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error for %q, got none", tt.raw)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error for %q: %v", tt.raw, err)
+			}
+			if spec.Kind != tt.kind {
+				t.Errorf("expected kind %v, got %v", tt.kind, spec.Kind)
+			}
+		})
+	}
+}
+
+func TestParseBlobLimitSizes(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want int64
+	}{
+		{"blob:limit=100", 100},
+		{"blob:limit=1k", 1024},
+		{"blob:limit=1m", 1024 * 1024},
+		{"blob:limit=1g", 1024 * 1024 * 1024},
+	}
+	for _, tt := range tests {
+		spec, err := Parse(tt.raw)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", tt.raw, err)
+		}
+		if spec.Limit != tt.want {
+			t.Errorf("Parse(%q).Limit = %d, want %d", tt.raw, spec.Limit, tt.want)
+		}
+	}
+}
+
+func TestExcludesBlob(t *testing.T) {
+	noneSpec, _ := Parse("")
+	if noneSpec.ExcludesBlob("a.txt", 1) {
+		t.Error("empty spec should never exclude")
+	}
+
+	blobNone, _ := Parse("blob:none")
+	if !blobNone.ExcludesBlob("a.txt", 1) {
+		t.Error("blob:none should exclude every blob")
+	}
+
+	limit, _ := Parse("blob:limit=100")
+	if limit.ExcludesBlob("small.txt", 50) {
+		t.Error("blob:limit=100 should not exclude a 50 byte file")
+	}
+	if !limit.ExcludesBlob("big.txt", 200) {
+		t.Error("blob:limit=100 should exclude a 200 byte file")
+	}
+
+	tree0, _ := Parse("tree:0")
+	if !tree0.ExcludesBlob("sub/dir/file.txt", 1) {
+		t.Error("tree:0 should exclude blobs nested under a subdirectory")
+	}
+	if tree0.ExcludesBlob("file.txt", 1) {
+		t.Error("tree:0 should not exclude a root-level blob")
+	}
+
+	sparse, _ := Parse("sparse:oid=abc123")
+	if sparse.ExcludesBlob("anything.txt", 1) {
+		t.Error("sparse:oid filters aren't resolved by this package and should never exclude")
+	}
+}
+
+func TestBlobSHAMatchesGitHashObject(t *testing.T) {
+	// `git hash-object` of an empty blob is the well-known constant
+	// e69de29bb2d1d6434b8b29ae775ad8c2e48c5391.
+	if got := BlobSHA(nil); got != "e69de29bb2d1d6434b8b29ae775ad8c2e48c5391" {
+		t.Errorf("BlobSHA(nil) = %s, want e69de29bb2d1d6434b8b29ae775ad8c2e48c5391", got)
+	}
+}
+
+func TestPlaceholderContainsKeyFields(t *testing.T) {
+	out := Placeholder("assets/big.bin", "deadbeef", 2048, "main", "blob:limit=1m")
+	for _, want := range []string{"assets/big.bin", "deadbeef", "2048", "main", "blob:limit=1m"} {
+		if !contains(out, want) {
+			t.Errorf("placeholder output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func contains(haystack, needle string) bool {
+	for i := 0; i+len(needle) <= len(haystack); i++ {
+		if haystack[i:i+len(needle)] == needle {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,257 @@
+// Package index builds and queries a trigram substring index over the
+// files captured in a snapshot, so `context search` can find a literal or
+// phrase term inside file content rather than only in snapshot metadata.
+package index
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// magic identifies an on-disk trigram index file ("CTRI" = context trigram
+// index), written to a snapshot as research/<ts>/<topic>/index.trigram.
+var magic = [4]byte{'C', 'T', 'R', 'I'}
+
+const (
+	headerSize    = 16
+	formatVersion = 1
+)
+
+// File is one file to index: its path relative to the snapshot's captured
+// root (so it round-trips straight into a `git show <branch>:<path>`
+// lookup at query time) and its raw content.
+type File struct {
+	Path    string
+	Content []byte
+}
+
+// postingEntry locates one trigram's posting list within the posting-data
+// section: Offset/Length index into the delta-varint-encoded byte range
+// for that trigram. The on-disk table is sorted by Trigram so a lookup is
+// a binary search rather than a hash probe.
+type postingEntry struct {
+	Trigram uint32
+	Offset  uint32
+	Length  uint32
+}
+
+// Index is a trigram index loaded from (or built for) a single snapshot:
+// every overlapping 3-byte sequence across its files, mapped to the sorted
+// list of file ids each trigram appears in. Trigram membership narrows a
+// search to candidate files; it is not itself proof of a match (a term's
+// trigrams can all appear in a file without the term itself occurring, if
+// they line up differently) — callers MUST verify candidates with a real
+// substring match.
+type Index struct {
+	Files []string
+
+	postings []postingEntry
+	data     []byte
+}
+
+// trigramKey packs 3 content bytes into the uint32 key used throughout
+// this package.
+func trigramKey(a, b, c byte) uint32 {
+	return uint32(a)<<16 | uint32(b)<<8 | uint32(c)
+}
+
+// trigramsOf returns the sorted, de-duplicated set of trigram keys in
+// content. A file shorter than 3 bytes contributes none, which is fine:
+// Build simply omits it from every posting list, and a linear fallback
+// scan (see searchContentHits in the search command) catches sub-trigram
+// terms anyway.
+func trigramsOf(content []byte) []uint32 {
+	if len(content) < 3 {
+		return nil
+	}
+	seen := make(map[uint32]bool)
+	for i := 0; i+2 < len(content); i++ {
+		seen[trigramKey(content[i], content[i+1], content[i+2])] = true
+	}
+	keys := make([]uint32, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+// Build indexes every file's trigrams and encodes the result as described
+// in Open's doc comment. Files are assigned ids by their position in
+// files, which is also how a caller maps a returned candidate id back to
+// its path via Index.Files.
+func Build(files []File) ([]byte, error) {
+	postingFileIDs := make(map[uint32][]uint32)
+	for id, f := range files {
+		for _, t := range trigramsOf(f.Content) {
+			postingFileIDs[t] = append(postingFileIDs[t], uint32(id))
+		}
+	}
+
+	trigrams := make([]uint32, 0, len(postingFileIDs))
+	for t := range postingFileIDs {
+		trigrams = append(trigrams, t)
+	}
+	sort.Slice(trigrams, func(i, j int) bool { return trigrams[i] < trigrams[j] })
+
+	var postingData []byte
+	entries := make([]postingEntry, 0, len(trigrams))
+	varint := make([]byte, binary.MaxVarintLen64)
+	for _, t := range trigrams {
+		ids := postingFileIDs[t]
+		start := len(postingData)
+
+		var prev uint32
+		for _, id := range ids {
+			n := binary.PutUvarint(varint, uint64(id-prev))
+			postingData = append(postingData, varint[:n]...)
+			prev = id
+		}
+
+		entries = append(entries, postingEntry{Trigram: t, Offset: uint32(start), Length: uint32(len(postingData) - start)})
+	}
+
+	var buf []byte
+	header := make([]byte, headerSize)
+	copy(header[0:4], magic[:])
+	binary.LittleEndian.PutUint16(header[4:6], formatVersion)
+	binary.LittleEndian.PutUint32(header[6:10], uint32(len(files)))
+	binary.LittleEndian.PutUint32(header[10:14], uint32(len(entries)))
+	buf = append(buf, header...)
+
+	for _, f := range files {
+		n := binary.PutUvarint(varint, uint64(len(f.Path)))
+		buf = append(buf, varint[:n]...)
+		buf = append(buf, f.Path...)
+	}
+
+	for _, e := range entries {
+		entryBytes := make([]byte, 12)
+		binary.LittleEndian.PutUint32(entryBytes[0:4], e.Trigram)
+		binary.LittleEndian.PutUint32(entryBytes[4:8], e.Offset)
+		binary.LittleEndian.PutUint32(entryBytes[8:12], e.Length)
+		buf = append(buf, entryBytes...)
+	}
+
+	buf = append(buf, postingData...)
+
+	return buf, nil
+}
+
+// Open decodes a trigram index previously written by Build:
+//
+//	header[16]: magic[4]="CTRI"  version uint16  fileCount uint32  trigramCount uint32  reserved[2]
+//	file table: fileCount * (varint path length, path bytes)
+//	posting table: trigramCount * (trigram uint32, offset uint32, length uint32), sorted by trigram
+//	posting data: delta-varint-encoded sorted file ids, sliced per posting table entry
+func Open(raw []byte) (*Index, error) {
+	if len(raw) < headerSize || string(raw[0:4]) != string(magic[:]) {
+		return nil, fmt.Errorf("missing or invalid trigram index header")
+	}
+
+	fileCount := int(binary.LittleEndian.Uint32(raw[6:10]))
+	trigramCount := int(binary.LittleEndian.Uint32(raw[10:14]))
+
+	r := bufio.NewReader(bytes.NewReader(raw[headerSize:]))
+	files := make([]string, fileCount)
+	for i := range files {
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file %d path length: %w", i, err)
+		}
+		path := make([]byte, n)
+		if _, err := io.ReadFull(r, path); err != nil {
+			return nil, fmt.Errorf("failed to read file %d path: %w", i, err)
+		}
+		files[i] = string(path)
+	}
+
+	postings := make([]postingEntry, trigramCount)
+	entryBytes := make([]byte, 12)
+	for i := range postings {
+		if _, err := io.ReadFull(r, entryBytes); err != nil {
+			return nil, fmt.Errorf("failed to read posting entry %d: %w", i, err)
+		}
+		postings[i] = postingEntry{
+			Trigram: binary.LittleEndian.Uint32(entryBytes[0:4]),
+			Offset:  binary.LittleEndian.Uint32(entryBytes[4:8]),
+			Length:  binary.LittleEndian.Uint32(entryBytes[8:12]),
+		}
+	}
+
+	remaining, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read posting data: %w", err)
+	}
+
+	return &Index{Files: files, postings: postings, data: remaining}, nil
+}
+
+// postingList returns the sorted file ids containing trigram t, decoding
+// its delta-varint run on demand.
+func (idx *Index) postingList(t uint32) []uint32 {
+	i := sort.Search(len(idx.postings), func(i int) bool { return idx.postings[i].Trigram >= t })
+	if i >= len(idx.postings) || idx.postings[i].Trigram != t {
+		return nil
+	}
+	e := idx.postings[i]
+	r := bufio.NewReader(bytes.NewReader(idx.data[e.Offset : e.Offset+e.Length]))
+
+	var ids []uint32
+	var cur uint32
+	for {
+		delta, err := binary.ReadUvarint(r)
+		if err != nil {
+			break
+		}
+		cur += uint32(delta)
+		ids = append(ids, cur)
+	}
+	return ids
+}
+
+// CandidateFiles intersects the posting lists of every trigram in term,
+// returning the file ids (indices into Index.Files) that might contain
+// term. Callers MUST verify each candidate with a real substring match
+// before trusting it — see the Index doc comment. Terms shorter than 3
+// bytes have no trigrams to look up; callers should fall back to a linear
+// scan for those instead of calling CandidateFiles.
+func (idx *Index) CandidateFiles(term string) []int {
+	trigrams := trigramsOf([]byte(term))
+	if len(trigrams) == 0 {
+		return nil
+	}
+
+	candidates := idsToSet(idx.postingList(trigrams[0]))
+	for _, t := range trigrams[1:] {
+		if len(candidates) == 0 {
+			break
+		}
+		next := idx.postingList(t)
+		nextSet := idsToSet(next)
+		for id := range candidates {
+			if !nextSet[id] {
+				delete(candidates, id)
+			}
+		}
+	}
+
+	out := make([]int, 0, len(candidates))
+	for id := range candidates {
+		out = append(out, int(id))
+	}
+	sort.Ints(out)
+	return out
+}
+
+func idsToSet(ids []uint32) map[uint32]bool {
+	set := make(map[uint32]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
@@ -0,0 +1,74 @@
+package index
+
+import "testing"
+
+func TestBuildOpenRoundTrip(t *testing.T) {
+	files := []File{
+		{Path: "cmd/search.go", Content: []byte("func calculateRelevance(query searchQuery) int {")},
+		{Path: "research/notes.md", Content: []byte("unrelated notes about something else")},
+	}
+
+	raw, err := Build(files)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	idx, err := Open(raw)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if len(idx.Files) != len(files) {
+		t.Fatalf("expected %d files, got %d", len(files), len(idx.Files))
+	}
+	for i, f := range files {
+		if idx.Files[i] != f.Path {
+			t.Errorf("file %d: expected path %q, got %q", i, f.Path, idx.Files[i])
+		}
+	}
+}
+
+func TestCandidateFilesFindsMatchingFile(t *testing.T) {
+	files := []File{
+		{Path: "a.go", Content: []byte("func calculateRelevance(query searchQuery) int {")},
+		{Path: "b.go", Content: []byte("package main\n\nfunc main() {}\n")},
+	}
+	raw, err := Build(files)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	idx, err := Open(raw)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	candidates := idx.CandidateFiles("calculateRelevance")
+	if len(candidates) != 1 || candidates[0] != 0 {
+		t.Errorf("expected candidates [0], got %v", candidates)
+	}
+
+	none := idx.CandidateFiles("nonexistentSymbolXYZ")
+	if len(none) != 0 {
+		t.Errorf("expected no candidates for an absent term, got %v", none)
+	}
+}
+
+func TestCandidateFilesShortTermReturnsNil(t *testing.T) {
+	raw, err := Build([]File{{Path: "a.go", Content: []byte("hello world")}})
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	idx, err := Open(raw)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	if got := idx.CandidateFiles("ab"); got != nil {
+		t.Errorf("expected nil candidates for a sub-trigram term, got %v", got)
+	}
+}
+
+func TestOpenRejectsCorruptData(t *testing.T) {
+	if _, err := Open([]byte("not an index")); err == nil {
+		t.Error("expected an error opening corrupt data")
+	}
+}
@@ -1,6 +1,11 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"time"
+
+	"github.com/pders01/git-context/internal/embeddings"
+)
 
 // SnapshotMode defines the type of snapshot
 type SnapshotMode string
@@ -14,14 +19,48 @@ const (
 
 // Metadata represents the meta.json structure for a snapshot
 type Metadata struct {
-	CreatedAt     time.Time    `json:"created_at"`
-	Topic         string       `json:"topic"`
-	Root          string       `json:"root"`
-	Mode          SnapshotMode `json:"mode"`
-	RelatedBranch string       `json:"related_branch,omitempty"`
-	MainCommit    string       `json:"main_commit"`
-	Tags          []string     `json:"tags,omitempty"`
-	Embedding     string       `json:"embedding,omitempty"`
-	Notes         string       `json:"notes,omitempty"`
-	TreeHash      string       `json:"tree_hash,omitempty"` // For immutability verification
+	CreatedAt      time.Time         `json:"created_at"`
+	Topic          string            `json:"topic"`
+	Root           string            `json:"root"`
+	Mode           SnapshotMode      `json:"mode"`
+	RelatedBranch  string            `json:"related_branch,omitempty"`
+	MainCommit     string            `json:"main_commit"`
+	Tags           []string          `json:"tags,omitempty"`
+	Embedding      string            `json:"embedding,omitempty"`
+	EmbeddingBase  string            `json:"embedding_base,omitempty"`
+	EmbeddingDelta string            `json:"embedding_delta,omitempty"`
+	EmbeddingScale float64           `json:"embedding_scale,omitempty"`
+	Chunked        *ChunkedEmbedding `json:"chunked,omitempty"`
+	Notes          string            `json:"notes,omitempty"`
+	TreeHash       string            `json:"tree_hash,omitempty"`   // For immutability verification
+	SignFormat     string            `json:"sign_format,omitempty"` // Signature scheme used to sign this snapshot, if any
+	Filter         string            `json:"filter,omitempty"`      // Partial-clone-style blob filter applied on save, if any (see internal/gitfilter)
+}
+
+// ChunkedEmbedding describes a per-chunk, multi-vector embedding index
+// stored alongside a snapshot's research/code text, for per-chunk cosine
+// ranking (see internal/embeddings.ReadChunkedEmbeddings) rather than the
+// single snapshot-level vector Embedding/EmbeddingDelta provide.
+type ChunkedEmbedding struct {
+	File   string `json:"file"`   // vectors, e.g. "embeddings.bin"
+	Chunks string `json:"chunks"` // chunk offsets/headings, e.g. "chunks.jsonl"
+	Dim    int    `json:"dim"`
+	Count  int    `json:"count"`
+	Format string `json:"format"` // vector element type, e.g. "float64"
+}
+
+// ResolveEmbedding decodes a snapshot's stored embedding payload, raw into
+// its vector form. If the snapshot stores a plain embedding (Embedding
+// set, EmbeddingDelta empty), raw is the contents of that file and base is
+// ignored. If the snapshot is delta-compressed (EmbeddingDelta set), raw
+// is the delta payload and base must be the already-resolved vector of the
+// snapshot named by EmbeddingBase.
+func (m Metadata) ResolveEmbedding(raw []byte, base []float64) ([]float64, error) {
+	if m.EmbeddingDelta == "" {
+		return embeddings.DecodeEmbeddingBytes(raw)
+	}
+	if base == nil {
+		return nil, fmt.Errorf("delta-compressed embedding requires a base vector from %q", m.EmbeddingBase)
+	}
+	return embeddings.DecodeDelta(base, raw, m.EmbeddingScale)
 }
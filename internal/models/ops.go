@@ -0,0 +1,94 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// OpType identifies what an Op mutates. The base meta.json blob a
+// snapshot is created with is never rewritten; everything recorded here
+// happens after the fact, one op per commit on the snapshot branch under
+// ops/, so the original blob (and TreeHash) stays verifiable.
+type OpType string
+
+const (
+	OpAddTag       OpType = "add_tag"
+	OpRemoveTag    OpType = "remove_tag"
+	OpSetTags      OpType = "set_tags"
+	OpEditNotes    OpType = "edit_notes"
+	OpSetMode      OpType = "set_mode"
+	OpLinkSnapshot OpType = "link_snapshot"
+)
+
+// Op is a single metadata mutation appended to a snapshot's operation
+// log (research/<ts>/<topic>/ops/). Only the fields relevant to Type are
+// set; the rest are left at their zero value.
+type Op struct {
+	Type          OpType       `json:"type"`
+	Timestamp     time.Time    `json:"timestamp"`
+	Tag           string       `json:"tag,omitempty"`
+	Tags          []string     `json:"tags,omitempty"`
+	Notes         string       `json:"notes,omitempty"`
+	Mode          SnapshotMode `json:"mode,omitempty"`
+	RelatedBranch string       `json:"related_branch,omitempty"`
+}
+
+// Resolve replays ops over m, in order, and returns the effective
+// metadata. m itself is never mutated.
+func (m Metadata) Resolve(ops []Op) Metadata {
+	result := m
+	tags := append([]string(nil), m.Tags...)
+
+	for _, op := range ops {
+		switch op.Type {
+		case OpAddTag:
+			tags = addTag(tags, op.Tag)
+		case OpRemoveTag:
+			tags = removeTag(tags, op.Tag)
+		case OpSetTags:
+			tags = append([]string(nil), op.Tags...)
+		case OpEditNotes:
+			result.Notes = op.Notes
+		case OpSetMode:
+			result.Mode = op.Mode
+		case OpLinkSnapshot:
+			result.RelatedBranch = op.RelatedBranch
+		}
+	}
+
+	result.Tags = dedupSortTags(tags)
+	return result
+}
+
+func addTag(tags []string, tag string) []string {
+	for _, t := range tags {
+		if t == tag {
+			return tags
+		}
+	}
+	return append(tags, tag)
+}
+
+func removeTag(tags []string, tag string) []string {
+	var result []string
+	for _, t := range tags {
+		if t != tag {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+func dedupSortTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	var result []string
+	for _, t := range tags {
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		result = append(result, t)
+	}
+	sort.Strings(result)
+	return result
+}
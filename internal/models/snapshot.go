@@ -35,3 +35,8 @@ func ResearchPath(timestamp time.Time, topic string) string {
 func MetadataPath(timestamp time.Time, topic string) string {
 	return fmt.Sprintf("%s/meta.json", ResearchPath(timestamp, topic))
 }
+
+// OpsPath returns the directory holding a snapshot's operation log (see Op).
+func OpsPath(timestamp time.Time, topic string) string {
+	return fmt.Sprintf("%s/ops", ResearchPath(timestamp, topic))
+}
@@ -0,0 +1,48 @@
+package ollama
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pders01/git-context/internal/embeddings"
+)
+
+// cachePath returns where a cached vector for (text, model) lives under
+// dir: a sha256 of "text|model", sharded two-hex-characters-deep the same
+// way internal/embeddings' content-addressed object store is, so no one
+// cache directory accumulates too many entries.
+func cachePath(dir, text, model string) string {
+	sum := sha256.Sum256([]byte(text + "|" + model))
+	key := hex.EncodeToString(sum[:])
+	return filepath.Join(dir, key[:2], key[2:]+".bin")
+}
+
+// cacheGet returns the cached vector for (text, model) under dir, if one
+// exists. Missing or unreadable entries are treated as a cache miss
+// rather than an error - the caller just falls through to the API.
+func cacheGet(dir, text, model string) ([]float64, bool) {
+	if dir == "" {
+		return nil, false
+	}
+	vec, err := embeddings.ReadEmbedding(cachePath(dir, text, model))
+	if err != nil {
+		return nil, false
+	}
+	return vec, true
+}
+
+// cachePut stores vec under dir, keyed by (text, model). It's a no-op if
+// dir is unset.
+func cachePut(dir, text, model string, vec []float64) error {
+	if dir == "" {
+		return nil
+	}
+	path := cachePath(dir, text, model)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create embedding cache directory: %w", err)
+	}
+	return embeddings.WriteEmbedding(path, vec)
+}
@@ -1,9 +1,12 @@
 package ollama
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/ollama/ollama/api"
@@ -14,15 +17,23 @@ const (
 	DefaultModel = "nomic-embed-text"
 	// DefaultURL is the default Ollama API endpoint
 	DefaultURL = "http://localhost:11434"
+
+	// DefaultChunkTokens and DefaultChunkOverlap are EmbedReader's default
+	// window size and overlap, in whitespace-delimited tokens.
+	DefaultChunkTokens  = 512
+	DefaultChunkOverlap = 64
 )
 
 // Client wraps the Ollama API client
 type Client struct {
-	client *api.Client
-	model  string
+	client   *api.Client
+	model    string
+	ctx      context.Context
+	cacheDir string
 }
 
-// NewClient creates a new Ollama client
+// NewClient creates a new Ollama client. Its default context is
+// context.Background(); use WithContext to bind one a caller can cancel.
 func NewClient(url, model string) (*Client, error) {
 	if url == "" {
 		url = DefaultURL
@@ -39,11 +50,32 @@ func NewClient(url, model string) (*Client, error) {
 	return &Client{
 		client: client,
 		model:  model,
+		ctx:    context.Background(),
 	}, nil
 }
 
+// WithContext returns a copy of c whose default context (used by
+// EmbedReader, which has no ctx parameter of its own) is ctx.
+func (c *Client) WithContext(ctx context.Context) *Client {
+	cp := *c
+	cp.ctx = ctx
+	return &cp
+}
+
+// WithCacheDir returns a copy of c that consults (and populates) a
+// content-addressed on-disk cache rooted at dir before calling the API,
+// keyed by sha256(text|model). This is what makes bulk re-embedding of
+// historical snapshots after an --no-embed gap, or a switch of
+// embeddings.model, cheap to re-run: unchanged text never leaves the
+// machine twice.
+func (c *Client) WithCacheDir(dir string) *Client {
+	cp := *c
+	cp.cacheDir = dir
+	return &cp
+}
+
 // IsAvailable checks if Ollama is running and accessible
-func IsAvailable(url string) bool {
+func IsAvailable(ctx context.Context, url string) bool {
 	if url == "" {
 		url = DefaultURL
 	}
@@ -53,7 +85,12 @@ func IsAvailable(url string) bool {
 		Timeout: 2 * time.Second,
 	}
 
-	resp, err := client.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false
+	}
+
+	resp, err := client.Do(req)
 	if err != nil {
 		return false
 	}
@@ -62,13 +99,17 @@ func IsAvailable(url string) bool {
 	return resp.StatusCode == http.StatusOK
 }
 
-// GenerateEmbedding generates an embedding vector for the given text
-func (c *Client) GenerateEmbedding(text string) ([]float64, error) {
+// GenerateEmbedding generates an embedding vector for the given text,
+// served from the on-disk cache (see WithCacheDir) when one is
+// configured and already holds this (text, model) pair.
+func (c *Client) GenerateEmbedding(ctx context.Context, text string) ([]float64, error) {
 	if text == "" {
 		return nil, fmt.Errorf("text cannot be empty")
 	}
 
-	ctx := context.Background()
+	if vec, ok := cacheGet(c.cacheDir, text, c.model); ok {
+		return vec, nil
+	}
 
 	req := &api.EmbedRequest{
 		Model: c.model,
@@ -91,13 +132,68 @@ func (c *Client) GenerateEmbedding(text string) ([]float64, error) {
 		embedding64[i] = float64(v)
 	}
 
+	if err := cachePut(c.cacheDir, text, c.model, embedding64); err != nil {
+		return nil, fmt.Errorf("failed to write embedding cache: %w", err)
+	}
+
 	return embedding64, nil
 }
 
-// CheckModel checks if the specified model is available
-func (c *Client) CheckModel() error {
-	ctx := context.Background()
+// GenerateEmbeddings generates one embedding vector per text, preserving
+// input order in the result. Texts already present in the on-disk cache
+// (see WithCacheDir) are served from there; everything else is requested
+// in a single batched /api/embed call.
+func (c *Client) GenerateEmbeddings(ctx context.Context, texts []string) ([][]float64, error) {
+	if len(texts) == 0 {
+		return nil, fmt.Errorf("texts cannot be empty")
+	}
+
+	vecs := make([][]float64, len(texts))
+	var missIdx []int
+	var missTexts []string
+	for i, text := range texts {
+		if vec, ok := cacheGet(c.cacheDir, text, c.model); ok {
+			vecs[i] = vec
+			continue
+		}
+		missIdx = append(missIdx, i)
+		missTexts = append(missTexts, text)
+	}
+
+	if len(missTexts) == 0 {
+		return vecs, nil
+	}
+
+	req := &api.EmbedRequest{
+		Model: c.model,
+		Input: missTexts,
+	}
+
+	resp, err := c.client.Embed(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate embeddings: %w", err)
+	}
+
+	if len(resp.Embeddings) != len(missTexts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(missTexts), len(resp.Embeddings))
+	}
+
+	for j, embedding32 := range resp.Embeddings {
+		vec := make([]float64, len(embedding32))
+		for k, v := range embedding32 {
+			vec[k] = float64(v)
+		}
+		if err := cachePut(c.cacheDir, missTexts[j], c.model, vec); err != nil {
+			return nil, fmt.Errorf("failed to write embedding cache: %w", err)
+		}
+		vecs[missIdx[j]] = vec
+	}
 
+	return vecs, nil
+}
+
+// CheckModel checks if the specified model is available
+func (c *Client) CheckModel(ctx context.Context) error {
 	// List available models
 	listResp, err := c.client.List(ctx)
 	if err != nil {
@@ -118,3 +214,92 @@ func (c *Client) CheckModel() error {
 func (c *Client) GetModel() string {
 	return c.model
 }
+
+// EmbedReader streams r, splits it into overlapping chunks of
+// DefaultChunkTokens whitespace-delimited tokens (DefaultChunkOverlap
+// tokens shared between consecutive chunks), and embeds every chunk in a
+// single batched GenerateEmbeddings call. It returns one vector per
+// chunk, in order, plus an unweighted mean-pooled vector summarizing the
+// whole document. Cancellation goes through the context bound by
+// WithContext (context.Background() if none was bound).
+//
+// A real tokenizer would pack more text per chunk than whitespace
+// splitting does, but doesn't require pulling in the target model's
+// vocabulary just to chunk text before embedding it.
+func (c *Client) EmbedReader(r io.Reader) (chunks [][]float64, aggregate []float64, err error) {
+	tokens, err := tokenize(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read document: %w", err)
+	}
+	if len(tokens) == 0 {
+		return nil, nil, fmt.Errorf("document is empty")
+	}
+
+	texts := chunkTokens(tokens, DefaultChunkTokens, DefaultChunkOverlap)
+
+	vecs, err := c.GenerateEmbeddings(c.ctx, texts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return vecs, meanPool(vecs), nil
+}
+
+// tokenize reads r fully and splits it on whitespace.
+func tokenize(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	scanner.Split(bufio.ScanWords)
+
+	var tokens []string
+	for scanner.Scan() {
+		tokens = append(tokens, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// chunkTokens groups tokens into overlapping windows of size tokens with
+// overlap tokens shared between consecutive windows, joining each window
+// back into a single string with single spaces.
+func chunkTokens(tokens []string, size, overlap int) []string {
+	if overlap >= size {
+		overlap = size - 1
+	}
+	step := size - overlap
+
+	var texts []string
+	for start := 0; start < len(tokens); start += step {
+		end := start + size
+		if end > len(tokens) {
+			end = len(tokens)
+		}
+		texts = append(texts, strings.Join(tokens[start:end], " "))
+		if end == len(tokens) {
+			break
+		}
+	}
+	return texts
+}
+
+// meanPool returns the unweighted element-wise mean of vecs, the
+// document-level summary vector EmbedReader returns alongside its
+// per-chunk vectors.
+func meanPool(vecs [][]float64) []float64 {
+	if len(vecs) == 0 {
+		return nil
+	}
+
+	mean := make([]float64, len(vecs[0]))
+	for _, vec := range vecs {
+		for i, v := range vec {
+			mean[i] += v
+		}
+	}
+	for i := range mean {
+		mean[i] /= float64(len(vecs))
+	}
+	return mean
+}
@@ -1,7 +1,9 @@
 package ollama
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -118,7 +120,7 @@ func TestIsAvailable(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := IsAvailable(tt.url)
+			result := IsAvailable(context.Background(), tt.url)
 			if result != tt.expected {
 				t.Errorf("expected %v, got %v", tt.expected, result)
 			}
@@ -156,7 +158,7 @@ func TestGenerateEmbedding(t *testing.T) {
 			t.Skipf("skipping test - could not create client: %v", err)
 		}
 
-		_, err = client.GenerateEmbedding("")
+		_, err = client.GenerateEmbedding(context.Background(), "")
 		if err == nil {
 			t.Error("expected error for empty text")
 		}
@@ -165,7 +167,7 @@ func TestGenerateEmbedding(t *testing.T) {
 	t.Run("valid text", func(t *testing.T) {
 		// This test would require mocking the Ollama client itself
 		// For now, we skip if Ollama is not available
-		if !IsAvailable(DefaultURL) {
+		if !IsAvailable(context.Background(), DefaultURL) {
 			t.Skip("Ollama not available, skipping integration test")
 		}
 
@@ -175,7 +177,7 @@ func TestGenerateEmbedding(t *testing.T) {
 		}
 
 		// Try to generate embedding with real Ollama (if available)
-		embedding, err := client.GenerateEmbedding("test text")
+		embedding, err := client.GenerateEmbedding(context.Background(), "test text")
 		if err != nil {
 			t.Skipf("Ollama not available or model not pulled: %v", err)
 		}
@@ -213,7 +215,7 @@ func TestCheckModel(t *testing.T) {
 
 	// These tests require integration with Ollama
 	// Skip if Ollama is not available
-	if !IsAvailable(DefaultURL) {
+	if !IsAvailable(context.Background(), DefaultURL) {
 		t.Skip("Ollama not available, skipping integration test")
 	}
 
@@ -223,7 +225,7 @@ func TestCheckModel(t *testing.T) {
 			t.Skipf("could not create client: %v", err)
 		}
 
-		err = client.CheckModel()
+		err = client.CheckModel(context.Background())
 		// If the model doesn't exist, the error will mention pulling it
 		// We just verify the function works
 		if err != nil {
@@ -237,7 +239,7 @@ func TestCheckModel(t *testing.T) {
 			t.Skipf("could not create client: %v", err)
 		}
 
-		err = client.CheckModel()
+		err = client.CheckModel(context.Background())
 		if err == nil {
 			t.Error("expected error for nonexistent model")
 		}
@@ -278,7 +280,7 @@ func TestFloat32ToFloat64Conversion(t *testing.T) {
 
 // Integration test that requires Ollama to be running
 func TestIntegrationGenerateEmbedding(t *testing.T) {
-	if !IsAvailable(DefaultURL) {
+	if !IsAvailable(context.Background(), DefaultURL) {
 		t.Skip("Ollama not available at default URL, skipping integration test")
 	}
 
@@ -288,7 +290,7 @@ func TestIntegrationGenerateEmbedding(t *testing.T) {
 	}
 
 	// Check if model is available
-	if err := client.CheckModel(); err != nil {
+	if err := client.CheckModel(context.Background()); err != nil {
 		t.Skipf("model not available: %v", err)
 	}
 
@@ -312,7 +314,7 @@ func TestIntegrationGenerateEmbedding(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			embedding, err := client.GenerateEmbedding(tt.text)
+			embedding, err := client.GenerateEmbedding(context.Background(), tt.text)
 			if err != nil {
 				t.Fatalf("failed to generate embedding: %v", err)
 			}
@@ -332,3 +334,71 @@ func TestIntegrationGenerateEmbedding(t *testing.T) {
 		})
 	}
 }
+
+func TestChunkTokens(t *testing.T) {
+	tokens := make([]string, 10)
+	for i := range tokens {
+		tokens[i] = fmt.Sprintf("tok%d", i)
+	}
+
+	chunks := chunkTokens(tokens, 4, 1)
+
+	want := []string{
+		"tok0 tok1 tok2 tok3",
+		"tok3 tok4 tok5 tok6",
+		"tok6 tok7 tok8 tok9",
+	}
+	if len(chunks) != len(want) {
+		t.Fatalf("expected %d chunks, got %d: %v", len(want), len(chunks), chunks)
+	}
+	for i := range want {
+		if chunks[i] != want[i] {
+			t.Errorf("chunk %d = %q, want %q", i, chunks[i], want[i])
+		}
+	}
+}
+
+func TestMeanPool(t *testing.T) {
+	vecs := [][]float64{
+		{1, 2, 3},
+		{3, 4, 5},
+	}
+
+	mean := meanPool(vecs)
+	want := []float64{2, 3, 4}
+	for i := range want {
+		if mean[i] != want[i] {
+			t.Errorf("mean[%d] = %v, want %v", i, mean[i], want[i])
+		}
+	}
+
+	if meanPool(nil) != nil {
+		t.Error("expected nil for empty input")
+	}
+}
+
+func TestCacheRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+
+	vec := []float64{0.1, 0.2, 0.3}
+	if err := cachePut(dir, "hello", "test-model", vec); err != nil {
+		t.Fatalf("cachePut failed: %v", err)
+	}
+
+	got, ok := cacheGet(dir, "hello", "test-model")
+	if !ok {
+		t.Fatal("expected cache hit")
+	}
+	for i := range vec {
+		if got[i] != vec[i] {
+			t.Errorf("got[%d] = %v, want %v", i, got[i], vec[i])
+		}
+	}
+
+	if _, ok := cacheGet(dir, "hello", "other-model"); ok {
+		t.Error("expected cache miss for a different model")
+	}
+	if _, ok := cacheGet("", "hello", "test-model"); ok {
+		t.Error("expected cache miss when no cache dir is configured")
+	}
+}
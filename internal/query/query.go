@@ -0,0 +1,165 @@
+// Package query implements a small expression evaluator over a snapshot's
+// metadata and timestamp, the filter syntax behind `context list --filter`
+// (and, eventually, `diff`, `xref`, and `search`): a comma-separated list
+// of field=value clauses ANDed together, e.g.
+// "tag=security,mode=poc,since=2025-10-01".
+package query
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pders01/git-context/internal/models"
+)
+
+// Record is the evaluation context for an Expr or GroupKey: a snapshot's
+// metadata plus its timestamp (the branch's parsed timestamp, not itself a
+// Metadata field, needed for since/until clauses and month/date grouping).
+type Record struct {
+	Metadata  models.Metadata
+	Timestamp time.Time
+}
+
+// field names a clause or group-by dimension supported by this package.
+type field string
+
+const (
+	fieldTag   field = "tag"
+	fieldMode  field = "mode"
+	fieldTopic field = "topic"
+	fieldSince field = "since"
+	fieldUntil field = "until"
+	fieldDate  field = "date"
+	fieldMonth field = "month"
+)
+
+// clause is a single parsed field=value filter term.
+type clause struct {
+	field field
+	value string
+}
+
+// Expr is a parsed filter expression: field=value clauses joined by commas
+// and evaluated as a conjunction, every clause must match. Repeated tag=
+// clauses are ANDed, matching context list --tag's existing multi-tag
+// semantics. The zero Expr matches everything.
+type Expr struct {
+	clauses []clause
+}
+
+// Parse parses a comma-separated field=value filter expression. Supported
+// fields: tag, mode, topic, since, until. An empty expr returns the zero
+// Expr, which matches everything.
+func Parse(expr string) (Expr, error) {
+	if strings.TrimSpace(expr) == "" {
+		return Expr{}, nil
+	}
+
+	var clauses []clause
+	for _, part := range strings.Split(expr, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return Expr{}, fmt.Errorf("invalid filter clause %q (expected field=value)", part)
+		}
+		name = strings.TrimSpace(name)
+		value = strings.TrimSpace(value)
+
+		f := field(name)
+		switch f {
+		case fieldTag, fieldMode, fieldTopic, fieldSince, fieldUntil:
+			clauses = append(clauses, clause{field: f, value: value})
+		default:
+			return Expr{}, fmt.Errorf("unknown filter field %q (want: tag, mode, topic, since, until)", name)
+		}
+	}
+
+	return Expr{clauses: clauses}, nil
+}
+
+// Match reports whether r satisfies every clause in e.
+func (e Expr) Match(r Record) bool {
+	for _, c := range e.clauses {
+		if !matchClause(c, r) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchClause(c clause, r Record) bool {
+	switch c.field {
+	case fieldTag:
+		for _, tag := range r.Metadata.Tags {
+			if tag == c.value {
+				return true
+			}
+		}
+		return false
+	case fieldMode:
+		return string(r.Metadata.Mode) == c.value
+	case fieldTopic:
+		return r.Metadata.Topic == c.value
+	case fieldSince:
+		since, err := time.Parse("2006-01-02", c.value)
+		if err != nil {
+			return false
+		}
+		return !r.Timestamp.Before(since)
+	case fieldUntil:
+		until, err := time.Parse("2006-01-02", c.value)
+		if err != nil {
+			return false
+		}
+		return !r.Timestamp.After(until)
+	default:
+		return false
+	}
+}
+
+// GroupKey builds a composite grouping key for r over dims, the field
+// names named in `context list --group-by`, e.g. []string{"mode", "tag",
+// "month"}. Dimensions are joined with " / " in the order given. Supported
+// dimensions: tag, mode, topic, date, month.
+func GroupKey(dims []string, r Record) (string, error) {
+	parts := make([]string, len(dims))
+	for i, dim := range dims {
+		part, err := groupValue(field(strings.TrimSpace(dim)), r)
+		if err != nil {
+			return "", err
+		}
+		parts[i] = part
+	}
+	return strings.Join(parts, " / "), nil
+}
+
+func groupValue(f field, r Record) (string, error) {
+	switch f {
+	case fieldTag:
+		if len(r.Metadata.Tags) == 0 {
+			return "(untagged)", nil
+		}
+		tags := append([]string(nil), r.Metadata.Tags...)
+		sort.Strings(tags)
+		return strings.Join(tags, "+"), nil
+	case fieldMode:
+		if r.Metadata.Mode == "" {
+			return "(unknown)", nil
+		}
+		return string(r.Metadata.Mode), nil
+	case fieldTopic:
+		return r.Metadata.Topic, nil
+	case fieldDate:
+		return r.Timestamp.Format("2006-01-02"), nil
+	case fieldMonth:
+		return r.Timestamp.Format("2006-01"), nil
+	default:
+		return "", fmt.Errorf("unknown group-by field %q (want: tag, mode, topic, date, month)", f)
+	}
+}
@@ -0,0 +1,105 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pders01/git-context/internal/models"
+)
+
+func mustParse(t *testing.T, expr string) Expr {
+	t.Helper()
+	e, err := Parse(expr)
+	if err != nil {
+		t.Fatalf("Parse(%q) failed: %v", expr, err)
+	}
+	return e
+}
+
+func TestExprMatch(t *testing.T) {
+	record := Record{
+		Metadata: models.Metadata{
+			Topic: "vulnerability-analysis",
+			Mode:  models.ModePOC,
+			Tags:  []string{"security", "critical"},
+		},
+		Timestamp: time.Date(2025, 10, 15, 0, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		want bool
+	}{
+		{"empty expression matches", "", true},
+		{"matching tag", "tag=security", true},
+		{"non-matching tag", "tag=performance", false},
+		{"matching mode", "mode=poc", true},
+		{"non-matching mode", "mode=full", false},
+		{"matching topic", "topic=vulnerability-analysis", true},
+		{"since before timestamp", "since=2025-10-01", true},
+		{"since after timestamp", "since=2025-11-01", false},
+		{"until after timestamp", "until=2025-10-31", true},
+		{"until before timestamp", "until=2025-10-01", false},
+		{"combined clauses all match", "tag=security,mode=poc,since=2025-10-01", true},
+		{"combined clauses one fails", "tag=security,mode=full", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr := mustParse(t, tt.expr)
+			if got := expr.Match(record); got != tt.want {
+				t.Errorf("Match(%q) = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []string{
+		"tag",
+		"unknown=value",
+		"tag=security,bogus=x",
+	}
+	for _, expr := range tests {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q): expected error, got none", expr)
+		}
+	}
+}
+
+func TestGroupKey(t *testing.T) {
+	record := Record{
+		Metadata: models.Metadata{
+			Mode: models.ModeFull,
+			Tags: []string{"b", "a"},
+		},
+		Timestamp: time.Date(2025, 10, 15, 0, 0, 0, 0, time.UTC),
+	}
+
+	key, err := GroupKey([]string{"mode", "tag", "month"}, record)
+	if err != nil {
+		t.Fatalf("GroupKey failed: %v", err)
+	}
+
+	want := "full / a+b / 2025-10"
+	if key != want {
+		t.Errorf("GroupKey = %q, want %q", key, want)
+	}
+}
+
+func TestGroupKeyUntagged(t *testing.T) {
+	key, err := GroupKey([]string{"tag"}, Record{})
+	if err != nil {
+		t.Fatalf("GroupKey failed: %v", err)
+	}
+	if key != "(untagged)" {
+		t.Errorf("GroupKey = %q, want %q", key, "(untagged)")
+	}
+}
+
+func TestGroupKeyUnknownField(t *testing.T) {
+	if _, err := GroupKey([]string{"bogus"}, Record{}); err == nil {
+		t.Error("expected error for unknown group-by field")
+	}
+}
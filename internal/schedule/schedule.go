@@ -0,0 +1,209 @@
+// Package schedule turns the cron-like entries under the schedule.jobs
+// config key into fired snapshot triggers: context schedule (see
+// cmd/schedule.go) wraps robfig/cron around the jobs, adds a small random
+// jitter so multiple repos on the same host sharing a schedule don't all
+// wake at once, and appends one JSON line per attempted tick to
+// .git/context/schedule.log for `context schedule status` to read back.
+package schedule
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Job is one entry under the schedule.jobs config key, e.g.:
+//
+//	[[schedule.jobs]]
+//	cron = "0 */4 * * *"
+//	topic = "auto"
+//	mode = "research-only"
+//	tags = ["auto"]
+//	dedup_within = "2h"
+type Job struct {
+	Cron        string   `mapstructure:"cron"`
+	Topic       string   `mapstructure:"topic"`
+	Mode        string   `mapstructure:"mode"`
+	Tags        []string `mapstructure:"tags"`
+	DedupWithin string   `mapstructure:"dedup_within"`
+}
+
+// TriggerFunc creates a snapshot for job. Returning skipped=true (with no
+// error) records the tick as a deliberate no-op, e.g. because a snapshot
+// already exists within the job's dedup window.
+type TriggerFunc func(ctx context.Context, job Job) (skipped bool, reason string, err error)
+
+// Result is one logged attempt at firing a Job, the unit both
+// schedule.log and `context schedule status` deal in.
+type Result struct {
+	Topic   string    `json:"topic"`
+	Cron    string    `json:"cron"`
+	Time    time.Time `json:"time"`
+	Skipped bool      `json:"skipped,omitempty"`
+	Reason  string    `json:"reason,omitempty"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// MaxJitter bounds the random delay added before a tick actually fires,
+// so `cron: "0 */4 * * *"` shared across a fleet of repos doesn't become
+// a thundering herd against the same Ollama instance.
+const MaxJitter = 30 * time.Second
+
+// parser accepts the same syntax as robfig/cron's default (5-field
+// minute-precision expressions, plus @daily/@hourly/@every descriptors).
+var parser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// NextFire returns the next time exprStr fires strictly after after.
+func NextFire(exprStr string, after time.Time) (time.Time, error) {
+	sched, err := parser.Parse(exprStr)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid cron expression %q: %w", exprStr, err)
+	}
+	return sched.Next(after), nil
+}
+
+// Scheduler fires trigger on the cron schedule described by jobs,
+// appending one Result per tick to logPath.
+type Scheduler struct {
+	jobs    []Job
+	trigger TriggerFunc
+	logPath string
+	jitter  time.Duration
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// New creates a Scheduler. jitter is the maximum random delay added
+// before each tick fires; pass 0 to disable jitter entirely (e.g. under
+// --run-once, where there's no herd to avoid).
+func New(jobs []Job, trigger TriggerFunc, logPath string, jitter time.Duration) *Scheduler {
+	return &Scheduler{
+		jobs:    jobs,
+		trigger: trigger,
+		logPath: logPath,
+		jitter:  jitter,
+		rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// RunOnce fires every job immediately, in order, and returns one Result
+// per job. This is what --run-once and external cron/systemd timers
+// drive instead of the long-lived Start loop.
+func (s *Scheduler) RunOnce(ctx context.Context) []Result {
+	results := make([]Result, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		results = append(results, s.fire(ctx, job))
+	}
+	return results
+}
+
+// Start runs the scheduler as a long-lived process: every job is
+// registered with an internal cron.Cron, and Start blocks until ctx is
+// canceled.
+func (s *Scheduler) Start(ctx context.Context) error {
+	c := cron.New(cron.WithParser(parser))
+
+	for _, job := range s.jobs {
+		job := job
+		if _, err := c.AddFunc(job.Cron, func() { s.tick(ctx, job) }); err != nil {
+			return fmt.Errorf("schedule job %q: invalid cron expression %q: %w", job.Topic, job.Cron, err)
+		}
+	}
+
+	c.Start()
+	<-ctx.Done()
+	<-c.Stop().Done()
+	return ctx.Err()
+}
+
+// tick adds jitter before firing, so simultaneous cron expressions across
+// a host don't all call out to Ollama/git at the same instant.
+func (s *Scheduler) tick(ctx context.Context, job Job) {
+	if s.jitter > 0 {
+		s.mu.Lock()
+		delay := time.Duration(s.rand.Int63n(int64(s.jitter)))
+		s.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+	s.fire(ctx, job)
+}
+
+func (s *Scheduler) fire(ctx context.Context, job Job) Result {
+	result := Result{Topic: job.Topic, Cron: job.Cron, Time: time.Now()}
+
+	skipped, reason, err := s.trigger(ctx, job)
+	result.Skipped = skipped
+	result.Reason = reason
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	if logErr := appendLog(s.logPath, result); logErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to write schedule log: %v\n", logErr)
+	}
+	return result
+}
+
+// appendLog appends one JSON-encoded Result line to path, creating the file
+// if needed; its parent directory must already exist.
+func appendLog(path string, result Result) error {
+	if path == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open schedule log: %w", err)
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	return enc.Encode(result)
+}
+
+// ReadLog reads every Result recorded at path, oldest first. A missing
+// file (the daemon has never run) is not an error: it just returns nil.
+func ReadLog(path string) ([]Result, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read schedule log: %w", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	var results []Result
+	for {
+		var r Result
+		if err := dec.Decode(&r); err != nil {
+			break
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// LastByTopic reduces results to the most recent entry per job topic.
+func LastByTopic(results []Result) map[string]Result {
+	last := make(map[string]Result)
+	for _, r := range results {
+		if prev, ok := last[r.Topic]; !ok || r.Time.After(prev.Time) {
+			last[r.Topic] = r
+		}
+	}
+	return last
+}
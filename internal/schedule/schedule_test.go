@@ -0,0 +1,84 @@
+package schedule
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNextFire(t *testing.T) {
+	after := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		expr string
+		want time.Time
+	}{
+		{"daily descriptor", "@daily", time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"standard expression", "0 */4 * * *", time.Date(2026, 1, 1, 4, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NextFire(tt.expr, after)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("NextFire(%q) = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := NextFire("not a cron expression", after); err == nil {
+		t.Error("expected error for invalid cron expression")
+	}
+}
+
+func TestAppendAndReadLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schedule.log")
+
+	job := Job{Cron: "@daily", Topic: "auto"}
+	s := New([]Job{job}, func(ctx context.Context, j Job) (bool, string, error) {
+		return true, "dedup window active", nil
+	}, path, 0)
+
+	results := s.RunOnce(context.Background())
+	if len(results) != 1 || !results[0].Skipped {
+		t.Fatalf("expected one skipped result, got %+v", results)
+	}
+
+	logged, err := ReadLog(path)
+	if err != nil {
+		t.Fatalf("ReadLog failed: %v", err)
+	}
+	if len(logged) != 1 || logged[0].Topic != "auto" || !logged[0].Skipped {
+		t.Fatalf("unexpected logged results: %+v", logged)
+	}
+}
+
+func TestReadLogMissingFile(t *testing.T) {
+	results, err := ReadLog(filepath.Join(t.TempDir(), "does-not-exist.log"))
+	if err != nil {
+		t.Fatalf("unexpected error for missing log: %v", err)
+	}
+	if results != nil {
+		t.Errorf("expected nil results, got %+v", results)
+	}
+}
+
+func TestLastByTopic(t *testing.T) {
+	older := Result{Topic: "auto", Time: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	newer := Result{Topic: "auto", Time: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)}
+	other := Result{Topic: "daily", Time: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+
+	last := LastByTopic([]Result{older, newer, other})
+
+	if got := last["auto"].Time; !got.Equal(newer.Time) {
+		t.Errorf("expected newest result for auto, got %v", got)
+	}
+	if _, ok := last["daily"]; !ok {
+		t.Error("expected daily topic to be present")
+	}
+}
@@ -0,0 +1,54 @@
+package signing
+
+import "strings"
+
+// EmbedGPGSig inserts armoredSig into raw (a git commit object's exact
+// byte content, as returned by internal/git's CatFileCommit) as a gpgsig
+// header, the format git itself uses to store commit signatures: the
+// header's first line follows "gpgsig ", each continuation line is
+// indented by one space, and the header sits with the other commit
+// headers, before the blank line that separates them from the message.
+func EmbedGPGSig(raw string, armoredSig []byte) string {
+	headers, message, _ := strings.Cut(raw, "\n\n")
+	lines := strings.Split(strings.TrimRight(string(armoredSig), "\n"), "\n")
+
+	var header strings.Builder
+	header.WriteString("gpgsig " + lines[0])
+	for _, line := range lines[1:] {
+		header.WriteString("\n " + line)
+	}
+
+	return headers + "\n" + header.String() + "\n\n" + message
+}
+
+// ExtractGPGSig reverses EmbedGPGSig, returning the commit object's raw
+// bytes with the gpgsig header removed (the exact payload that was
+// signed) and the decoded armored signature. ok is false if raw has no
+// gpgsig header.
+func ExtractGPGSig(raw string) (stripped string, signature []byte, ok bool) {
+	headers, message, hasMessage := strings.Cut(raw, "\n\n")
+	if !hasMessage {
+		return raw, nil, false
+	}
+
+	var kept, sigLines []string
+	inSig := false
+	for _, line := range strings.Split(headers, "\n") {
+		switch {
+		case strings.HasPrefix(line, "gpgsig "):
+			inSig = true
+			sigLines = append(sigLines, strings.TrimPrefix(line, "gpgsig "))
+		case inSig && strings.HasPrefix(line, " "):
+			sigLines = append(sigLines, strings.TrimPrefix(line, " "))
+		default:
+			inSig = false
+			kept = append(kept, line)
+		}
+	}
+
+	if len(sigLines) == 0 {
+		return raw, nil, false
+	}
+
+	return strings.Join(kept, "\n") + "\n\n" + message, []byte(strings.Join(sigLines, "\n") + "\n"), true
+}
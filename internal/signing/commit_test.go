@@ -0,0 +1,29 @@
+package signing
+
+import "testing"
+
+func TestEmbedExtractGPGSigRoundTrip(t *testing.T) {
+	raw := "tree abc123\nparent def456\nauthor A <a@example.com> 0 +0000\ncommitter A <a@example.com> 0 +0000\n\nsnapshot: test\n"
+	sig := []byte("-----BEGIN PGP SIGNATURE-----\n\nline1\nline2\n-----END PGP SIGNATURE-----\n")
+
+	signed := EmbedGPGSig(raw, sig)
+
+	stripped, extractedSig, ok := ExtractGPGSig(signed)
+	if !ok {
+		t.Fatal("expected gpgsig header to be found")
+	}
+	if stripped != raw {
+		t.Errorf("stripped commit text does not match original:\ngot:  %q\nwant: %q", stripped, raw)
+	}
+	if string(extractedSig) != string(sig) {
+		t.Errorf("extracted signature does not match original:\ngot:  %q\nwant: %q", extractedSig, sig)
+	}
+}
+
+func TestExtractGPGSigNoHeader(t *testing.T) {
+	raw := "tree abc123\nparent def456\n\nsnapshot: test\n"
+	_, _, ok := ExtractGPGSig(raw)
+	if ok {
+		t.Error("expected ok=false for a commit with no gpgsig header")
+	}
+}
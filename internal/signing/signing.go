@@ -0,0 +1,110 @@
+// Package signing provides pluggable cryptographic signers and verifiers
+// for snapshot commits and their manifests, so "snapshots are immutable"
+// becomes a checkable signature rather than a convention enforced only by
+// save refusing to recreate an existing branch.
+package signing
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Format names a signature scheme, matching git's own gpg.format values.
+type Format string
+
+const (
+	FormatGPG  Format = "gpg"
+	FormatSSH  Format = "ssh"
+	FormatX509 Format = "x509"
+)
+
+// Signer produces a detached, armored signature over arbitrary bytes (a
+// commit object or a manifest), analogous to go-git's Signer interface in
+// plumbing/object, but returning the signature pre-armored for embedding
+// in a commit's gpgsig header or writing to manifest.sig.
+type Signer interface {
+	// Sign returns a detached, armored signature over data.
+	Sign(data []byte) ([]byte, error)
+	// Format reports which signature scheme this Signer implements.
+	Format() Format
+}
+
+// Verifier checks a detached signature produced by the counterpart Signer.
+type Verifier interface {
+	// Verify checks signature against data and, on success, returns a
+	// human-readable identity for the key that produced it (a GPG key ID
+	// and identity name, or an SSH fingerprint and comment).
+	Verify(data, signature []byte) (identity string, err error)
+	Format() Format
+}
+
+// NewSigner constructs a Signer for format, resolving key as --sign-key
+// names it: an armored private key file for gpg, a private key file for
+// ssh.
+func NewSigner(format Format, key string) (Signer, error) {
+	switch format {
+	case FormatGPG:
+		return newGPGSigner(key)
+	case FormatSSH:
+		return newSSHSigner(key)
+	default:
+		return nil, fmt.Errorf("unsupported sign format %q (want: gpg, ssh)", format)
+	}
+}
+
+// NewVerifier constructs a Verifier for format, resolving key as
+// --verify-key names it: an armored public keyring for gpg, an
+// authorized_keys-format public key for ssh.
+func NewVerifier(format Format, key string) (Verifier, error) {
+	switch format {
+	case FormatGPG:
+		return newGPGVerifier(key)
+	case FormatSSH:
+		return newSSHVerifier(key)
+	default:
+		return nil, fmt.Errorf("unsupported sign format %q (want: gpg, ssh)", format)
+	}
+}
+
+// Manifest is the detached, signable summary of a snapshot's identity: the
+// code tree it was taken from, the commit it references on main, the raw
+// embedding bytes (if any), and its creation time. Verify recomputes this
+// from a checked-out snapshot and checks it against manifest.sig.
+type Manifest struct {
+	TreeHash   string    `json:"tree_hash"`
+	MainCommit string    `json:"main_commit"`
+	Embedding  []byte    `json:"embedding,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Bytes renders m in the canonical form signers sign and verifiers check
+// against: indented JSON, the same encoding convention meta.json itself
+// uses.
+func (m Manifest) Bytes() ([]byte, error) {
+	return json.MarshalIndent(m, "", "  ")
+}
+
+// SignManifest signs m's canonical bytes with signer, returning the
+// armored signature to write to manifest.sig.
+func SignManifest(signer Signer, m Manifest) ([]byte, error) {
+	data, err := m.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	sig, err := signer.Sign(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign manifest: %w", err)
+	}
+	return sig, nil
+}
+
+// VerifyManifest checks sig against m's canonical bytes using verifier,
+// returning the signer's identity on success.
+func VerifyManifest(verifier Verifier, m Manifest, sig []byte) (string, error) {
+	data, err := m.Bytes()
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	return verifier.Verify(data, sig)
+}
@@ -0,0 +1,171 @@
+// Package xref scans snapshot notes and research artifacts for references
+// to other snapshots, and models the resulting cross-reference graph.
+package xref
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// EdgeKind identifies how one snapshot came to reference another.
+type EdgeKind string
+
+const (
+	// KindMention means From's notes or research files named To, either
+	// by full branch name or by #topic@timestamp shorthand.
+	KindMention EdgeKind = "mention"
+	// KindRelatedBranch means From's metadata.RelatedBranch pointed at
+	// To's underlying code branch, auto-detected rather than written.
+	KindRelatedBranch EdgeKind = "related_branch"
+)
+
+// Edge is one directed cross-reference: From references To.
+type Edge struct {
+	From string   `json:"from"`
+	To   string   `json:"to"`
+	Kind EdgeKind `json:"kind"`
+}
+
+// Index is the full cross-reference graph across every scanned snapshot.
+type Index struct {
+	Edges []Edge `json:"edges"`
+}
+
+var (
+	branchRefPattern    = regexp.MustCompile(`snapshot/\d{4}-\d{2}-\d{2}T\d{4}/[a-z0-9][a-z0-9-]*`)
+	shorthandRefPattern = regexp.MustCompile(`#([a-z0-9][a-z0-9-]*)@(\d{4}-\d{2}-\d{2}T\d{4})`)
+)
+
+// ScanReferences finds every snapshot branch content mentions, either by
+// full branch name (snapshot/2025-11-14T0930/security-audit) or by the
+// shorthand #topic@timestamp syntax, and returns the full branch names
+// referenced, deduplicated and in the order they first appear.
+func ScanReferences(content string) []string {
+	type match struct {
+		start  int
+		branch string
+	}
+	var matches []match
+
+	for _, loc := range branchRefPattern.FindAllStringIndex(content, -1) {
+		matches = append(matches, match{start: loc[0], branch: content[loc[0]:loc[1]]})
+	}
+	for _, loc := range shorthandRefPattern.FindAllStringSubmatchIndex(content, -1) {
+		topic := content[loc[2]:loc[3]]
+		timestamp := content[loc[4]:loc[5]]
+		matches = append(matches, match{start: loc[0], branch: fmt.Sprintf("snapshot/%s/%s", timestamp, topic)})
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].start < matches[j].start })
+
+	seen := make(map[string]bool)
+	var refs []string
+	for _, m := range matches {
+		if !seen[m.branch] {
+			seen[m.branch] = true
+			refs = append(refs, m.branch)
+		}
+	}
+	return refs
+}
+
+// MentionsOf returns the edges where From is branch: what branch
+// references.
+func (idx Index) MentionsOf(branch string) []Edge {
+	var edges []Edge
+	for _, e := range idx.Edges {
+		if e.From == branch {
+			edges = append(edges, e)
+		}
+	}
+	return edges
+}
+
+// MentionedBy returns the edges where To is branch: what references
+// branch.
+func (idx Index) MentionedBy(branch string) []Edge {
+	var edges []Edge
+	for _, e := range idx.Edges {
+		if e.To == branch {
+			edges = append(edges, e)
+		}
+	}
+	return edges
+}
+
+// WithoutSnapshot drops every edge touching branch, as either endpoint.
+// Used by --reindex to clear a snapshot's stale edges before rescanning.
+func (idx Index) WithoutSnapshot(branch string) Index {
+	var edges []Edge
+	for _, e := range idx.Edges {
+		if e.From != branch && e.To != branch {
+			edges = append(edges, e)
+		}
+	}
+	return Index{Edges: edges}
+}
+
+// Merge appends edges to idx, skipping exact duplicates.
+func (idx Index) Merge(edges []Edge) Index {
+	seen := make(map[Edge]bool, len(idx.Edges))
+	for _, e := range idx.Edges {
+		seen[e] = true
+	}
+	result := idx
+	for _, e := range edges {
+		if !seen[e] {
+			seen[e] = true
+			result.Edges = append(result.Edges, e)
+		}
+	}
+	return result
+}
+
+// DOT renders the graph centered on branch (its mentions and mentioned-by
+// edges) as a Graphviz DOT digraph.
+func DOT(branch string, mentions, mentionedBy []Edge) string {
+	var b strings.Builder
+	b.WriteString("digraph xref {\n")
+	fmt.Fprintf(&b, "  %q [shape=box, style=filled, fillcolor=lightyellow];\n", branch)
+	for _, e := range sortedEdges(mentions) {
+		fmt.Fprintf(&b, "  %q -> %q [label=%q];\n", e.From, e.To, e.Kind)
+	}
+	for _, e := range sortedEdges(mentionedBy) {
+		fmt.Fprintf(&b, "  %q -> %q [label=%q];\n", e.From, e.To, e.Kind)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Mermaid renders the same graph as a Mermaid flowchart definition.
+func Mermaid(branch string, mentions, mentionedBy []Edge) string {
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for _, e := range sortedEdges(mentions) {
+		fmt.Fprintf(&b, "  %s -->|%s| %s\n", mermaidID(e.From), e.Kind, mermaidID(e.To))
+	}
+	for _, e := range sortedEdges(mentionedBy) {
+		fmt.Fprintf(&b, "  %s -->|%s| %s\n", mermaidID(e.From), e.Kind, mermaidID(e.To))
+	}
+	return b.String()
+}
+
+func sortedEdges(edges []Edge) []Edge {
+	sorted := append([]Edge(nil), edges...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].From != sorted[j].From {
+			return sorted[i].From < sorted[j].From
+		}
+		return sorted[i].To < sorted[j].To
+	})
+	return sorted
+}
+
+// mermaidID turns a branch name into a Mermaid-safe node id; "/" breaks
+// Mermaid's link syntax, so it's replaced and the original name kept as
+// the node label.
+func mermaidID(branch string) string {
+	id := strings.NewReplacer("/", "_", "-", "_", ":", "_").Replace(branch)
+	return fmt.Sprintf("%s[%q]", id, branch)
+}
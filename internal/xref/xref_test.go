@@ -0,0 +1,105 @@
+package xref
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScanReferencesFullBranch(t *testing.T) {
+	content := "See snapshot/2025-11-14T0930/security-audit for context."
+	got := ScanReferences(content)
+	want := []string{"snapshot/2025-11-14T0930/security-audit"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ScanReferences() = %v, want %v", got, want)
+	}
+}
+
+func TestScanReferencesShorthand(t *testing.T) {
+	content := "Follows up on #security-audit@2025-11-14T0930 with new findings."
+	got := ScanReferences(content)
+	want := []string{"snapshot/2025-11-14T0930/security-audit"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ScanReferences() = %v, want %v", got, want)
+	}
+}
+
+func TestScanReferencesDedupesAndPreservesOrder(t *testing.T) {
+	content := `first #topic-a@2025-01-01T0000
+then snapshot/2025-01-02T0000/topic-b
+then again #topic-a@2025-01-01T0000`
+	got := ScanReferences(content)
+	want := []string{
+		"snapshot/2025-01-01T0000/topic-a",
+		"snapshot/2025-01-02T0000/topic-b",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ScanReferences() = %v, want %v", got, want)
+	}
+}
+
+func TestScanReferencesNoMatches(t *testing.T) {
+	if got := ScanReferences("nothing to see here"); got != nil {
+		t.Errorf("ScanReferences() = %v, want nil", got)
+	}
+}
+
+func TestIndexMentionsAndMentionedBy(t *testing.T) {
+	idx := Index{Edges: []Edge{
+		{From: "snapshot/2025-01-01T0000/a", To: "snapshot/2025-01-02T0000/b", Kind: KindMention},
+		{From: "snapshot/2025-01-03T0000/c", To: "snapshot/2025-01-01T0000/a", Kind: KindMention},
+	}}
+
+	mentions := idx.MentionsOf("snapshot/2025-01-01T0000/a")
+	if len(mentions) != 1 || mentions[0].To != "snapshot/2025-01-02T0000/b" {
+		t.Errorf("MentionsOf() = %v", mentions)
+	}
+
+	mentionedBy := idx.MentionedBy("snapshot/2025-01-01T0000/a")
+	if len(mentionedBy) != 1 || mentionedBy[0].From != "snapshot/2025-01-03T0000/c" {
+		t.Errorf("MentionedBy() = %v", mentionedBy)
+	}
+}
+
+func TestIndexWithoutSnapshot(t *testing.T) {
+	idx := Index{Edges: []Edge{
+		{From: "a", To: "b", Kind: KindMention},
+		{From: "b", To: "c", Kind: KindMention},
+		{From: "c", To: "d", Kind: KindMention},
+	}}
+
+	result := idx.WithoutSnapshot("b")
+	for _, e := range result.Edges {
+		if e.From == "b" || e.To == "b" {
+			t.Errorf("WithoutSnapshot(\"b\") left edge touching b: %v", e)
+		}
+	}
+	if len(result.Edges) != 1 {
+		t.Errorf("WithoutSnapshot(\"b\") = %d edges, want 1", len(result.Edges))
+	}
+}
+
+func TestIndexMergeSkipsDuplicates(t *testing.T) {
+	idx := Index{Edges: []Edge{{From: "a", To: "b", Kind: KindMention}}}
+	merged := idx.Merge([]Edge{
+		{From: "a", To: "b", Kind: KindMention},
+		{From: "a", To: "c", Kind: KindMention},
+	})
+	if len(merged.Edges) != 2 {
+		t.Errorf("Merge() = %d edges, want 2", len(merged.Edges))
+	}
+}
+
+func TestDOTContainsEdges(t *testing.T) {
+	out := DOT("a", []Edge{{From: "a", To: "b", Kind: KindMention}}, nil)
+	if !strings.Contains(out, "digraph xref") || !strings.Contains(out, `"a" -> "b"`) {
+		t.Errorf("DOT() missing expected content: %s", out)
+	}
+}
+
+func TestMermaidContainsEdges(t *testing.T) {
+	out := Mermaid("a", []Edge{{From: "a", To: "b", Kind: KindMention}}, nil)
+	if !strings.Contains(out, "flowchart LR") || !strings.Contains(out, "-->|mention|") {
+		t.Errorf("Mermaid() missing expected content: %s", out)
+	}
+}